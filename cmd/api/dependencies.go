@@ -1,9 +1,14 @@
 package main
 
 import (
+	"context"
+	"fmt"
+
 	"search-engine-go/internal/api/handler"
 	"search-engine-go/internal/api/middleware"
 	"search-engine-go/internal/config"
+	"search-engine-go/internal/domain"
+	"search-engine-go/internal/infrastructure/searchindex"
 	"search-engine-go/internal/repository"
 	"search-engine-go/internal/service"
 	"search-engine-go/pkg/adapter"
@@ -15,11 +20,14 @@ type Dependencies struct {
 	ProviderService *service.ProviderService
 	ScoringService  *service.ScoringService
 	ContentService  *service.ContentService
+	SynonymService  *service.SynonymService
+	SearchIndex     *searchindex.Index
 	JWTService      *service.JWTService
 
 	AuthHandler      *handler.AuthHandler
 	ContentHandler   *handler.ContentHandler
 	DashboardHandler *handler.DashboardHandler
+	SynonymHandler   *handler.SynonymHandler
 
 	RateLimiter *middleware.RateLimiter
 	Logger *zap.Logger
@@ -27,15 +35,53 @@ type Dependencies struct {
 
 func initializeDependencies(infra *Infrastructure, adapters *adapter.AdapterRegistry, cfg *config.Config) (*Dependencies, error) {
 	contentRepo := repository.NewContentRepository(infra.DB.GetDB())
+	contentRepo.SetRelevanceWeights(domain.RelevanceWeights{
+		Text:  cfg.Search.RelevanceTextWeight,
+		Score: cfg.Search.RelevanceScoreWeight,
+	})
+	var searchIndex *searchindex.Index
+	if cfg.Search.Backend == repository.SearchBackendIndex {
+		index, err := searchindex.Open(cfg.Search.IndexPath)
+		if err != nil {
+			return nil, err
+		}
+		contentRepo.SetSearchBackend(index)
+		if err := contentRepo.ReindexSearchBackend(context.Background()); err != nil {
+			return nil, fmt.Errorf("failed to build search index: %w", err)
+		}
+		if err := index.Save(); err != nil {
+			return nil, err
+		}
+		index.Watch(cfg.Search.IndexSaveInterval, infra.Logger)
+		searchIndex = index
+	}
 
 	providerService := service.NewProviderService(adapters, infra.Logger)
 	scoringService := service.NewScoringService()
 	contentService := service.NewContentService(contentRepo, providerService, scoringService, infra.Cache, infra.Logger)
+	analyzer, err := service.LoadTextAnalyzer(cfg.Search.StopwordsFile)
+	if err != nil {
+		return nil, err
+	}
+	contentService.SetTextAnalyzer(analyzer)
+	contentService.WatchTrending(cfg.Search.TrendingRefreshInterval)
+	contentService.WatchQueryLog(cfg.Search.QueryLogFlushInterval)
+	if err := contentService.WarmSuggestions(context.Background()); err != nil {
+		infra.Logger.Warn("Failed to warm suggest index", zap.Error(err))
+	}
+
+	synonymService := service.NewSynonymService(repository.NewSynonymRepository(infra.DB.GetDB()), cfg.Search.SynonymsFile, infra.Logger)
+	if err := synonymService.Reload(context.Background()); err != nil {
+		infra.Logger.Warn("Failed to load synonyms", zap.Error(err))
+	}
+	synonymService.Watch(cfg.Search.SynonymReloadInterval)
+	contentService.SetSynonymService(synonymService)
 
 	jwtService := service.NewJWTService(cfg.Auth, infra.Logger)
 	authHandler := handler.NewAuthHandler(jwtService, infra.Logger)
 	contentHandler := handler.NewContentHandler(contentService, infra.Logger)
 	dashboardHandler := handler.NewDashboardHandler(contentService, infra.Logger)
+	synonymHandler := handler.NewSynonymHandler(synonymService, infra.Logger)
 
 	rateLimiter := middleware.NewRateLimiter(cfg.Server.RateLimit, infra.Logger)
 
@@ -43,10 +89,13 @@ func initializeDependencies(infra *Infrastructure, adapters *adapter.AdapterRegi
 		ProviderService:  providerService,
 		ScoringService:   scoringService,
 		ContentService:   contentService,
+		SynonymService:   synonymService,
+		SearchIndex:      searchIndex,
 		JWTService:       jwtService,
 		AuthHandler:      authHandler,
 		ContentHandler:   contentHandler,
 		DashboardHandler: dashboardHandler,
+		SynonymHandler:   synonymHandler,
 		RateLimiter:      rateLimiter,
 		Logger:           infra.Logger,
 	}, nil
@@ -18,6 +18,7 @@ func setupProviders(cfg config.ProvidersConfig, logger *zap.Logger) (*adapter.Ad
 		cfg.Provider1.RetryCount,
 		cfg.Provider1.RetryDelay,
 	)
+	provider1Adapter.SetLogger(logger)
 	adapters.Register("provider1", provider1Adapter)
 	logger.Info("Registered provider", zap.String("name", "provider1"), zap.String("type", "JSON"), zap.String("source", "mock file"))
 
@@ -29,6 +30,7 @@ func setupProviders(cfg config.ProvidersConfig, logger *zap.Logger) (*adapter.Ad
 		cfg.Provider2.RetryCount,
 		cfg.Provider2.RetryDelay,
 	)
+	provider2Adapter.SetLogger(logger)
 	adapters.Register("provider2", provider2Adapter)
 	logger.Info("Registered provider", zap.String("name", "provider2"), zap.String("type", "XML"), zap.String("source", "mock file"))
 
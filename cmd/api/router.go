@@ -1,11 +1,14 @@
 package main
 
 import (
+	"fmt"
 	"html/template"
 	"net/http"
 
+	"search-engine-go/internal/api/handler"
 	"search-engine-go/internal/api/middleware"
 	"search-engine-go/internal/config"
+	"search-engine-go/internal/service"
 
 	"github.com/gin-gonic/gin"
 )
@@ -40,7 +43,25 @@ func setupRouter(cfg *config.Config, deps *Dependencies) *gin.Engine {
 		v1.POST("/auth/logout", deps.AuthHandler.Logout)
 		
 		v1.GET("/search", deps.ContentHandler.Search)
+		v1.POST("/search", deps.ContentHandler.SearchDocument)
+		v1.GET("/search/export", deps.ContentHandler.ExportSearch)
+		v1.POST("/msearch", deps.ContentHandler.MultiSearch)
+		v1.GET("/trending", deps.ContentHandler.Trending)
+		v1.GET("/suggest", deps.ContentHandler.Suggest)
 		v1.GET("/content/:id", deps.ContentHandler.GetByID)
+		v1.GET("/content/:id/metrics", deps.ContentHandler.GetMetricsHistory)
+		v1.GET("/content/:id/related", deps.ContentHandler.GetRelated)
+	}
+
+	admin := v1.Group("/admin")
+	admin.Use(middleware.RequireRole(service.RoleAdmin, deps.Logger))
+	{
+		admin.GET("/synonyms", deps.SynonymHandler.List)
+		admin.POST("/synonyms", deps.SynonymHandler.Create)
+		admin.POST("/synonyms/reload", deps.SynonymHandler.Reload)
+		admin.GET("/synonyms/:id", deps.SynonymHandler.Get)
+		admin.PUT("/synonyms/:id", deps.SynonymHandler.Update)
+		admin.DELETE("/synonyms/:id", deps.SynonymHandler.Delete)
 	}
 	
 	docs := router.Group("/docs")
@@ -56,6 +77,12 @@ func setupRouter(cfg *config.Config, deps *Dependencies) *gin.Engine {
 	router.SetFuncMap(template.FuncMap{
 		"add": func(a, b int) int { return a + b },
 		"sub": func(a, b int) int { return a - b },
+		"formatDuration": func(seconds int) string {
+			if seconds >= 3600 {
+				return fmt.Sprintf("%d:%02d:%02d", seconds/3600, seconds%3600/60, seconds%60)
+			}
+			return fmt.Sprintf("%d:%02d", seconds/60, seconds%60)
+		},
 		"iterate": func(start, end int) []int {
 			var result []int
 			for i := start; i <= end; i++ {
@@ -63,6 +90,7 @@ func setupRouter(cfg *config.Config, deps *Dependencies) *gin.Engine {
 			}
 			return result
 		},
+		"highlight": handler.HighlightHTML,
 	})
 	router.LoadHTMLGlob("web/templates/*")
 	
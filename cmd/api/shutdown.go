@@ -30,6 +30,19 @@ func shutdownGracefully(
 	logger.Info("Shutting down rate limiter...")
 	deps.RateLimiter.Shutdown()
 
+	logger.Info("Stopping synonym reload...")
+	deps.SynonymService.Shutdown()
+
+	logger.Info("Stopping trending refresh and flushing search queries...")
+	deps.ContentService.Shutdown()
+
+	if deps.SearchIndex != nil {
+		logger.Info("Saving search index...")
+		if err := deps.SearchIndex.Shutdown(); err != nil {
+			logger.Warn("Error saving search index", zap.Error(err))
+		}
+	}
+
 	logger.Info("Closing cache connection...")
 	if err := infra.Cache.Close(); err != nil {
 		logger.Warn("Error closing cache", zap.Error(err))
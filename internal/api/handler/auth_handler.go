@@ -44,7 +44,7 @@ func (h *AuthHandler) Login(c *gin.Context) {
 
 	// TODO: Implement proper user authentication against database
 	if req.Username == "admin" && req.Password == "admin" {
-		token, err := h.jwtService.GenerateToken(req.Username)
+		token, err := h.jwtService.GenerateToken(req.Username, service.RoleAdmin)
 		if err != nil {
 			h.log.Error("Failed to generate token", zap.Error(err))
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
@@ -1,8 +1,10 @@
 package handler
 
 import (
+	"encoding/json"
 	"net/http"
 	"strconv"
+	"time"
 
 	"search-engine-go/internal/api/middleware"
 	"search-engine-go/internal/domain"
@@ -39,9 +41,17 @@ func (h *ContentHandler) Search(c *gin.Context) {
 	}
 
 	if req.ContentType != nil {
-		ct := string(*req.ContentType)
-		if ct == "" || (ct != string(domain.ContentTypeVideo) && ct != string(domain.ContentTypeText)) {
+		if *req.ContentType == "" {
 			req.ContentType = nil
+		} else if !req.ContentType.IsValid() {
+			domainErr := domain.NewInvalidInputError("content_type", "must be one of: video, text, audio, gallery")
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":      domainErr.Message,
+				"code":       string(domainErr.Code),
+				"details":    domainErr.Details,
+				"request_id": middleware.GetRequestID(c),
+			})
+			return
 		}
 	}
 
@@ -80,6 +90,139 @@ func (h *ContentHandler) Search(c *gin.Context) {
 	c.JSON(http.StatusOK, resp)
 }
 
+// maxSearchDocumentBytes bounds the size of a JSON search body
+const maxSearchDocumentBytes = 1 << 20
+
+func (h *ContentHandler) SearchDocument(c *gin.Context) {
+	var doc domain.SearchDocument
+	decoder := json.NewDecoder(http.MaxBytesReader(c.Writer, c.Request.Body, maxSearchDocumentBytes))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&doc); err != nil {
+		h.log.Warn("Invalid search document", zap.Error(err), zap.String("request_id", middleware.GetRequestID(c)))
+		respondDomainError(c, domain.NewInvalidInputError("body", err.Error()))
+		return
+	}
+
+	resp, err := h.service.SearchDocument(c.Request.Context(), &doc)
+	if err != nil {
+		h.log.Error("Search document failed", zap.Error(err), zap.String("request_id", middleware.GetRequestID(c)))
+		respondDomainError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// MultiSearch runs several searches at once. Every search gets an entry in the response, in
+// request order, holding either its results or its error with the status it would have had.
+func (h *ContentHandler) MultiSearch(c *gin.Context) {
+	var req domain.MultiSearchRequest
+	decoder := json.NewDecoder(http.MaxBytesReader(c.Writer, c.Request.Body, maxSearchDocumentBytes))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&req); err != nil {
+		h.log.Warn("Invalid multi-search request", zap.Error(err), zap.String("request_id", middleware.GetRequestID(c)))
+		respondDomainError(c, domain.NewInvalidInputError("body", err.Error()))
+		return
+	}
+
+	results, err := h.service.MultiSearch(c.Request.Context(), &req)
+	if err != nil {
+		h.log.Error("Multi-search failed", zap.Error(err), zap.String("request_id", middleware.GetRequestID(c)))
+		respondDomainError(c, err)
+		return
+	}
+
+	responses := make([]gin.H, 0, len(results))
+	for i, result := range results {
+		if result.Err != nil {
+			h.log.Warn("Multi-search query failed", zap.Int("index", i), zap.Error(result.Err), zap.String("request_id", middleware.GetRequestID(c)))
+			statusCode, body := domainErrorBody(result.Err)
+			body["status"] = statusCode
+			responses = append(responses, body)
+			continue
+		}
+		responses = append(responses, gin.H{"status": http.StatusOK, "result": result.Response})
+	}
+
+	c.JSON(http.StatusOK, gin.H{"responses": responses})
+}
+
+// ExportSearch streams every match of a search as CSV or NDJSON, flushing after each batch.
+// Errors found before the first row is written get a JSON error response; once streaming has
+// started they abort the connection, so that clients can tell the export is incomplete.
+func (h *ContentHandler) ExportSearch(c *gin.Context) {
+	var req domain.ExportRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		h.log.Warn("Invalid export request", zap.Error(err), zap.String("request_id", middleware.GetRequestID(c)))
+		respondDomainError(c, domain.NewInvalidInputError("query", err.Error()))
+		return
+	}
+
+	if req.ContentType != nil {
+		if *req.ContentType == "" {
+			req.ContentType = nil
+		} else if !req.ContentType.IsValid() {
+			respondDomainError(c, domain.NewInvalidInputError("content_type", "must be one of: video, text, audio, gallery"))
+			return
+		}
+	}
+
+	exportSpec := domain.NewExportSpecification()
+	if err := exportSpec.NormalizeExport(&req); err != nil {
+		respondDomainError(c, err)
+		return
+	}
+
+	var writer exportWriter
+	begin := func() error {
+		c.Header("Content-Type", exportContentTypes[req.Format])
+		c.Header("Content-Disposition", `attachment; filename="search-export.`+req.Format+`"`)
+		c.Status(http.StatusOK)
+		writer = newExportWriter(req.Format, req.SelectedColumns, c.Writer)
+		return writer.WriteHeader()
+	}
+
+	err := h.service.ExportSearch(c.Request.Context(), &req, func(batch []*domain.Content) error {
+		if writer == nil {
+			if err := begin(); err != nil {
+				return err
+			}
+		}
+		for _, content := range batch {
+			if err := writer.Write(content); err != nil {
+				return err
+			}
+		}
+		if err := writer.Flush(); err != nil {
+			return err
+		}
+		c.Writer.Flush()
+		return nil
+	})
+	if err != nil {
+		h.log.Error("Export failed", zap.Error(err), zap.String("request_id", middleware.GetRequestID(c)))
+		if writer == nil {
+			respondDomainError(c, err)
+			return
+		}
+		// The status and some rows are already sent, so drop the connection rather than end the
+		// response, which would make the truncated export look complete
+		panic(http.ErrAbortHandler)
+	}
+
+	// Nothing matched, so only the CSV header row is written
+	if writer == nil {
+		err = begin()
+		if err == nil {
+			err = writer.Flush()
+		}
+		if err != nil {
+			h.log.Error("Export failed", zap.Error(err), zap.String("request_id", middleware.GetRequestID(c)))
+			panic(http.ErrAbortHandler)
+		}
+	}
+}
+
 func (h *ContentHandler) GetByID(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.ParseInt(idStr, 10, 64)
@@ -135,3 +278,133 @@ func (h *ContentHandler) GetByID(c *gin.Context) {
 
 	c.JSON(http.StatusOK, content)
 }
+
+func (h *ContentHandler) GetMetricsHistory(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		respondDomainError(c, domain.NewInvalidInputError("id", "must be a valid integer"))
+		return
+	}
+
+	var req domain.MetricsHistoryRequest
+	if req.From, err = parseTimeParam(c.Query("from")); err != nil {
+		respondDomainError(c, domain.NewInvalidInputError("from", "must be an RFC3339 timestamp or YYYY-MM-DD date"))
+		return
+	}
+	if req.To, err = parseTimeParam(c.Query("to")); err != nil {
+		respondDomainError(c, domain.NewInvalidInputError("to", "must be an RFC3339 timestamp or YYYY-MM-DD date"))
+		return
+	}
+
+	resp, err := h.service.GetMetricsHistory(c.Request.Context(), id, &req)
+	if err != nil {
+		h.log.Error("Get metrics history failed", zap.Error(err), zap.String("request_id", middleware.GetRequestID(c)))
+		respondDomainError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *ContentHandler) GetRelated(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		respondDomainError(c, domain.NewInvalidInputError("id", "must be a valid integer"))
+		return
+	}
+
+	var req domain.RelatedRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		h.log.Warn("Invalid related request", zap.Error(err), zap.String("request_id", middleware.GetRequestID(c)))
+		respondDomainError(c, domain.NewInvalidInputError("query", err.Error()))
+		return
+	}
+
+	resp, err := h.service.GetRelated(c.Request.Context(), id, &req)
+	if err != nil {
+		h.log.Error("Get related content failed", zap.Error(err), zap.String("request_id", middleware.GetRequestID(c)))
+		respondDomainError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *ContentHandler) Trending(c *gin.Context) {
+	var req domain.TrendingRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		h.log.Warn("Invalid trending request", zap.Error(err), zap.String("request_id", middleware.GetRequestID(c)))
+		respondDomainError(c, domain.NewInvalidInputError("query", err.Error()))
+		return
+	}
+
+	resp, err := h.service.GetTrending(c.Request.Context(), &req)
+	if err != nil {
+		h.log.Error("Trending failed", zap.Error(err), zap.String("request_id", middleware.GetRequestID(c)))
+		respondDomainError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *ContentHandler) Suggest(c *gin.Context) {
+	var req domain.SuggestRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		h.log.Warn("Invalid suggest request", zap.Error(err), zap.String("request_id", middleware.GetRequestID(c)))
+		respondDomainError(c, domain.NewInvalidInputError("query", err.Error()))
+		return
+	}
+
+	resp, err := h.service.Suggest(c.Request.Context(), &req)
+	if err != nil {
+		h.log.Error("Suggest failed", zap.Error(err), zap.String("request_id", middleware.GetRequestID(c)))
+		respondDomainError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// respondDomainError maps a domain error code to its HTTP status; other errors are reported
+// as internal errors without their message
+func respondDomainError(c *gin.Context, err error) {
+	statusCode, body := domainErrorBody(err)
+	body["request_id"] = middleware.GetRequestID(c)
+	c.JSON(statusCode, body)
+}
+
+// domainErrorBody returns the status code and JSON body an error is reported with
+func domainErrorBody(err error) (int, gin.H) {
+	domainErr, ok := err.(*domain.DomainError)
+	if !ok {
+		return http.StatusInternalServerError, gin.H{"error": "Internal server error"}
+	}
+
+	statusCode := http.StatusInternalServerError
+	switch domainErr.Code {
+	case domain.ErrorCodeInvalidInput:
+		statusCode = http.StatusBadRequest
+	case domain.ErrorCodeNotFound:
+		statusCode = http.StatusNotFound
+	case domain.ErrorCodeProviderError:
+		statusCode = http.StatusServiceUnavailable
+	}
+
+	return statusCode, gin.H{
+		"error":   domainErr.Message,
+		"code":    string(domainErr.Code),
+		"details": domainErr.Details,
+	}
+}
+
+// parseTimeParam accepts either an RFC3339 timestamp or a plain date; an empty value yields the zero time
+func parseTimeParam(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t.UTC(), nil
+	}
+	return time.Parse("2006-01-02", value)
+}
@@ -28,7 +28,7 @@ func setupIntegrationTest(t *testing.T) (*ContentHandler, func()) {
 	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
 	require.NoError(t, err)
 
-	err = db.AutoMigrate(&domain.Content{})
+	err = db.AutoMigrate(&domain.Content{}, &domain.Tag{}, &domain.ContentMetricsSnapshot{}, &domain.SearchQueryLog{})
 	require.NoError(t, err)
 
 	logger, _ := zap.NewDevelopment()
@@ -5,13 +5,16 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"search-engine-go/internal/domain"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 )
 
@@ -35,13 +38,81 @@ func (m *MockContentService) GetByID(ctx context.Context, id int64) (*domain.Con
 	return args.Get(0).(*domain.Content), args.Error(1)
 }
 
+func (m *MockContentService) GetMetricsHistory(ctx context.Context, id int64, req *domain.MetricsHistoryRequest) (*domain.MetricsHistoryResponse, error) {
+	args := m.Called(ctx, id, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.MetricsHistoryResponse), args.Error(1)
+}
+
+func (m *MockContentService) GetTrending(ctx context.Context, req *domain.TrendingRequest) (*domain.SearchResponse, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.SearchResponse), args.Error(1)
+}
+
+func (m *MockContentService) GetRelated(ctx context.Context, id int64, req *domain.RelatedRequest) (*domain.SearchResponse, error) {
+	args := m.Called(ctx, id, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.SearchResponse), args.Error(1)
+}
+
+func (m *MockContentService) SearchDocument(ctx context.Context, doc *domain.SearchDocument) (*domain.SearchResponse, error) {
+	args := m.Called(ctx, doc)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.SearchResponse), args.Error(1)
+}
+
+func (m *MockContentService) MultiSearch(ctx context.Context, req *domain.MultiSearchRequest) ([]*domain.MultiSearchResult, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.MultiSearchResult), args.Error(1)
+}
+
+// ExportSearch passes each batch of the first return value to fn, stopping at fn's first error
+func (m *MockContentService) ExportSearch(ctx context.Context, req *domain.ExportRequest, fn func([]*domain.Content) error) error {
+	args := m.Called(ctx, req)
+	if batches, ok := args.Get(0).([][]*domain.Content); ok {
+		for _, batch := range batches {
+			if err := fn(batch); err != nil {
+				return err
+			}
+		}
+	}
+	return args.Error(1)
+}
+
+func (m *MockContentService) Suggest(ctx context.Context, req *domain.SuggestRequest) (*domain.SuggestResponse, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.SuggestResponse), args.Error(1)
+}
+
 func setupTestRouter(handler *ContentHandler) *gin.Engine {
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
 	v1 := router.Group("/api/v1")
 	{
 		v1.GET("/search", handler.Search)
+		v1.POST("/search", handler.SearchDocument)
+		v1.GET("/search/export", handler.ExportSearch)
+		v1.POST("/msearch", handler.MultiSearch)
+		v1.GET("/suggest", handler.Suggest)
+		v1.GET("/trending", handler.Trending)
 		v1.GET("/content/:id", handler.GetByID)
+		v1.GET("/content/:id/metrics", handler.GetMetricsHistory)
+		v1.GET("/content/:id/related", handler.GetRelated)
 	}
 	return router
 }
@@ -113,6 +184,25 @@ func TestContentHandler_Search(t *testing.T) {
 		mockService.AssertExpectations(t)
 	})
 
+	t.Run("Unknown content type is rejected", func(t *testing.T) {
+		mockService := new(MockContentService)
+		handler := NewContentHandler(mockService, logger)
+
+		router := setupTestRouter(handler)
+		req := httptest.NewRequest("GET", "/api/v1/search?query=test&content_type=hologram", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		var response map[string]interface{}
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		assert.NoError(t, err)
+		assert.Equal(t, "INVALID_INPUT", response["code"])
+
+		mockService.AssertNotCalled(t, "Search")
+	})
+
 	t.Run("Invalid request parameters", func(t *testing.T) {
 		mockService := new(MockContentService)
 		handler := NewContentHandler(mockService, logger)
@@ -154,6 +244,69 @@ func TestContentHandler_Search(t *testing.T) {
 		mockService.AssertExpectations(t)
 	})
 
+	t.Run("Collapse is passed to service and inner hits are returned", func(t *testing.T) {
+		mockService := new(MockContentService)
+		handler := NewContentHandler(mockService, logger)
+
+		expectedResponse := &domain.SearchResponse{
+			Items: []*domain.Content{
+				{ID: 1, Title: "Top Hit", Provider: "provider1", Type: domain.ContentTypeVideo, InnerHits: &domain.InnerHits{Total: 3}},
+			},
+			Total:      1,
+			Page:       1,
+			PageSize:   20,
+			TotalPages: 1,
+		}
+
+		mockService.On("Search", mock.Anything, mock.MatchedBy(func(req *domain.SearchRequest) bool {
+			return req.Collapse == "provider"
+		})).Return(expectedResponse, nil)
+
+		router := setupTestRouter(handler)
+		req := httptest.NewRequest("GET", "/api/v1/search?query=test&collapse=provider", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response domain.SearchResponse
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		assert.NoError(t, err)
+		assert.Equal(t, 3, response.Items[0].InnerHits.Total)
+
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Facets are passed to service and aggregations are returned", func(t *testing.T) {
+		mockService := new(MockContentService)
+		handler := NewContentHandler(mockService, logger)
+
+		expectedResponse := &domain.SearchResponse{
+			Items: []*domain.Content{},
+			Aggregations: domain.Aggregations{
+				domain.FacetType: {{Key: "video", Count: 12}, {Key: "text", Count: 8}},
+			},
+		}
+
+		mockService.On("Search", mock.Anything, mock.MatchedBy(func(req *domain.SearchRequest) bool {
+			return len(req.Facets) == 1 && req.Facets[0] == "type,provider"
+		})).Return(expectedResponse, nil)
+
+		router := setupTestRouter(handler)
+		req := httptest.NewRequest("GET", "/api/v1/search?query=test&facets=type,provider", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response domain.SearchResponse
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		assert.NoError(t, err)
+		assert.Equal(t, 12, response.Aggregations[domain.FacetType][0].Count)
+
+		mockService.AssertExpectations(t)
+	})
+
 	t.Run("Pagination normalization", func(t *testing.T) {
 		mockService := new(MockContentService)
 		handler := NewContentHandler(mockService, logger)
@@ -276,3 +429,456 @@ func TestContentHandler_GetByID(t *testing.T) {
 		mockService.AssertExpectations(t)
 	})
 }
+
+func TestContentHandler_GetMetricsHistory(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+
+	t.Run("Returns time series", func(t *testing.T) {
+		mockService := new(MockContentService)
+		handler := NewContentHandler(mockService, logger)
+
+		expectedResponse := &domain.MetricsHistoryResponse{
+			ContentID: 1,
+			Points: []*domain.ContentMetricsSnapshot{
+				{Views: 100, Likes: 10},
+				{Views: 250, Likes: 18},
+			},
+		}
+
+		mockService.On("GetMetricsHistory", mock.Anything, int64(1), mock.MatchedBy(func(req *domain.MetricsHistoryRequest) bool {
+			return req.From.Equal(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)) && req.To.IsZero()
+		})).Return(expectedResponse, nil)
+
+		router := setupTestRouter(handler)
+		req := httptest.NewRequest("GET", "/api/v1/content/1/metrics?from=2024-03-01", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response domain.MetricsHistoryResponse
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		assert.NoError(t, err)
+		assert.Len(t, response.Points, 2)
+
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Invalid from parameter", func(t *testing.T) {
+		mockService := new(MockContentService)
+		handler := NewContentHandler(mockService, logger)
+
+		router := setupTestRouter(handler)
+		req := httptest.NewRequest("GET", "/api/v1/content/1/metrics?from=yesterday", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertNotCalled(t, "GetMetricsHistory")
+	})
+
+	t.Run("Unknown content", func(t *testing.T) {
+		mockService := new(MockContentService)
+		handler := NewContentHandler(mockService, logger)
+
+		mockService.On("GetMetricsHistory", mock.Anything, int64(42), mock.Anything).
+			Return(nil, domain.NewNotFoundError("content", int64(42)))
+
+		router := setupTestRouter(handler)
+		req := httptest.NewRequest("GET", "/api/v1/content/42/metrics", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		mockService.AssertExpectations(t)
+	})
+}
+
+func TestContentHandler_Trending(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+
+	t.Run("Passes type and window to service", func(t *testing.T) {
+		mockService := new(MockContentService)
+		handler := NewContentHandler(mockService, logger)
+
+		expectedResponse := &domain.SearchResponse{
+			Items:      []*domain.Content{{ID: 1, Title: "Rising Video", TrendingScore: 3.5}},
+			Total:      1,
+			Page:       1,
+			PageSize:   20,
+			TotalPages: 1,
+		}
+
+		mockService.On("GetTrending", mock.Anything, mock.MatchedBy(func(req *domain.TrendingRequest) bool {
+			return req.ContentType != nil && *req.ContentType == domain.ContentTypeVideo && req.Window == "24h"
+		})).Return(expectedResponse, nil)
+
+		router := setupTestRouter(handler)
+		req := httptest.NewRequest("GET", "/api/v1/trending?type=video&window=24h", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Invalid window", func(t *testing.T) {
+		mockService := new(MockContentService)
+		handler := NewContentHandler(mockService, logger)
+
+		mockService.On("GetTrending", mock.Anything, mock.Anything).
+			Return(nil, domain.NewInvalidInputError("window", "must be a duration such as 24h or 7d"))
+
+		router := setupTestRouter(handler)
+		req := httptest.NewRequest("GET", "/api/v1/trending?window=soon", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestContentHandler_GetRelated(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+
+	t.Run("Passes id and pagination to service", func(t *testing.T) {
+		mockService := new(MockContentService)
+		handler := NewContentHandler(mockService, logger)
+
+		expectedResponse := &domain.SearchResponse{
+			Items:      []*domain.Content{{ID: 2, Title: "Go Concurrency Patterns"}},
+			Total:      1,
+			Page:       2,
+			PageSize:   5,
+			TotalPages: 1,
+		}
+
+		mockService.On("GetRelated", mock.Anything, int64(1), mock.MatchedBy(func(req *domain.RelatedRequest) bool {
+			return req.Page == 2 && req.PageSize == 5
+		})).Return(expectedResponse, nil)
+
+		router := setupTestRouter(handler)
+		req := httptest.NewRequest("GET", "/api/v1/content/1/related?page=2&page_size=5", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Invalid id", func(t *testing.T) {
+		mockService := new(MockContentService)
+		handler := NewContentHandler(mockService, logger)
+
+		router := setupTestRouter(handler)
+		req := httptest.NewRequest("GET", "/api/v1/content/abc/related", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertNotCalled(t, "GetRelated")
+	})
+
+	t.Run("Unknown content", func(t *testing.T) {
+		mockService := new(MockContentService)
+		handler := NewContentHandler(mockService, logger)
+
+		mockService.On("GetRelated", mock.Anything, int64(99), mock.Anything).
+			Return(nil, domain.NewNotFoundError("content", int64(99)))
+
+		router := setupTestRouter(handler)
+		req := httptest.NewRequest("GET", "/api/v1/content/99/related", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestContentHandler_SearchDocument(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+
+	t.Run("Decodes the document and passes it to service", func(t *testing.T) {
+		mockService := new(MockContentService)
+		handler := NewContentHandler(mockService, logger)
+
+		mockService.On("SearchDocument", mock.Anything, mock.MatchedBy(func(doc *domain.SearchDocument) bool {
+			return doc.Query != nil && doc.Query.Bool != nil &&
+				len(doc.Query.Bool.Must) == 1 && doc.Query.Bool.Must[0].Match["title"] == "go" &&
+				len(doc.Sort) == 1 && doc.Sort[0]["views"] == "desc" &&
+				doc.PageSize == 5
+		})).Return(&domain.SearchResponse{Items: []*domain.Content{}, Page: 1, PageSize: 5}, nil)
+
+		body := `{
+			"query": {"bool": {"must": [{"match": {"title": "go"}}], "must_not": [{"term": {"provider": "provider2"}}]}},
+			"sort": [{"views": "desc"}],
+			"page_size": 5
+		}`
+		router := setupTestRouter(handler)
+		req := httptest.NewRequest("POST", "/api/v1/search", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Unknown fields are rejected", func(t *testing.T) {
+		mockService := new(MockContentService)
+		handler := NewContentHandler(mockService, logger)
+
+		router := setupTestRouter(handler)
+		req := httptest.NewRequest("POST", "/api/v1/search", strings.NewReader(`{"query": {"fuzzy": {"title": "go"}}}`))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		var response map[string]interface{}
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		assert.NoError(t, err)
+		assert.Equal(t, "INVALID_INPUT", response["code"])
+
+		mockService.AssertNotCalled(t, "SearchDocument")
+	})
+
+	t.Run("Validation errors map to bad request", func(t *testing.T) {
+		mockService := new(MockContentService)
+		handler := NewContentHandler(mockService, logger)
+
+		mockService.On("SearchDocument", mock.Anything, mock.Anything).
+			Return(nil, domain.NewInvalidInputError("query.range.views.gte", "must be a number"))
+
+		router := setupTestRouter(handler)
+		req := httptest.NewRequest("POST", "/api/v1/search", strings.NewReader(`{"query": {"range": {"views": {"gte": "many"}}}}`))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertExpectations(t)
+	})
+}
+
+func TestContentHandler_MultiSearch(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+
+	t.Run("Returns results and errors in order", func(t *testing.T) {
+		mockService := new(MockContentService)
+		handler := NewContentHandler(mockService, logger)
+
+		mockService.On("MultiSearch", mock.Anything, mock.MatchedBy(func(req *domain.MultiSearchRequest) bool {
+			return len(req.Searches) == 2 && req.Searches[0].Query == "go" &&
+				req.Searches[0].ContentType != nil && *req.Searches[0].ContentType == domain.ContentTypeVideo &&
+				req.Searches[1].SortBy == "trending"
+		})).Return([]*domain.MultiSearchResult{
+			{Response: &domain.SearchResponse{Items: []*domain.Content{{ID: 1, Title: "Go"}}, Total: 1, Page: 1, PageSize: 20}},
+			{Err: domain.NewInvalidInputError("sort_by", "must be one of: score, created_at, popularity")},
+		}, nil)
+
+		body := `{"searches": [{"query": "go", "content_type": "video"}, {"sort_by": "trending"}]}`
+		router := setupTestRouter(handler)
+		req := httptest.NewRequest("POST", "/api/v1/msearch", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response struct {
+			Responses []map[string]interface{} `json:"responses"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		require.Len(t, response.Responses, 2)
+		assert.Equal(t, float64(http.StatusOK), response.Responses[0]["status"])
+		assert.Equal(t, float64(1), response.Responses[0]["result"].(map[string]interface{})["total"])
+		assert.Equal(t, float64(http.StatusBadRequest), response.Responses[1]["status"])
+		assert.Equal(t, "INVALID_INPUT", response.Responses[1]["code"])
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Invalid body is rejected", func(t *testing.T) {
+		mockService := new(MockContentService)
+		handler := NewContentHandler(mockService, logger)
+
+		router := setupTestRouter(handler)
+		req := httptest.NewRequest("POST", "/api/v1/msearch", strings.NewReader(`{"searches": [{"qurey": "go"}]}`))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertNotCalled(t, "MultiSearch")
+	})
+
+	t.Run("Batch validation errors map to bad request", func(t *testing.T) {
+		mockService := new(MockContentService)
+		handler := NewContentHandler(mockService, logger)
+
+		mockService.On("MultiSearch", mock.Anything, mock.Anything).
+			Return(nil, domain.NewInvalidInputError("searches", "must contain at least one search"))
+
+		router := setupTestRouter(handler)
+		req := httptest.NewRequest("POST", "/api/v1/msearch", strings.NewReader(`{"searches": []}`))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestContentHandler_ExportSearch(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	created := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	batches := [][]*domain.Content{
+		{{ID: 1, Title: "Go, the Basics", Score: 4.5, CreatedAt: created, Tags: domain.NewTags("go", "basics")}},
+		{{ID: 2, Title: "Rust", Score: 3, CreatedAt: created}},
+	}
+
+	t.Run("Streams CSV with the selected columns", func(t *testing.T) {
+		mockService := new(MockContentService)
+		handler := NewContentHandler(mockService, logger)
+
+		mockService.On("ExportSearch", mock.Anything, mock.MatchedBy(func(req *domain.ExportRequest) bool {
+			return req.Query == "go" && req.ContentType != nil && *req.ContentType == domain.ContentTypeVideo &&
+				req.Format == domain.ExportFormatCSV
+		})).Return(batches, nil)
+
+		router := setupTestRouter(handler)
+		req := httptest.NewRequest("GET", "/api/v1/search/export?query=go&content_type=video&columns=id,title,score,tags,created_at", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+		assert.Equal(t, `attachment; filename="search-export.csv"`, w.Header().Get("Content-Disposition"))
+		assert.Equal(t, "id,title,score,tags,created_at\n"+
+			"1,\"Go, the Basics\",4.5,\"go,basics\",2024-03-01T12:00:00Z\n"+
+			"2,Rust,3,,2024-03-01T12:00:00Z\n", w.Body.String())
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Streams NDJSON", func(t *testing.T) {
+		mockService := new(MockContentService)
+		handler := NewContentHandler(mockService, logger)
+
+		mockService.On("ExportSearch", mock.Anything, mock.Anything).Return(batches, nil)
+
+		router := setupTestRouter(handler)
+		req := httptest.NewRequest("GET", "/api/v1/search/export?format=ndjson&columns=title,id,tags", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/x-ndjson", w.Header().Get("Content-Type"))
+		assert.Equal(t, `{"title":"Go, the Basics","id":1,"tags":["go","basics"]}`+"\n"+
+			`{"title":"Rust","id":2,"tags":[]}`+"\n", w.Body.String())
+	})
+
+	t.Run("No matches writes the CSV header", func(t *testing.T) {
+		mockService := new(MockContentService)
+		handler := NewContentHandler(mockService, logger)
+
+		mockService.On("ExportSearch", mock.Anything, mock.Anything).Return(nil, nil)
+
+		router := setupTestRouter(handler)
+		req := httptest.NewRequest("GET", "/api/v1/search/export?columns=id,title", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "id,title\n", w.Body.String())
+	})
+
+	t.Run("Errors while streaming abort the connection", func(t *testing.T) {
+		mockService := new(MockContentService)
+		handler := NewContentHandler(mockService, logger)
+
+		mockService.On("ExportSearch", mock.Anything, mock.Anything).
+			Return(batches[:1], domain.NewDatabaseError("export_search", assert.AnError))
+
+		router := setupTestRouter(handler)
+		req := httptest.NewRequest("GET", "/api/v1/search/export?columns=id", nil)
+		w := httptest.NewRecorder()
+
+		assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+			router.ServeHTTP(w, req)
+		})
+		assert.Equal(t, "id\n1\n", w.Body.String())
+	})
+
+	t.Run("Invalid format is rejected", func(t *testing.T) {
+		mockService := new(MockContentService)
+		handler := NewContentHandler(mockService, logger)
+
+		router := setupTestRouter(handler)
+		req := httptest.NewRequest("GET", "/api/v1/search/export?format=xlsx", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertNotCalled(t, "ExportSearch")
+	})
+
+	t.Run("Errors before streaming map to error responses", func(t *testing.T) {
+		mockService := new(MockContentService)
+		handler := NewContentHandler(mockService, logger)
+
+		mockService.On("ExportSearch", mock.Anything, mock.Anything).
+			Return(nil, domain.NewInvalidInputError("sort_by", "must be one of: score, created_at, popularity"))
+
+		router := setupTestRouter(handler)
+		req := httptest.NewRequest("GET", "/api/v1/search/export?sort_by=bogus", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "INVALID_INPUT")
+	})
+}
+
+func TestContentHandler_Suggest(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+
+	t.Run("Binds prefix, type and limit", func(t *testing.T) {
+		mockService := new(MockContentService)
+		handler := NewContentHandler(mockService, logger)
+
+		mockService.On("Suggest", mock.Anything, mock.MatchedBy(func(req *domain.SuggestRequest) bool {
+			return req.Prefix == "kube" && req.ContentType != nil && *req.ContentType == domain.ContentTypeVideo && req.Limit == 5
+		})).Return(&domain.SuggestResponse{
+			Prefix:  "kube",
+			Titles:  []*domain.Suggestion{{Text: "Kubernetes Operators", Type: domain.ContentTypeVideo, Weight: 5}},
+			Queries: []*domain.Suggestion{{Text: "kubernetes", Weight: 3}},
+		}, nil)
+
+		router := setupTestRouter(handler)
+		req := httptest.NewRequest("GET", "/api/v1/suggest?prefix=kube&type=video&limit=5", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response domain.SuggestResponse
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		assert.NoError(t, err)
+		assert.Len(t, response.Titles, 1)
+		assert.Equal(t, "kubernetes", response.Queries[0].Text)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Missing prefix is a bad request", func(t *testing.T) {
+		mockService := new(MockContentService)
+		handler := NewContentHandler(mockService, logger)
+
+		mockService.On("Suggest", mock.Anything, mock.Anything).
+			Return(nil, domain.NewInvalidInputError("prefix", "is required"))
+
+		router := setupTestRouter(handler)
+		req := httptest.NewRequest("GET", "/api/v1/suggest", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertExpectations(t)
+	})
+}
@@ -1,7 +1,11 @@
 package handler
 
 import (
+	"html/template"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
 
 	"search-engine-go/internal/domain"
 	"search-engine-go/internal/service"
@@ -10,6 +14,12 @@ import (
 	"go.uber.org/zap"
 )
 
+// Dashboard results are highlighted with <mark>, which HighlightHTML keeps while escaping the rest
+const (
+	dashboardHighlightPreTag  = "<mark>"
+	dashboardHighlightPostTag = "</mark>"
+)
+
 type DashboardHandler struct {
 	service *service.ContentService
 	log     *zap.Logger
@@ -32,11 +42,8 @@ func (h *DashboardHandler) Index(c *gin.Context) {
 		}
 	}
 
-	if req.ContentType != nil {
-		ct := string(*req.ContentType)
-		if ct == "" || (ct != string(domain.ContentTypeVideo) && ct != string(domain.ContentTypeText)) {
-			req.ContentType = nil
-		}
+	if req.ContentType != nil && !req.ContentType.IsValid() {
+		req.ContentType = nil
 	}
 
 	paginationSpec := domain.NewPaginationSpecification()
@@ -49,7 +56,28 @@ func (h *DashboardHandler) Index(c *gin.Context) {
 		req.SortOrder = "desc"
 	}
 
+	// Empty number inputs bind as zero, which filters nothing
+	if req.MinViews != nil && *req.MinViews == 0 {
+		req.MinViews = nil
+	}
+	if req.MinLikes != nil && *req.MinLikes == 0 {
+		req.MinLikes = nil
+	}
+	if req.MinScore != nil && *req.MinScore == 0 {
+		req.MinScore = nil
+	}
+
+	req.Facets = append([]string(nil), domain.SupportedFacets...)
+	req.HighlightPreTag = dashboardHighlightPreTag
+	req.HighlightPostTag = dashboardHighlightPostTag
+
 	resp, err := h.service.Search(c.Request.Context(), &req)
+	if domainErr, ok := err.(*domain.DomainError); ok && domainErr.Code == domain.ErrorCodeInvalidInput {
+		c.HTML(http.StatusBadRequest, "error.html", gin.H{
+			"error": domainErr.Message,
+		})
+		return
+	}
 	if err != nil {
 		h.log.Error("Dashboard search failed", zap.Error(err))
 		c.HTML(http.StatusInternalServerError, "error.html", gin.H{
@@ -68,17 +96,73 @@ func (h *DashboardHandler) Index(c *gin.Context) {
 		username = ""
 	}
 
+	ranges := h.rangeFilters(&req)
+	rangeQuery := ""
+	if len(ranges) > 0 {
+		rangeQuery = "&" + ranges.Encode()
+	}
+
 	c.HTML(http.StatusOK, "index.html", gin.H{
-		"title":       "Content Search Dashboard",
-		"items":       resp.Items,
-		"total":       resp.Total,
-		"page":        resp.Page,
-		"pageSize":    resp.PageSize,
-		"totalPages":  resp.TotalPages,
-		"query":       req.Query,
-		"sortBy":      req.SortBy,
-		"sortOrder":   req.SortOrder,
-		"contentType": contentType,
-		"username":    username,
+		"title":         "Content Search Dashboard",
+		"items":         resp.Items,
+		"total":         resp.Total,
+		"page":          resp.Page,
+		"pageSize":      resp.PageSize,
+		"totalPages":    resp.TotalPages,
+		"query":         req.Query,
+		"sortBy":        req.SortBy,
+		"sortOrder":     req.SortOrder,
+		"contentType":   contentType,
+		"contentTypes":  domain.ContentTypes,
+		"tags":          strings.Join(req.Tags, ","),
+		"tagMode":       req.TagMode,
+		"aggregations":  resp.Aggregations,
+		"suggestion":    resp.Suggestion,
+		"autoCorrected": resp.AutoCorrected,
+		"ranges":        ranges,
+		"rangeQuery":    template.URL(rangeQuery),
+		"username":      username,
 	})
 }
+
+// rangeFilters collects the range filters of a request as query parameters, so that facet and
+// pagination links keep them
+func (h *DashboardHandler) rangeFilters(req *domain.SearchRequest) url.Values {
+	values := url.Values{}
+	for name, value := range map[string]string{
+		"published_after":  req.PublishedAfter,
+		"published_before": req.PublishedBefore,
+	} {
+		if value != "" {
+			values.Set(name, value)
+		}
+	}
+	for name, value := range map[string]*int{
+		"min_views": req.MinViews,
+		"max_views": req.MaxViews,
+		"min_likes": req.MinLikes,
+		"max_likes": req.MaxLikes,
+	} {
+		if value != nil {
+			values.Set(name, strconv.Itoa(*value))
+		}
+	}
+	for name, value := range map[string]*float64{
+		"min_score": req.MinScore,
+		"max_score": req.MaxScore,
+	} {
+		if value != nil {
+			values.Set(name, strconv.FormatFloat(*value, 'f', -1, 64))
+		}
+	}
+	return values
+}
+
+// HighlightHTML renders a title highlighted with the dashboard tags: the title is HTML-escaped
+// and only the <mark> tags are let through
+func HighlightHTML(highlighted string) template.HTML {
+	escaped := template.HTMLEscapeString(highlighted)
+	escaped = strings.ReplaceAll(escaped, template.HTMLEscapeString(dashboardHighlightPreTag), dashboardHighlightPreTag)
+	escaped = strings.ReplaceAll(escaped, template.HTMLEscapeString(dashboardHighlightPostTag), dashboardHighlightPostTag)
+	return template.HTML(escaped)
+}
@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"search-engine-go/internal/domain"
+)
+
+// exportWriter writes exported content as rows of the selected columns. Rows are buffered
+// until Flush.
+type exportWriter interface {
+	WriteHeader() error
+	Write(content *domain.Content) error
+	Flush() error
+}
+
+// exportContentTypes maps each export format onto the content type of its response
+var exportContentTypes = map[string]string{
+	domain.ExportFormatCSV:    "text/csv; charset=utf-8",
+	domain.ExportFormatNDJSON: "application/x-ndjson",
+}
+
+func newExportWriter(format string, columns []string, w io.Writer) exportWriter {
+	if format == domain.ExportFormatNDJSON {
+		return &ndjsonExportWriter{columns: columns, w: bufio.NewWriter(w)}
+	}
+	return &csvExportWriter{columns: columns, w: csv.NewWriter(w)}
+}
+
+// csvExportWriter writes a header row of column names followed by one row per content.
+// Tags are joined with commas.
+type csvExportWriter struct {
+	columns []string
+	w       *csv.Writer
+}
+
+func (e *csvExportWriter) WriteHeader() error {
+	return e.w.Write(e.columns)
+}
+
+func (e *csvExportWriter) Write(content *domain.Content) error {
+	record := make([]string, len(e.columns))
+	for i, column := range e.columns {
+		record[i] = csvField(domain.ExportValue(content, column))
+	}
+	return e.w.Write(record)
+}
+
+func (e *csvExportWriter) Flush() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+func csvField(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case int:
+		return strconv.Itoa(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case []string:
+		return strings.Join(v, ",")
+	case time.Time:
+		return v.UTC().Format(time.RFC3339)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// ndjsonExportWriter writes one JSON object per content, keyed by column in selection order
+type ndjsonExportWriter struct {
+	columns []string
+	w       *bufio.Writer
+}
+
+func (e *ndjsonExportWriter) WriteHeader() error {
+	return nil
+}
+
+func (e *ndjsonExportWriter) Write(content *domain.Content) error {
+	e.w.WriteByte('{')
+	for i, column := range e.columns {
+		if i > 0 {
+			e.w.WriteByte(',')
+		}
+		value := domain.ExportValue(content, column)
+		if t, ok := value.(time.Time); ok {
+			value = t.UTC().Format(time.RFC3339)
+		}
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		e.w.WriteString(strconv.Quote(column))
+		e.w.WriteByte(':')
+		e.w.Write(encoded)
+	}
+	_, err := e.w.WriteString("}\n")
+	return err
+}
+
+func (e *ndjsonExportWriter) Flush() error {
+	return e.w.Flush()
+}
@@ -0,0 +1,129 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"search-engine-go/internal/api/middleware"
+	"search-engine-go/internal/domain"
+	"search-engine-go/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// SynonymHandler serves the admin API for the synonym sets used to expand search queries
+type SynonymHandler struct {
+	service service.SynonymServiceInterface
+	log     *zap.Logger
+}
+
+func NewSynonymHandler(service service.SynonymServiceInterface, log *zap.Logger) *SynonymHandler {
+	return &SynonymHandler{
+		service: service,
+		log:     log,
+	}
+}
+
+func (h *SynonymHandler) List(c *gin.Context) {
+	sets, err := h.service.List(c.Request.Context())
+	if err != nil {
+		h.log.Error("List synonym sets failed", zap.Error(err), zap.String("request_id", middleware.GetRequestID(c)))
+		respondDomainError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"items": sets})
+}
+
+func (h *SynonymHandler) Get(c *gin.Context) {
+	id, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+
+	set, err := h.service.Get(c.Request.Context(), id)
+	if err != nil {
+		h.log.Error("Get synonym set failed", zap.Error(err), zap.String("request_id", middleware.GetRequestID(c)))
+		respondDomainError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, set)
+}
+
+func (h *SynonymHandler) Create(c *gin.Context) {
+	var set domain.SynonymSet
+	if err := c.ShouldBindJSON(&set); err != nil {
+		h.log.Warn("Invalid synonym set", zap.Error(err), zap.String("request_id", middleware.GetRequestID(c)))
+		respondDomainError(c, domain.NewInvalidInputError("body", err.Error()))
+		return
+	}
+
+	created, err := h.service.Create(c.Request.Context(), &set)
+	if err != nil {
+		h.log.Error("Create synonym set failed", zap.Error(err), zap.String("request_id", middleware.GetRequestID(c)))
+		respondDomainError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, created)
+}
+
+func (h *SynonymHandler) Update(c *gin.Context) {
+	id, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+
+	var set domain.SynonymSet
+	if err := c.ShouldBindJSON(&set); err != nil {
+		h.log.Warn("Invalid synonym set", zap.Error(err), zap.String("request_id", middleware.GetRequestID(c)))
+		respondDomainError(c, domain.NewInvalidInputError("body", err.Error()))
+		return
+	}
+
+	updated, err := h.service.Update(c.Request.Context(), id, &set)
+	if err != nil {
+		h.log.Error("Update synonym set failed", zap.Error(err), zap.String("request_id", middleware.GetRequestID(c)))
+		respondDomainError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, updated)
+}
+
+func (h *SynonymHandler) Delete(c *gin.Context) {
+	id, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.Delete(c.Request.Context(), id); err != nil {
+		h.log.Error("Delete synonym set failed", zap.Error(err), zap.String("request_id", middleware.GetRequestID(c)))
+		respondDomainError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// Reload rebuilds the synonym dictionary right away, e.g. after editing the synonyms file
+func (h *SynonymHandler) Reload(c *gin.Context) {
+	if err := h.service.Reload(c.Request.Context()); err != nil {
+		h.log.Error("Reload synonyms failed", zap.Error(err), zap.String("request_id", middleware.GetRequestID(c)))
+		respondDomainError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "reloaded"})
+}
+
+func (h *SynonymHandler) parseID(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		respondDomainError(c, domain.NewInvalidInputError("id", "must be a valid integer"))
+		return 0, false
+	}
+	return id, true
+}
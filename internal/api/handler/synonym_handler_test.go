@@ -0,0 +1,182 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"search-engine-go/internal/domain"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+)
+
+type MockSynonymService struct {
+	mock.Mock
+}
+
+func (m *MockSynonymService) List(ctx context.Context) ([]*domain.SynonymSet, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.SynonymSet), args.Error(1)
+}
+
+func (m *MockSynonymService) Get(ctx context.Context, id int64) (*domain.SynonymSet, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.SynonymSet), args.Error(1)
+}
+
+func (m *MockSynonymService) Create(ctx context.Context, set *domain.SynonymSet) (*domain.SynonymSet, error) {
+	args := m.Called(ctx, set)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.SynonymSet), args.Error(1)
+}
+
+func (m *MockSynonymService) Update(ctx context.Context, id int64, set *domain.SynonymSet) (*domain.SynonymSet, error) {
+	args := m.Called(ctx, id, set)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.SynonymSet), args.Error(1)
+}
+
+func (m *MockSynonymService) Delete(ctx context.Context, id int64) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockSynonymService) Reload(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
+
+func setupSynonymTestRouter(handler *SynonymHandler) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	admin := router.Group("/api/v1/admin/synonyms")
+	{
+		admin.GET("", handler.List)
+		admin.POST("", handler.Create)
+		admin.POST("/reload", handler.Reload)
+		admin.GET("/:id", handler.Get)
+		admin.PUT("/:id", handler.Update)
+		admin.DELETE("/:id", handler.Delete)
+	}
+	return router
+}
+
+func TestSynonymHandler(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+
+	t.Run("List", func(t *testing.T) {
+		mockService := new(MockSynonymService)
+		mockService.On("List", mock.Anything).Return([]*domain.SynonymSet{{ID: 1, Name: "infra"}}, nil)
+
+		router := setupSynonymTestRouter(NewSynonymHandler(mockService, logger))
+		req := httptest.NewRequest("GET", "/api/v1/admin/synonyms", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var body struct {
+			Items []*domain.SynonymSet `json:"items"`
+		}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		assert.Len(t, body.Items, 1)
+	})
+
+	t.Run("Create decodes the set", func(t *testing.T) {
+		mockService := new(MockSynonymService)
+		mockService.On("Create", mock.Anything, mock.MatchedBy(func(set *domain.SynonymSet) bool {
+			return set.Name == "infra" && len(set.Rules) == 1 && len(set.Rules[0].Terms) == 2
+		})).Return(&domain.SynonymSet{ID: 1, Name: "infra"}, nil)
+
+		router := setupSynonymTestRouter(NewSynonymHandler(mockService, logger))
+		body := `{"name": "infra", "rules": [{"terms": ["k8s", "kubernetes"]}]}`
+		req := httptest.NewRequest("POST", "/api/v1/admin/synonyms", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Create rejects invalid sets", func(t *testing.T) {
+		mockService := new(MockSynonymService)
+		mockService.On("Create", mock.Anything, mock.Anything).
+			Return(nil, domain.NewInvalidInputError("rules", "must contain at least one rule"))
+
+		router := setupSynonymTestRouter(NewSynonymHandler(mockService, logger))
+		req := httptest.NewRequest("POST", "/api/v1/admin/synonyms", strings.NewReader(`{"name": "infra"}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Update passes the id", func(t *testing.T) {
+		mockService := new(MockSynonymService)
+		mockService.On("Update", mock.Anything, int64(3), mock.Anything).Return(&domain.SynonymSet{ID: 3, Name: "infra"}, nil)
+
+		router := setupSynonymTestRouter(NewSynonymHandler(mockService, logger))
+		body := `{"name": "infra", "rules": [{"terms": ["js"], "synonyms": ["javascript"]}]}`
+		req := httptest.NewRequest("PUT", "/api/v1/admin/synonyms/3", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Delete unknown set", func(t *testing.T) {
+		mockService := new(MockSynonymService)
+		mockService.On("Delete", mock.Anything, int64(9)).Return(domain.NewNotFoundError("synonym set", int64(9)))
+
+		router := setupSynonymTestRouter(NewSynonymHandler(mockService, logger))
+		req := httptest.NewRequest("DELETE", "/api/v1/admin/synonyms/9", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("Invalid id", func(t *testing.T) {
+		mockService := new(MockSynonymService)
+
+		router := setupSynonymTestRouter(NewSynonymHandler(mockService, logger))
+		req := httptest.NewRequest("GET", "/api/v1/admin/synonyms/abc", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertNotCalled(t, "Get")
+	})
+
+	t.Run("Reload", func(t *testing.T) {
+		mockService := new(MockSynonymService)
+		mockService.On("Reload", mock.Anything).Return(nil)
+
+		router := setupSynonymTestRouter(NewSynonymHandler(mockService, logger))
+		req := httptest.NewRequest("POST", "/api/v1/admin/synonyms/reload", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+	})
+}
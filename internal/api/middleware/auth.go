@@ -74,6 +74,27 @@ func JWTAuth(jwtService *service.JWTService, log *zap.Logger) gin.HandlerFunc {
 		}
 
 		c.Set("username", claims.Username)
+		c.Set("role", claims.Role)
+		c.Next()
+	}
+}
+
+// RequireRole rejects requests whose token, checked by JWTAuth, does not carry role
+func RequireRole(role string, log *zap.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetString("role") != role {
+			log.Warn("Insufficient role",
+				zap.String("username", c.GetString("username")),
+				zap.String("path", c.Request.URL.Path),
+				zap.String("method", c.Request.Method),
+			)
+			c.JSON(http.StatusForbidden, gin.H{
+				"error":   "Forbidden",
+				"message": "This endpoint requires the " + role + " role.",
+			})
+			c.Abort()
+			return
+		}
 		c.Next()
 	}
 }
@@ -8,12 +8,20 @@ import (
 )
 
 func Recovery(log *zap.Logger) gin.HandlerFunc {
-	return gin.CustomRecovery(func(c *gin.Context, recovered interface{}) {
+	// gin's own stack dump is turned off: it would report aborted handlers as panics too
+	return gin.CustomRecoveryWithWriter(nil, func(c *gin.Context, recovered interface{}) {
+		// handlers abort responses they cannot finish, such as failed streams, with
+		// http.ErrAbortHandler; the server then drops the connection instead of ending the response
+		if recovered == http.ErrAbortHandler {
+			panic(recovered)
+		}
+
 		requestID := GetRequestID(c)
 		log.Error("Panic recovered",
 			zap.Any("error", recovered),
 			zap.String("path", c.Request.URL.Path),
 			zap.String("request_id", requestID),
+			zap.Stack("stack"),
 		)
 
 		c.JSON(http.StatusInternalServerError, gin.H{
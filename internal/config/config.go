@@ -16,6 +16,7 @@ type Config struct {
 	Providers   ProvidersConfig
 	Log         LogConfig
 	Auth        AuthConfig
+	Search      SearchConfig
 }
 
 type ServerConfig struct {
@@ -67,6 +68,28 @@ type LogConfig struct {
 	Output string
 }
 
+// SearchConfig holds the weights sort_by=relevance blends text relevance and stored score with,
+// and the backend that matches and ranks titles: "sql" for the database's text search or
+// "index" for the in-process inverted index snapshotted to IndexPath every IndexSaveInterval
+// and on shutdown. Synonym sets come from
+// the database and the optional SynonymsFile, and are reloaded every SynonymReloadInterval.
+// StopwordsFile optionally adds stopwords per language to the built-in lists removed from
+// queries; content is still indexed with the built-in lists. Stored trending
+// scores are recomputed every TrendingRefreshInterval. Searched queries are counted in memory
+// and written to the query log every QueryLogFlushInterval and on shutdown.
+type SearchConfig struct {
+	RelevanceTextWeight     float64
+	RelevanceScoreWeight    float64
+	Backend                 string
+	IndexPath               string
+	IndexSaveInterval       time.Duration
+	SynonymsFile            string
+	SynonymReloadInterval   time.Duration
+	StopwordsFile           string
+	TrendingRefreshInterval time.Duration
+	QueryLogFlushInterval   time.Duration
+}
+
 type AuthConfig struct {
 	JWTSecret     string
 	JWTExpiration time.Duration
@@ -129,6 +152,18 @@ func Load() (*Config, error) {
 			JWTSecret:     getEnv("JWT_SECRET", "your-secret-key-change-in-production"),
 			JWTExpiration: getEnvAsDuration("JWT_EXPIRATION", 24*time.Hour),
 		},
+		Search: SearchConfig{
+			RelevanceTextWeight:     getEnvAsFloat("SEARCH_RELEVANCE_TEXT_WEIGHT", 0.7),
+			RelevanceScoreWeight:    getEnvAsFloat("SEARCH_RELEVANCE_SCORE_WEIGHT", 0.3),
+			Backend:                 getEnv("SEARCH_BACKEND", "sql"),
+			IndexPath:               getEnv("SEARCH_INDEX_PATH", "data/search-index.gob"),
+			IndexSaveInterval:       getEnvAsDuration("SEARCH_INDEX_SAVE_INTERVAL", time.Minute),
+			SynonymsFile:            getEnv("SEARCH_SYNONYMS_FILE", ""),
+			SynonymReloadInterval:   getEnvAsDuration("SEARCH_SYNONYM_RELOAD_INTERVAL", 30*time.Second),
+			StopwordsFile:           getEnv("SEARCH_STOPWORDS_FILE", ""),
+			TrendingRefreshInterval: getEnvAsDuration("SEARCH_TRENDING_REFRESH_INTERVAL", 5*time.Minute),
+			QueryLogFlushInterval:   getEnvAsDuration("SEARCH_QUERY_LOG_FLUSH_INTERVAL", 10*time.Second),
+		},
 	}
 
 	return cfg, nil
@@ -149,6 +184,14 @@ func getEnvAsInt(key string, defaultValue int) int {
 	return defaultValue
 }
 
+func getEnvAsFloat(key string, defaultValue float64) float64 {
+	valueStr := getEnv(key, "")
+	if value, err := strconv.ParseFloat(valueStr, 64); err == nil {
+		return value
+	}
+	return defaultValue
+}
+
 func getEnvAsDuration(key string, defaultValue time.Duration) time.Duration {
 	valueStr := getEnv(key, "")
 	if value, err := time.ParseDuration(valueStr); err == nil {
@@ -0,0 +1,97 @@
+package domain
+
+import (
+	"strconv"
+	"strings"
+)
+
+const (
+	BoostFieldTitle    = "title"
+	BoostFieldTags     = "tags"
+	BoostFieldProvider = "provider"
+
+	// MaxBoost is the largest boost a field may be given
+	MaxBoost = 100.0
+)
+
+// FieldBoosts weights how much a match in each field of the search vector counts towards text
+// relevance. The title has weight A in the vector, tags B and the provider C.
+type FieldBoosts struct {
+	Title    float64
+	Tags     float64
+	Provider float64
+}
+
+// DefaultFieldBoosts are PostgreSQL's default weights for A, B and C, so that title matches
+// outrank tag-only matches, which outrank provider-only matches
+var DefaultFieldBoosts = FieldBoosts{Title: 1.0, Tags: 0.4, Provider: 0.2}
+
+// Normalized scales the boosts so that the largest is 1, the range ts_rank accepts weights in
+func (b FieldBoosts) Normalized() FieldBoosts {
+	largest := max(b.Title, b.Tags, b.Provider)
+	if largest == 0 {
+		return DefaultFieldBoosts
+	}
+	return FieldBoosts{Title: b.Title / largest, Tags: b.Tags / largest, Provider: b.Provider / largest}
+}
+
+// String formats the boosts the way the boost parameter takes them
+func (b FieldBoosts) String() string {
+	return strings.Join([]string{
+		BoostFieldTitle + ":" + strconv.FormatFloat(b.Title, 'f', -1, 64),
+		BoostFieldTags + ":" + strconv.FormatFloat(b.Tags, 'f', -1, 64),
+		BoostFieldProvider + ":" + strconv.FormatFloat(b.Provider, 'f', -1, 64),
+	}, ",")
+}
+
+// SearchBoosts returns the field boosts of a search, or the defaults when it sets none
+func SearchBoosts(req *SearchRequest) FieldBoosts {
+	if req.Boosts != nil {
+		return *req.Boosts
+	}
+	return DefaultFieldBoosts
+}
+
+type BoostSpecification struct{}
+
+func NewBoostSpecification() *BoostSpecification {
+	return &BoostSpecification{}
+}
+
+// NormalizeBoost parses a boost setting such as "title:3,tags:1" into field boosts. Fields left
+// out keep their default boost; at least one field must keep a positive boost.
+func (s *BoostSpecification) NormalizeBoost(req *SearchRequest) error {
+	req.Boosts = nil
+	if strings.TrimSpace(req.Boost) == "" {
+		return nil
+	}
+
+	boosts := DefaultFieldBoosts
+	for _, entry := range strings.Split(req.Boost, ",") {
+		field, raw, ok := strings.Cut(strings.TrimSpace(entry), ":")
+		if !ok {
+			return NewInvalidInputError("boost", "must be a comma-separated list of field:boost pairs")
+		}
+		value, err := strconv.ParseFloat(strings.TrimSpace(raw), 64)
+		if err != nil || !(value >= 0 && value <= MaxBoost) {
+			return NewInvalidInputError("boost", "boost of "+field+" must be a number between 0 and 100")
+		}
+		switch strings.ToLower(strings.TrimSpace(field)) {
+		case BoostFieldTitle:
+			boosts.Title = value
+		case BoostFieldTags:
+			boosts.Tags = value
+		case BoostFieldProvider:
+			boosts.Provider = value
+		default:
+			return NewInvalidInputError("boost", "field must be one of: title, tags, provider")
+		}
+	}
+	if boosts.Title+boosts.Tags+boosts.Provider == 0 {
+		return NewInvalidInputError("boost", "at least one field must have a positive boost")
+	}
+
+	req.Boost = boosts.String()
+	req.Boosts = &boosts
+	return nil
+}
@@ -0,0 +1,46 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBoostSpecification_NormalizeBoost(t *testing.T) {
+	spec := NewBoostSpecification()
+
+	t.Run("Empty boost keeps the defaults", func(t *testing.T) {
+		req := &SearchRequest{Boost: " "}
+
+		require.NoError(t, spec.NormalizeBoost(req))
+		assert.Nil(t, req.Boosts)
+		assert.Equal(t, DefaultFieldBoosts, SearchBoosts(req))
+	})
+
+	t.Run("Overrides the given fields", func(t *testing.T) {
+		req := &SearchRequest{Boost: "title:3, TAGS:1"}
+
+		require.NoError(t, spec.NormalizeBoost(req))
+		assert.Equal(t, FieldBoosts{Title: 3, Tags: 1, Provider: 0.2}, *req.Boosts)
+		assert.Equal(t, "title:3,tags:1,provider:0.2", req.Boost)
+	})
+
+	for _, value := range []string{"title", "title:x", "title:-1", "title:101", "title:NaN", "title:Inf", "author:2", "title:0,tags:0,provider:0"} {
+		t.Run("Rejects "+value, func(t *testing.T) {
+			err := spec.NormalizeBoost(&SearchRequest{Boost: value})
+
+			require.Error(t, err)
+			domainErr, ok := err.(*DomainError)
+			require.True(t, ok)
+			assert.Equal(t, ErrorCodeInvalidInput, domainErr.Code)
+			assert.Equal(t, "boost", domainErr.Details["field"])
+		})
+	}
+}
+
+func TestFieldBoosts_Normalized(t *testing.T) {
+	assert.Equal(t, FieldBoosts{Title: 1, Tags: 0.5, Provider: 0}, FieldBoosts{Title: 4, Tags: 2}.Normalized())
+	assert.Equal(t, FieldBoosts{Title: 0.5, Tags: 1, Provider: 0.25}, FieldBoosts{Title: 2, Tags: 4, Provider: 1}.Normalized())
+	assert.Equal(t, DefaultFieldBoosts, DefaultFieldBoosts.Normalized())
+}
@@ -0,0 +1,37 @@
+package domain
+
+import (
+	"strings"
+)
+
+const (
+	CollapseProvider = "provider"
+	CollapseType     = "type"
+)
+
+// InnerHits summarizes the group a collapsed search hit stands in for
+type InnerHits struct {
+	Total int `json:"total"`
+}
+
+type CollapseSpecification struct{}
+
+func NewCollapseSpecification() *CollapseSpecification {
+	return &CollapseSpecification{}
+}
+
+// NormalizeCollapse lowercases the collapse field and rejects fields results cannot be grouped by
+func (s *CollapseSpecification) NormalizeCollapse(req *SearchRequest) error {
+	req.Collapse = strings.ToLower(strings.TrimSpace(req.Collapse))
+	if req.Collapse == "" {
+		return nil
+	}
+	if !s.isCollapsibleField(req.Collapse) {
+		return NewInvalidInputError("collapse", "must be one of: provider, type")
+	}
+	return nil
+}
+
+func (s *CollapseSpecification) isCollapsibleField(field string) bool {
+	return field == CollapseProvider || field == CollapseType
+}
@@ -0,0 +1,36 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCollapseSpecification_NormalizeCollapse(t *testing.T) {
+	spec := NewCollapseSpecification()
+
+	t.Run("Empty collapse is allowed", func(t *testing.T) {
+		req := &SearchRequest{}
+
+		assert.NoError(t, spec.NormalizeCollapse(req))
+		assert.Equal(t, "", req.Collapse)
+	})
+
+	t.Run("Normalizes supported fields", func(t *testing.T) {
+		req := &SearchRequest{Collapse: " Provider "}
+
+		assert.NoError(t, spec.NormalizeCollapse(req))
+		assert.Equal(t, CollapseProvider, req.Collapse)
+	})
+
+	t.Run("Rejects unsupported fields", func(t *testing.T) {
+		req := &SearchRequest{Collapse: "title"}
+
+		err := spec.NormalizeCollapse(req)
+
+		assert.Error(t, err)
+		domainErr, ok := err.(*DomainError)
+		assert.True(t, ok)
+		assert.Equal(t, ErrorCodeInvalidInput, domainErr.Code)
+	})
+}
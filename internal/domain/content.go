@@ -3,6 +3,7 @@ package domain
 import (
 	"database/sql/driver"
 	"errors"
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
@@ -11,10 +12,52 @@ import (
 type ContentType string
 
 const (
-	ContentTypeVideo ContentType = "video"
-	ContentTypeText  ContentType = "text"
+	ContentTypeVideo   ContentType = "video"
+	ContentTypeText    ContentType = "text"
+	ContentTypeAudio   ContentType = "audio"
+	ContentTypeGallery ContentType = "gallery"
 )
 
+// ContentTypes lists every supported content type in display order
+var ContentTypes = []ContentType{
+	ContentTypeVideo,
+	ContentTypeText,
+	ContentTypeAudio,
+	ContentTypeGallery,
+}
+
+// providerContentTypeAliases maps the type labels used by providers onto our content types
+var providerContentTypeAliases = map[string]ContentType{
+	"video":   ContentTypeVideo,
+	"text":    ContentTypeText,
+	"article": ContentTypeText,
+	"post":    ContentTypeText,
+	"audio":   ContentTypeAudio,
+	"podcast": ContentTypeAudio,
+	"episode": ContentTypeAudio,
+	"gallery": ContentTypeGallery,
+	"image":   ContentTypeGallery,
+	"images":  ContentTypeGallery,
+	"photo":   ContentTypeGallery,
+	"album":   ContentTypeGallery,
+}
+
+// IsValid reports whether ct is one of the supported content types
+func (ct ContentType) IsValid() bool {
+	for _, known := range ContentTypes {
+		if ct == known {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseProviderContentType resolves a provider type label, reporting false for unknown labels
+func ParseProviderContentType(label string) (ContentType, bool) {
+	ct, ok := providerContentTypeAliases[strings.ToLower(strings.TrimSpace(label))]
+	return ct, ok
+}
+
 // Value implements the driver.Valuer interface for ContentType
 func (ct ContentType) Value() (driver.Value, error) {
 	return string(ct), nil
@@ -37,38 +80,104 @@ func (ct *ContentType) Scan(value interface{}) error {
 }
 
 type Content struct {
-	ID          int64          `json:"id" gorm:"primaryKey;autoIncrement"`
-	ProviderID  string         `json:"provider_id" gorm:"type:varchar(255);not null;uniqueIndex:idx_provider_content"`
-	Provider    string         `json:"provider" gorm:"type:varchar(100);not null;uniqueIndex:idx_provider_content"`
-	Title       string         `json:"title" gorm:"type:varchar(500);not null"`
-	Type        ContentType    `json:"type" gorm:"type:content_type;not null;index"`
-	Views       int            `json:"views" gorm:"default:0"`
-	Likes       int            `json:"likes" gorm:"default:0"`
-	ReadingTime int            `json:"reading_time" gorm:"default:0"`
-	Reactions   int            `json:"reactions" gorm:"default:0"`
-	Score       float64        `json:"score" gorm:"type:decimal(10,4);default:0;index"`
-	CreatedAt   time.Time      `json:"created_at" gorm:"index"`
-	UpdatedAt   time.Time      `json:"updated_at"`
-	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"`
+	ID              int64           `json:"id" gorm:"primaryKey;autoIncrement"`
+	ProviderID      string          `json:"provider_id" gorm:"type:varchar(255);not null;uniqueIndex:idx_provider_content"`
+	Provider        string          `json:"provider" gorm:"type:varchar(100);not null;uniqueIndex:idx_provider_content"`
+	Title           string          `json:"title" gorm:"type:varchar(500);not null"`
+	Type            ContentType     `json:"type" gorm:"type:content_type;not null;index"`
+	Language        string          `json:"language" gorm:"type:varchar(8);not null;default:'en'"`
+	Views           int             `json:"views" gorm:"default:0"`
+	Likes           int             `json:"likes" gorm:"default:0"`
+	ReadingTime     int             `json:"reading_time" gorm:"default:0"`
+	Reactions       int             `json:"reactions" gorm:"default:0"`
+	Duration        int             `json:"duration" gorm:"default:0"`
+	Comments        int             `json:"comments" gorm:"default:0"`
+	Listens         int             `json:"listens" gorm:"default:0"`
+	ImageCount      int             `json:"image_count" gorm:"default:0"`
+	Score           float64         `json:"score" gorm:"type:decimal(10,4);default:0;index"`
+	TrendingScore   float64         `json:"trending_score" gorm:"type:decimal(12,4);default:0"`
+	CanonicalID     *int64          `json:"canonical_id,omitempty" gorm:"index"`
+	NormalizedTitle string          `json:"-" gorm:"type:varchar(500);index"`
+	SimHash         int64           `json:"-" gorm:"default:0"`
+	SimHashBand0    int             `json:"-" gorm:"default:0;index"`
+	SimHashBand1    int             `json:"-" gorm:"default:0;index"`
+	SimHashBand2    int             `json:"-" gorm:"default:0;index"`
+	SimHashBand3    int             `json:"-" gorm:"default:0;index"`
+	Tags            []Tag           `json:"tags" gorm:"many2many:content_tags;"`
+	Sources         []ContentSource `json:"sources,omitempty" gorm:"-"`
+	InnerHits       *InnerHits      `json:"inner_hits,omitempty" gorm:"-"`
+	Highlights      *Highlights     `json:"highlights,omitempty" gorm:"-"`
+	Relevance       *float64        `json:"relevance,omitempty" gorm:"-"`
+	CreatedAt       time.Time       `json:"created_at" gorm:"index"`
+	UpdatedAt       time.Time       `json:"updated_at"`
+	DeletedAt       gorm.DeletedAt  `json:"-" gorm:"index"`
 }
 
 func (Content) TableName() string {
 	return "contents"
 }
 
+// IsCanonical reports whether the content is the representative record of its duplicate cluster
+func (c *Content) IsCanonical() bool {
+	return c.CanonicalID == nil
+}
+
+// ClusterID returns the ID of the canonical record the content belongs to
+func (c *Content) ClusterID() int64 {
+	if c.IsCanonical() {
+		return c.ID
+	}
+	return *c.CanonicalID
+}
+
 type SearchRequest struct {
-	Query       string       `json:"query" form:"query"`
-	ContentType *ContentType `json:"content_type,omitempty" form:"content_type"`
-	Page        int          `json:"page" form:"page"`
-	PageSize    int          `json:"page_size" form:"page_size"`
-	SortBy      string       `json:"sort_by" form:"sort_by"`
-	SortOrder   string       `json:"sort_order" form:"sort_order"`
+	Query               string        `json:"query" form:"query"`
+	ContentType         *ContentType  `json:"content_type,omitempty" form:"content_type"`
+	Tags                []string      `json:"tags,omitempty" form:"tags"`
+	TagMode             string        `json:"tag_mode,omitempty" form:"tag_mode"`
+	MinDuration         *int          `json:"min_duration,omitempty" form:"min_duration"`
+	MaxDuration         *int          `json:"max_duration,omitempty" form:"max_duration"`
+	PublishedAfter      string        `json:"published_after,omitempty" form:"published_after"`
+	PublishedBefore     string        `json:"published_before,omitempty" form:"published_before"`
+	MinViews            *int          `json:"min_views,omitempty" form:"min_views"`
+	MaxViews            *int          `json:"max_views,omitempty" form:"max_views"`
+	MinLikes            *int          `json:"min_likes,omitempty" form:"min_likes"`
+	MaxLikes            *int          `json:"max_likes,omitempty" form:"max_likes"`
+	MinScore            *float64      `json:"min_score,omitempty" form:"min_score"`
+	MaxScore            *float64      `json:"max_score,omitempty" form:"max_score"`
+	Collapse            string        `json:"collapse,omitempty" form:"collapse"`
+	Facets              []string      `json:"facets,omitempty" form:"facets"`
+	Fuzzy               string        `json:"fuzzy,omitempty" form:"fuzzy"`
+	AutoCorrect         bool          `json:"auto_correct,omitempty" form:"auto_correct"`
+	HighlightPreTag     string        `json:"highlight_pre_tag,omitempty" form:"highlight_pre_tag"`
+	HighlightPostTag    string        `json:"highlight_post_tag,omitempty" form:"highlight_post_tag"`
+	Language            string        `json:"language,omitempty" form:"language"`
+	Expand              *bool         `json:"expand,omitempty" form:"expand"`
+	Boost               string        `json:"boost,omitempty" form:"boost"`
+	Boosts              *FieldBoosts  `json:"-" form:"-"`
+	AnalyzedQuery       QueryNode     `json:"-" form:"-"`
+	Filter              QueryNode     `json:"-" form:"-"`
+	Sort                []SortField   `json:"-" form:"-"`
+	After               *SearchCursor `json:"-" form:"-"`
+	PublishedAfterTime  *time.Time    `json:"-" form:"-"`
+	PublishedBeforeTime *time.Time    `json:"-" form:"-"`
+	Cursor              string        `json:"cursor,omitempty" form:"cursor"`
+	Page                int           `json:"page" form:"page"`
+	PageSize            int           `json:"page_size" form:"page_size"`
+	SortBy              string        `json:"sort_by" form:"sort_by"`
+	SortOrder           string        `json:"sort_order" form:"sort_order"`
 }
 
 type SearchResponse struct {
-	Items      []*Content `json:"items"`
-	Total      int        `json:"total"`
-	Page       int        `json:"page"`
-	PageSize   int        `json:"page_size"`
-	TotalPages int        `json:"total_pages"`
+	Items        []*Content   `json:"items"`
+	Total        int          `json:"total"`
+	Page         int          `json:"page"`
+	PageSize     int          `json:"page_size"`
+	TotalPages   int          `json:"total_pages"`
+	NextCursor   string       `json:"next_cursor,omitempty"`
+	Aggregations Aggregations `json:"aggregations,omitempty"`
+	// Suggestion is a spelling-corrected query offered when a search finds nothing;
+	// AutoCorrected reports that the results are for the suggestion instead
+	Suggestion    string `json:"suggestion,omitempty"`
+	AutoCorrected bool   `json:"auto_corrected,omitempty"`
 }
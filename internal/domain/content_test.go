@@ -0,0 +1,40 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContentType_IsValid(t *testing.T) {
+	for _, ct := range ContentTypes {
+		assert.True(t, ct.IsValid(), string(ct))
+	}
+	assert.False(t, ContentType("hologram").IsValid())
+	assert.False(t, ContentType("").IsValid())
+}
+
+func TestParseProviderContentType(t *testing.T) {
+	tests := []struct {
+		label    string
+		expected ContentType
+		ok       bool
+	}{
+		{label: "video", expected: ContentTypeVideo, ok: true},
+		{label: "article", expected: ContentTypeText, ok: true},
+		{label: "Podcast", expected: ContentTypeAudio, ok: true},
+		{label: "episode", expected: ContentTypeAudio, ok: true},
+		{label: "gallery", expected: ContentTypeGallery, ok: true},
+		{label: "photo", expected: ContentTypeGallery, ok: true},
+		{label: "hologram", ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.label, func(t *testing.T) {
+			ct, ok := ParseProviderContentType(tt.label)
+
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.expected, ct)
+		})
+	}
+}
@@ -0,0 +1,183 @@
+package domain
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// SearchCursor marks the last item of a page in keyset pagination: the values of the sort
+// fields, the exact-match rank of fuzzy searches and the ID that breaks ties between them.
+// Sort records the ordering the cursor was issued for so it cannot be replayed against another.
+type SearchCursor struct {
+	Sort   string            `json:"s"`
+	Exact  int               `json:"e,omitempty"`
+	Values []json.RawMessage `json:"v"`
+	ID     int64             `json:"id"`
+}
+
+// Value returns the cursor value of the i-th sort field, typed like the column it compares to
+func (c *SearchCursor) Value(i int, field string) (interface{}, error) {
+	if i >= len(c.Values) {
+		return nil, NewInvalidInputError("cursor", "is malformed")
+	}
+	switch field {
+	case "created_at":
+		var value time.Time
+		if err := json.Unmarshal(c.Values[i], &value); err != nil {
+			return nil, NewInvalidInputError("cursor", "is malformed")
+		}
+		return value, nil
+	case "score", "trending_score", SortByRelevance:
+		var value float64
+		if err := json.Unmarshal(c.Values[i], &value); err != nil {
+			return nil, NewInvalidInputError("cursor", "is malformed")
+		}
+		return value, nil
+	default:
+		var value int64
+		if err := json.Unmarshal(c.Values[i], &value); err != nil {
+			return nil, NewInvalidInputError("cursor", "is malformed")
+		}
+		return value, nil
+	}
+}
+
+type CursorSpecification struct{}
+
+func NewCursorSpecification() *CursorSpecification {
+	return &CursorSpecification{}
+}
+
+// NormalizeCursor decodes the request cursor into req.After. A cursor replaces the page
+// number, so it cannot be combined with a page other than the first, and it must have been
+// issued for the same ordering as the request. Call it after the sort and fuzzy settings
+// have been normalized.
+func (s *CursorSpecification) NormalizeCursor(req *SearchRequest) error {
+	req.Cursor = strings.TrimSpace(req.Cursor)
+	req.After = nil
+	if req.Cursor == "" {
+		return nil
+	}
+	if req.Page > 1 {
+		return NewInvalidInputError("cursor", "cannot be combined with page")
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(req.Cursor)
+	if err != nil {
+		return NewInvalidInputError("cursor", "is malformed")
+	}
+	var cursor SearchCursor
+	if err := json.Unmarshal(raw, &cursor); err != nil {
+		return NewInvalidInputError("cursor", "is malformed")
+	}
+	if cursor.Sort != s.sortSignature(req) {
+		return NewInvalidInputError("cursor", "was issued for a different sort order")
+	}
+
+	fields := SearchSortFields(req)
+	if len(cursor.Values) != len(fields) {
+		return NewInvalidInputError("cursor", "is malformed")
+	}
+	for i, field := range fields {
+		if _, err := cursor.Value(i, field.Field); err != nil {
+			return err
+		}
+	}
+
+	req.After = &cursor
+	return nil
+}
+
+// Encode returns the opaque form of the cursor used in next_cursor and the cursor parameter
+func (c *SearchCursor) Encode() (string, error) {
+	raw, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+// CursorAfter returns the cursor pointing just past content in the request's ordering
+func (s *CursorSpecification) CursorAfter(req *SearchRequest, content *Content, exact int) (*SearchCursor, error) {
+	fields := SearchSortFields(req)
+	cursor := &SearchCursor{
+		Sort:   s.sortSignature(req),
+		Exact:  exact,
+		Values: make([]json.RawMessage, 0, len(fields)),
+		ID:     content.ID,
+	}
+	for _, field := range fields {
+		value, err := json.Marshal(s.sortValue(content, field.Field))
+		if err != nil {
+			return nil, err
+		}
+		cursor.Values = append(cursor.Values, value)
+	}
+	return cursor, nil
+}
+
+// SearchSortFields returns the fields a search is ordered by, before the exact-match rank of
+// fuzzy searches and the ID tie-breaker are applied
+func SearchSortFields(req *SearchRequest) []SortField {
+	if len(req.Sort) > 0 {
+		return req.Sort
+	}
+
+	order := "desc"
+	if req.SortOrder == "asc" {
+		order = "asc"
+	}
+
+	switch req.SortBy {
+	case "created_at":
+		return []SortField{{Field: "created_at", Order: order}}
+	case "trending":
+		return []SortField{{Field: "trending_score", Order: order}}
+	case "duration":
+		return []SortField{{Field: "duration", Order: order}}
+	case "popularity":
+		return []SortField{{Field: "views", Order: order}, {Field: "likes", Order: order}}
+	case SortByRelevance:
+		return []SortField{{Field: SortByRelevance, Order: order}}
+	default:
+		return []SortField{{Field: "score", Order: order}}
+	}
+}
+
+func (s *CursorSpecification) sortSignature(req *SearchRequest) string {
+	fields := SearchSortFields(req)
+	parts := make([]string, 0, len(fields)+1)
+	if req.Fuzzy != "" && req.Fuzzy != "0" {
+		parts = append(parts, "exact")
+	}
+	for _, field := range fields {
+		parts = append(parts, field.Field+" "+field.Order)
+	}
+	return strings.Join(parts, ",")
+}
+
+func (s *CursorSpecification) sortValue(content *Content, field string) interface{} {
+	switch field {
+	case "created_at":
+		return content.CreatedAt
+	case "trending_score":
+		return content.TrendingScore
+	case "views":
+		return content.Views
+	case "likes":
+		return content.Likes
+	case "comments":
+		return content.Comments
+	case "duration":
+		return content.Duration
+	case SortByRelevance:
+		if content.Relevance == nil {
+			return 0.0
+		}
+		return *content.Relevance
+	default:
+		return content.Score
+	}
+}
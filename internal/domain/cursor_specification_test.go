@@ -0,0 +1,125 @@
+package domain
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchSortFields(t *testing.T) {
+	tests := []struct {
+		name     string
+		req      *SearchRequest
+		expected []SortField
+	}{
+		{"Default is score", &SearchRequest{}, []SortField{{"score", "desc"}}},
+		{"Ascending", &SearchRequest{SortBy: "created_at", SortOrder: "asc"}, []SortField{{"created_at", "asc"}}},
+		{"Trending", &SearchRequest{SortBy: "trending"}, []SortField{{"trending_score", "desc"}}},
+		{"Popularity uses two fields", &SearchRequest{SortBy: "popularity"}, []SortField{{"views", "desc"}, {"likes", "desc"}}},
+		{"Relevance", &SearchRequest{SortBy: SortByRelevance}, []SortField{{SortByRelevance, "desc"}}},
+		{"Document sort wins", &SearchRequest{SortBy: "duration", Sort: []SortField{{"likes", "asc"}}}, []SortField{{"likes", "asc"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, SearchSortFields(tt.req))
+		})
+	}
+}
+
+func TestCursorSpecification_RoundTrip(t *testing.T) {
+	spec := NewCursorSpecification()
+	createdAt := time.Date(2024, 1, 15, 10, 30, 0, 123456789, time.UTC)
+	content := &Content{ID: 42, Views: 1000, Likes: 50, Score: 15.5, CreatedAt: createdAt}
+
+	tests := []struct {
+		name     string
+		req      *SearchRequest
+		expected []interface{}
+	}{
+		{"Score", &SearchRequest{}, []interface{}{15.5}},
+		{"Created at", &SearchRequest{SortBy: "created_at"}, []interface{}{createdAt}},
+		{"Popularity", &SearchRequest{SortBy: "popularity", SortOrder: "asc"}, []interface{}{int64(1000), int64(50)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cursor, err := spec.CursorAfter(tt.req, content, 0)
+			require.NoError(t, err)
+			encoded, err := cursor.Encode()
+			require.NoError(t, err)
+
+			req := *tt.req
+			req.Cursor = encoded
+			require.NoError(t, spec.NormalizeCursor(&req))
+
+			require.NotNil(t, req.After)
+			assert.Equal(t, int64(42), req.After.ID)
+			for i, field := range SearchSortFields(&req) {
+				value, err := req.After.Value(i, field.Field)
+				require.NoError(t, err)
+				assert.Equal(t, tt.expected[i], value)
+			}
+		})
+	}
+
+	t.Run("Fuzzy searches keep the exact rank", func(t *testing.T) {
+		cursor, err := spec.CursorAfter(&SearchRequest{Fuzzy: FuzzyAuto}, content, 1)
+		require.NoError(t, err)
+		encoded, err := cursor.Encode()
+		require.NoError(t, err)
+
+		req := &SearchRequest{Fuzzy: FuzzyAuto, Cursor: encoded}
+		require.NoError(t, spec.NormalizeCursor(req))
+
+		assert.Equal(t, 1, req.After.Exact)
+	})
+}
+
+func TestCursorSpecification_NormalizeCursor(t *testing.T) {
+	spec := NewCursorSpecification()
+	cursor, err := spec.CursorAfter(&SearchRequest{}, &Content{ID: 1, Score: 2}, 0)
+	require.NoError(t, err)
+	encoded, err := cursor.Encode()
+	require.NoError(t, err)
+
+	t.Run("No cursor", func(t *testing.T) {
+		req := &SearchRequest{Cursor: "  ", After: &SearchCursor{}}
+
+		require.NoError(t, spec.NormalizeCursor(req))
+		assert.Empty(t, req.Cursor)
+		assert.Nil(t, req.After)
+	})
+
+	malformed, err := (&SearchCursor{Sort: "score desc", Values: []json.RawMessage{json.RawMessage(`"high"`)}}).Encode()
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		req    *SearchRequest
+		reason string
+	}{
+		{"Combined with page", &SearchRequest{Cursor: encoded, Page: 2}, "cannot be combined with page"},
+		{"Not base64", &SearchRequest{Cursor: "not a cursor!"}, "is malformed"},
+		{"Not JSON", &SearchRequest{Cursor: "bm90IGpzb24"}, "is malformed"},
+		{"Wrong value type", &SearchRequest{Cursor: malformed}, "is malformed"},
+		{"Different sort field", &SearchRequest{Cursor: encoded, SortBy: "created_at"}, "was issued for a different sort order"},
+		{"Different sort order", &SearchRequest{Cursor: encoded, SortOrder: "asc"}, "was issued for a different sort order"},
+		{"Fuzzy toggled", &SearchRequest{Cursor: encoded, Fuzzy: "1"}, "was issued for a different sort order"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := spec.NormalizeCursor(tt.req)
+
+			require.Error(t, err)
+			domainErr, ok := err.(*DomainError)
+			require.True(t, ok)
+			assert.Equal(t, "cursor", domainErr.Details["field"])
+			assert.Equal(t, tt.reason, domainErr.Details["reason"])
+		})
+	}
+}
@@ -0,0 +1,174 @@
+package domain
+
+import (
+	"hash/fnv"
+	"math/bits"
+	"strings"
+)
+
+// DefaultSimHashDistance is the largest Hamming distance between title simhashes still treated as a near-duplicate
+const DefaultSimHashDistance = 3
+
+// SimHashBands is how many 16-bit bands a simhash is split into for looking up duplicate
+// candidates. Simhashes within DefaultSimHashDistance differ in at most that many bands, so
+// with one band more they always share one.
+const SimHashBands = DefaultSimHashDistance + 1
+
+var titleStopwords = map[string]bool{
+	"a":   true,
+	"an":  true,
+	"the": true,
+}
+
+// ContentSource describes one provider's copy of a piece of content
+type ContentSource struct {
+	ID         int64  `json:"id"`
+	Provider   string `json:"provider"`
+	ProviderID string `json:"provider_id"`
+	Views      int    `json:"views"`
+	Likes      int    `json:"likes"`
+	Reactions  int    `json:"reactions"`
+	Comments   int    `json:"comments"`
+	Listens    int    `json:"listens"`
+}
+
+func NewContentSource(content *Content) ContentSource {
+	return ContentSource{
+		ID:         content.ID,
+		Provider:   content.Provider,
+		ProviderID: content.ProviderID,
+		Views:      content.Views,
+		Likes:      content.Likes,
+		Reactions:  content.Reactions,
+		Comments:   content.Comments,
+		Listens:    content.Listens,
+	}
+}
+
+// NormalizeTitle lowercases a title, strips punctuation and drops articles so that
+// cosmetic differences between providers do not defeat exact-title matching
+func NormalizeTitle(title string) string {
+	return strings.Join(titleTokens(title), " ")
+}
+
+// SimHash computes a 64-bit similarity hash over the normalized title tokens
+func SimHash(title string) uint64 {
+	tokens := titleTokens(title)
+	if len(tokens) == 0 {
+		return 0
+	}
+
+	var weights [64]int
+	for _, token := range tokens {
+		h := fnv.New64a()
+		h.Write([]byte(token))
+		sum := h.Sum64()
+		for bit := 0; bit < 64; bit++ {
+			if sum&(1<<uint(bit)) != 0 {
+				weights[bit]++
+			} else {
+				weights[bit]--
+			}
+		}
+	}
+
+	var hash uint64
+	for bit := 0; bit < 64; bit++ {
+		if weights[bit] > 0 {
+			hash |= 1 << uint(bit)
+		}
+	}
+	return hash
+}
+
+// SimHashBand returns the 16 bits of a simhash in the given band
+func SimHashBand(hash int64, band int) int {
+	return int(uint64(hash) >> (16 * uint(band)) & 0xffff)
+}
+
+// ApplyFingerprint fills in the dedup fingerprint fields derived from the title
+func ApplyFingerprint(content *Content) {
+	content.NormalizedTitle = NormalizeTitle(content.Title)
+	content.SimHash = int64(SimHash(content.Title))
+	content.SimHashBand0 = SimHashBand(content.SimHash, 0)
+	content.SimHashBand1 = SimHashBand(content.SimHash, 1)
+	content.SimHashBand2 = SimHashBand(content.SimHash, 2)
+	content.SimHashBand3 = SimHashBand(content.SimHash, 3)
+}
+
+func titleTokens(title string) []string {
+	fields := textTokens(title)
+
+	tokens := fields[:0]
+	for _, field := range fields {
+		if !titleStopwords[field] {
+			tokens = append(tokens, field)
+		}
+	}
+	return tokens
+}
+
+type DuplicateContentSpecification struct {
+	maxDistance int
+}
+
+func NewDuplicateContentSpecification() *DuplicateContentSpecification {
+	return &DuplicateContentSpecification{maxDistance: DefaultSimHashDistance}
+}
+
+// IsDuplicate reports whether two items from different providers are the same piece of content
+func (s *DuplicateContentSpecification) IsDuplicate(a, b *Content) bool {
+	if s.isSameProvider(a, b) || s.isDifferentType(a, b) {
+		return false
+	}
+	if s.hasEmptyTitle(a) || s.hasEmptyTitle(b) {
+		return false
+	}
+	if a.NormalizedTitle == b.NormalizedTitle {
+		return true
+	}
+	return s.hammingDistance(a.SimHash, b.SimHash) <= s.maxDistance
+}
+
+func (s *DuplicateContentSpecification) isSameProvider(a, b *Content) bool {
+	return a.Provider == b.Provider
+}
+
+func (s *DuplicateContentSpecification) isDifferentType(a, b *Content) bool {
+	return a.Type != b.Type
+}
+
+func (s *DuplicateContentSpecification) hasEmptyTitle(content *Content) bool {
+	return content.NormalizedTitle == ""
+}
+
+func (s *DuplicateContentSpecification) hammingDistance(a, b int64) int {
+	return bits.OnesCount64(uint64(a) ^ uint64(b))
+}
+
+// MergeContentMetrics combines the engagement of every copy in a duplicate cluster into a
+// single content value for scoring. The first element is treated as the canonical record.
+func MergeContentMetrics(cluster []*Content) *Content {
+	if len(cluster) == 0 {
+		return nil
+	}
+
+	merged := *cluster[0]
+	for _, member := range cluster[1:] {
+		merged.Views += member.Views
+		merged.Likes += member.Likes
+		merged.Reactions += member.Reactions
+		merged.Comments += member.Comments
+		merged.Listens += member.Listens
+		if member.ReadingTime > merged.ReadingTime {
+			merged.ReadingTime = member.ReadingTime
+		}
+		if member.Duration > merged.Duration {
+			merged.Duration = member.Duration
+		}
+		if member.CreatedAt.Before(merged.CreatedAt) {
+			merged.CreatedAt = member.CreatedAt
+		}
+	}
+	return &merged
+}
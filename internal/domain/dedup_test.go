@@ -0,0 +1,109 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func fingerprinted(provider, title string, contentType ContentType) *Content {
+	content := &Content{Provider: provider, Title: title, Type: contentType}
+	ApplyFingerprint(content)
+	return content
+}
+
+func TestNormalizeTitle(t *testing.T) {
+	assert.Equal(t, "intro to go concurrency", NormalizeTitle("  The Intro to Go: Concurrency! "))
+	assert.Equal(t, "go 1 22 release notes", NormalizeTitle("Go 1.22 - Release Notes"))
+	assert.Equal(t, "", NormalizeTitle("The"))
+}
+
+func TestSimHash(t *testing.T) {
+	t.Run("Cosmetic differences hash identically", func(t *testing.T) {
+		assert.Equal(t, SimHash("Intro to Go Concurrency"), SimHash("intro to go: concurrency!"))
+	})
+
+	t.Run("Empty titles hash to zero", func(t *testing.T) {
+		assert.Equal(t, uint64(0), SimHash("  "))
+	})
+}
+
+func TestSimHashBand(t *testing.T) {
+	hash := int64(0x0123456789abcdef)
+
+	assert.Equal(t, 0xcdef, SimHashBand(hash, 0))
+	assert.Equal(t, 0x0123, SimHashBand(hash, 3))
+	assert.Equal(t, 0xffff, SimHashBand(-1, 3))
+
+	t.Run("Near-duplicates share a band", func(t *testing.T) {
+		near := hash ^ (1 | 1<<20 | 1<<40)
+
+		assert.Equal(t, SimHashBand(hash, 3), SimHashBand(near, 3))
+	})
+}
+
+func TestDuplicateContentSpecification_IsDuplicate(t *testing.T) {
+	spec := NewDuplicateContentSpecification()
+
+	t.Run("Same normalized title across providers", func(t *testing.T) {
+		a := fingerprinted("provider1", "Building APIs in Go", ContentTypeVideo)
+		b := fingerprinted("provider2", "Building APIs in Go!", ContentTypeVideo)
+
+		assert.True(t, spec.IsDuplicate(a, b))
+	})
+
+	t.Run("Near-identical simhash across providers", func(t *testing.T) {
+		a := fingerprinted("provider1", "x", ContentTypeVideo)
+		b := fingerprinted("provider2", "y", ContentTypeVideo)
+		b.SimHash = a.SimHash ^ 0b101
+
+		assert.True(t, spec.IsDuplicate(a, b))
+	})
+
+	t.Run("Distant simhash is not a duplicate", func(t *testing.T) {
+		a := fingerprinted("provider1", "Building APIs in Go", ContentTypeVideo)
+		b := fingerprinted("provider2", "Cooking Pasta at Home", ContentTypeVideo)
+
+		assert.False(t, spec.IsDuplicate(a, b))
+	})
+
+	t.Run("Same provider is never a duplicate", func(t *testing.T) {
+		a := fingerprinted("provider1", "Building APIs in Go", ContentTypeVideo)
+		b := fingerprinted("provider1", "Building APIs in Go", ContentTypeVideo)
+
+		assert.False(t, spec.IsDuplicate(a, b))
+	})
+
+	t.Run("Different types are never duplicates", func(t *testing.T) {
+		a := fingerprinted("provider1", "Building APIs in Go", ContentTypeVideo)
+		b := fingerprinted("provider2", "Building APIs in Go", ContentTypeText)
+
+		assert.False(t, spec.IsDuplicate(a, b))
+	})
+
+	t.Run("Empty titles are never duplicates", func(t *testing.T) {
+		a := fingerprinted("provider1", "The", ContentTypeVideo)
+		b := fingerprinted("provider2", "A", ContentTypeVideo)
+
+		assert.False(t, spec.IsDuplicate(a, b))
+	})
+}
+
+func TestMergeContentMetrics(t *testing.T) {
+	now := time.Date(2024, 3, 15, 12, 0, 0, 0, time.UTC)
+	canonical := &Content{ID: 1, Provider: "provider1", Views: 1000, Likes: 10, Duration: 300, CreatedAt: now}
+	duplicate := &Content{ID: 2, Provider: "provider2", Views: 500, Likes: 5, Comments: 3, Duration: 310, CreatedAt: now.Add(-time.Hour)}
+
+	merged := MergeContentMetrics([]*Content{canonical, duplicate})
+
+	assert.Equal(t, int64(1), merged.ID)
+	assert.Equal(t, "provider1", merged.Provider)
+	assert.Equal(t, 1500, merged.Views)
+	assert.Equal(t, 15, merged.Likes)
+	assert.Equal(t, 3, merged.Comments)
+	assert.Equal(t, 310, merged.Duration)
+	assert.Equal(t, now.Add(-time.Hour), merged.CreatedAt)
+	assert.Equal(t, 1000, canonical.Views)
+	assert.Nil(t, MergeContentMetrics(nil))
+}
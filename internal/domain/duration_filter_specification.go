@@ -0,0 +1,29 @@
+package domain
+
+type DurationFilterSpecification struct{}
+
+func NewDurationFilterSpecification() *DurationFilterSpecification {
+	return &DurationFilterSpecification{}
+}
+
+// ValidateDurationFilter checks that the requested duration bounds, in seconds, form a valid range
+func (s *DurationFilterSpecification) ValidateDurationFilter(req *SearchRequest) error {
+	if s.isNegative(req.MinDuration) {
+		return NewInvalidInputError("min_duration", "must be zero or greater")
+	}
+	if s.isNegative(req.MaxDuration) {
+		return NewInvalidInputError("max_duration", "must be zero or greater")
+	}
+	if s.isInvertedRange(req.MinDuration, req.MaxDuration) {
+		return NewInvalidInputError("max_duration", "must be greater than or equal to min_duration")
+	}
+	return nil
+}
+
+func (s *DurationFilterSpecification) isNegative(seconds *int) bool {
+	return seconds != nil && *seconds < 0
+}
+
+func (s *DurationFilterSpecification) isInvertedRange(min, max *int) bool {
+	return min != nil && max != nil && *max < *min
+}
@@ -0,0 +1,40 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDurationFilterSpecification_ValidateDurationFilter(t *testing.T) {
+	spec := NewDurationFilterSpecification()
+	intPtr := func(v int) *int { return &v }
+
+	t.Run("Accepts open range", func(t *testing.T) {
+		req := &SearchRequest{MaxDuration: intPtr(600)}
+
+		assert.NoError(t, spec.ValidateDurationFilter(req))
+	})
+
+	t.Run("Accepts bounded range", func(t *testing.T) {
+		req := &SearchRequest{MinDuration: intPtr(60), MaxDuration: intPtr(600)}
+
+		assert.NoError(t, spec.ValidateDurationFilter(req))
+	})
+
+	t.Run("Rejects negative bound", func(t *testing.T) {
+		req := &SearchRequest{MinDuration: intPtr(-1)}
+
+		err := spec.ValidateDurationFilter(req)
+
+		assert.True(t, IsInvalidInputError(err))
+	})
+
+	t.Run("Rejects inverted range", func(t *testing.T) {
+		req := &SearchRequest{MinDuration: intPtr(600), MaxDuration: intPtr(60)}
+
+		err := spec.ValidateDurationFilter(req)
+
+		assert.True(t, IsInvalidInputError(err))
+	})
+}
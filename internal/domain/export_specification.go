@@ -0,0 +1,124 @@
+package domain
+
+import (
+	"strings"
+)
+
+const (
+	ExportFormatCSV    = "csv"
+	ExportFormatNDJSON = "ndjson"
+
+	// ExportBatchSize is how many rows an export reads from the database at a time
+	ExportBatchSize = 500
+)
+
+// ExportColumns lists the columns an export may select, in their default order
+var ExportColumns = []string{
+	"id", "provider_id", "provider", "title", "type", "language",
+	"views", "likes", "reading_time", "reactions", "duration", "comments", "listens", "image_count",
+	"score", "trending_score", "tags", "created_at", "updated_at",
+}
+
+// DefaultExportColumns are exported when a request selects none
+var DefaultExportColumns = []string{"id", "provider", "title", "type", "views", "likes", "score", "created_at"}
+
+// ExportRequest is a search whose every match is exported: the search filters and sort plus
+// the format and the comma-separated columns to write
+type ExportRequest struct {
+	SearchRequest
+	Format          string   `form:"format"`
+	Columns         string   `form:"columns"`
+	SelectedColumns []string `form:"-"`
+}
+
+// ExportValue returns the value of a column of content, typed as it is stored
+func ExportValue(content *Content, column string) interface{} {
+	switch column {
+	case "id":
+		return content.ID
+	case "provider_id":
+		return content.ProviderID
+	case "provider":
+		return content.Provider
+	case "title":
+		return content.Title
+	case "type":
+		return content.Type
+	case "language":
+		return ContentLanguage(content)
+	case "views":
+		return content.Views
+	case "likes":
+		return content.Likes
+	case "reading_time":
+		return content.ReadingTime
+	case "reactions":
+		return content.Reactions
+	case "duration":
+		return content.Duration
+	case "comments":
+		return content.Comments
+	case "listens":
+		return content.Listens
+	case "image_count":
+		return content.ImageCount
+	case "score":
+		return content.Score
+	case "trending_score":
+		return content.TrendingScore
+	case "tags":
+		return TagNames(content.Tags)
+	case "created_at":
+		return content.CreatedAt
+	case "updated_at":
+		return content.UpdatedAt
+	default:
+		return nil
+	}
+}
+
+type ExportSpecification struct{}
+
+func NewExportSpecification() *ExportSpecification {
+	return &ExportSpecification{}
+}
+
+// NormalizeExport defaults the format to CSV and resolves the selected columns, rejecting
+// unknown formats and columns. Columns are written in the order they are selected.
+func (s *ExportSpecification) NormalizeExport(req *ExportRequest) error {
+	req.Format = strings.ToLower(strings.TrimSpace(req.Format))
+	switch req.Format {
+	case "":
+		req.Format = ExportFormatCSV
+	case ExportFormatCSV, ExportFormatNDJSON:
+	default:
+		return NewInvalidInputError("format", "must be one of: csv, ndjson")
+	}
+
+	req.SelectedColumns = nil
+	seen := make(map[string]bool)
+	for _, column := range strings.Split(req.Columns, ",") {
+		column = strings.ToLower(strings.TrimSpace(column))
+		if column == "" || seen[column] {
+			continue
+		}
+		if !s.isExportColumn(column) {
+			return NewInvalidInputError("columns", "must be a comma-separated list of: "+strings.Join(ExportColumns, ", "))
+		}
+		seen[column] = true
+		req.SelectedColumns = append(req.SelectedColumns, column)
+	}
+	if len(req.SelectedColumns) == 0 {
+		req.SelectedColumns = DefaultExportColumns
+	}
+	return nil
+}
+
+func (s *ExportSpecification) isExportColumn(column string) bool {
+	for _, known := range ExportColumns {
+		if column == known {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,62 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportSpecification_NormalizeExport(t *testing.T) {
+	spec := NewExportSpecification()
+
+	t.Run("Defaults to CSV with the default columns", func(t *testing.T) {
+		req := &ExportRequest{}
+
+		require.NoError(t, spec.NormalizeExport(req))
+		assert.Equal(t, ExportFormatCSV, req.Format)
+		assert.Equal(t, DefaultExportColumns, req.SelectedColumns)
+	})
+
+	t.Run("Keeps the selected column order without duplicates", func(t *testing.T) {
+		req := &ExportRequest{Format: " NDJSON ", Columns: "title, ID,tags,,title"}
+
+		require.NoError(t, spec.NormalizeExport(req))
+		assert.Equal(t, ExportFormatNDJSON, req.Format)
+		assert.Equal(t, []string{"title", "id", "tags"}, req.SelectedColumns)
+	})
+
+	tests := []struct {
+		name  string
+		req   *ExportRequest
+		field string
+	}{
+		{"Unknown format", &ExportRequest{Format: "xlsx"}, "format"},
+		{"Unknown column", &ExportRequest{Columns: "id,secret"}, "columns"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := spec.NormalizeExport(tt.req)
+
+			require.Error(t, err)
+			domainErr, ok := err.(*DomainError)
+			require.True(t, ok)
+			assert.Equal(t, ErrorCodeInvalidInput, domainErr.Code)
+			assert.Equal(t, tt.field, domainErr.Details["field"])
+		})
+	}
+}
+
+func TestExportValue(t *testing.T) {
+	created := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	content := &Content{ID: 7, Title: "Go Basics", Type: ContentTypeVideo, Score: 4.5, CreatedAt: created, Tags: NewTags("go", "basics")}
+
+	assert.Equal(t, int64(7), ExportValue(content, "id"))
+	assert.Equal(t, ContentTypeVideo, ExportValue(content, "type"))
+	assert.Equal(t, 4.5, ExportValue(content, "score"))
+	assert.Equal(t, TagNames(content.Tags), ExportValue(content, "tags"))
+	assert.Equal(t, LanguageEnglish, ExportValue(content, "language"))
+	assert.Equal(t, created, ExportValue(content, "created_at"))
+	assert.Nil(t, ExportValue(content, "unknown"))
+}
@@ -0,0 +1,86 @@
+package domain
+
+import (
+	"strings"
+)
+
+const (
+	FacetType      = "type"
+	FacetProvider  = "provider"
+	FacetTag       = "tag"
+	FacetPublished = "published"
+	FacetScore     = "score"
+)
+
+// SupportedFacets lists every facet that can be requested, in display order
+var SupportedFacets = []string{FacetType, FacetProvider, FacetTag, FacetPublished, FacetScore}
+
+// MaxTagFacetBuckets caps the tag facet to the most frequent tags
+const MaxTagFacetBuckets = 20
+
+// ScoreRange is a half-open [From, To) score bucket; a nil To leaves the range unbounded
+type ScoreRange struct {
+	Key  string
+	From float64
+	To   *float64
+}
+
+func scoreBound(value float64) *float64 {
+	return &value
+}
+
+// ScoreFacetRanges are the buckets reported by the score facet
+var ScoreFacetRanges = []ScoreRange{
+	{Key: "0-5", From: 0, To: scoreBound(5)},
+	{Key: "5-10", From: 5, To: scoreBound(10)},
+	{Key: "10-20", From: 10, To: scoreBound(20)},
+	{Key: "20-50", From: 20, To: scoreBound(50)},
+	{Key: "50+", From: 50},
+}
+
+type FacetBucket struct {
+	Key   string   `json:"key"`
+	Count int      `json:"count"`
+	From  *float64 `json:"from,omitempty"`
+	To    *float64 `json:"to,omitempty"`
+}
+
+// Aggregations maps each requested facet onto its buckets
+type Aggregations map[string][]FacetBucket
+
+type FacetSpecification struct{}
+
+func NewFacetSpecification() *FacetSpecification {
+	return &FacetSpecification{}
+}
+
+// NormalizeFacets splits comma-separated facet names, lowercases and dedupes them,
+// and rejects facets that cannot be aggregated
+func (s *FacetSpecification) NormalizeFacets(req *SearchRequest) error {
+	seen := make(map[string]bool)
+	var facets []string
+	for _, value := range req.Facets {
+		for _, facet := range strings.Split(value, ",") {
+			facet = strings.ToLower(strings.TrimSpace(facet))
+			if facet == "" || seen[facet] {
+				continue
+			}
+			if !s.isSupportedFacet(facet) {
+				return NewInvalidInputError("facets", "must be a comma-separated list of: "+strings.Join(SupportedFacets, ", "))
+			}
+			seen[facet] = true
+			facets = append(facets, facet)
+		}
+	}
+	req.Facets = facets
+	return nil
+}
+
+func (s *FacetSpecification) isSupportedFacet(facet string) bool {
+	for _, supported := range SupportedFacets {
+		if facet == supported {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,38 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFacetSpecification_NormalizeFacets(t *testing.T) {
+	spec := NewFacetSpecification()
+
+	t.Run("Splits, lowercases and dedupes facets", func(t *testing.T) {
+		req := &SearchRequest{Facets: []string{"Type, provider", "type", "tag,"}}
+
+		err := spec.NormalizeFacets(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{FacetType, FacetProvider, FacetTag}, req.Facets)
+	})
+
+	t.Run("No facets requested", func(t *testing.T) {
+		req := &SearchRequest{}
+
+		assert.NoError(t, spec.NormalizeFacets(req))
+		assert.Empty(t, req.Facets)
+	})
+
+	t.Run("Rejects unknown facets", func(t *testing.T) {
+		req := &SearchRequest{Facets: []string{"type,color"}}
+
+		err := spec.NormalizeFacets(req)
+
+		assert.Error(t, err)
+		domainErr, ok := err.(*DomainError)
+		assert.True(t, ok)
+		assert.Equal(t, ErrorCodeInvalidInput, domainErr.Code)
+	})
+}
@@ -0,0 +1,155 @@
+package domain
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	FuzzyAuto = "auto"
+
+	// MaxFuzzyEdits is the largest edit distance a fuzzy term may be matched with
+	MaxFuzzyEdits = 2
+)
+
+type FuzzySpecification struct{}
+
+func NewFuzzySpecification() *FuzzySpecification {
+	return &FuzzySpecification{}
+}
+
+// NormalizeFuzzy lowercases the fuzzy setting and rejects anything but auto or an edit distance of 0-2.
+// An empty setting disables fuzzy matching.
+func (s *FuzzySpecification) NormalizeFuzzy(req *SearchRequest) error {
+	req.Fuzzy = strings.ToLower(strings.TrimSpace(req.Fuzzy))
+	if req.Fuzzy == "" || req.Fuzzy == FuzzyAuto {
+		return nil
+	}
+	edits, err := strconv.Atoi(req.Fuzzy)
+	if err != nil || edits < 0 || edits > MaxFuzzyEdits {
+		return NewInvalidInputError("fuzzy", "must be one of: auto, 0, 1, 2")
+	}
+	return nil
+}
+
+// MaxEdits returns how many edits a term may be matched with. As in Elasticsearch, auto allows
+// none for terms of up to 2 characters, 1 for 3-5 characters and 2 for longer terms. An explicit
+// distance is capped below the term length so that short terms do not match every word.
+func (s *FuzzySpecification) MaxEdits(setting, term string) int {
+	length := utf8.RuneCountInString(term)
+
+	var edits int
+	switch setting {
+	case "":
+		return 0
+	case FuzzyAuto:
+		switch {
+		case length <= 2:
+			edits = 0
+		case length <= 5:
+			edits = 1
+		default:
+			edits = 2
+		}
+	default:
+		edits, _ = strconv.Atoi(setting)
+	}
+
+	if edits >= length {
+		edits = length - 1
+	}
+	if edits < 0 {
+		edits = 0
+	}
+	return edits
+}
+
+// SimilarityThreshold is the lowest trigram word similarity a word within edits of term can have.
+// Each edit changes at most 3 of the term's padded trigrams.
+func (s *FuzzySpecification) SimilarityThreshold(term string, edits int) float64 {
+	trigrams := float64(len(Trigrams(term)))
+	if trigrams == 0 {
+		return 0
+	}
+	threshold := (trigrams - 3*float64(edits)) / trigrams
+	if threshold < 0 {
+		return 0
+	}
+	return threshold
+}
+
+// IsMatch reports whether word is within edits of term. Words sharing too few trigrams with
+// the term are rejected before the edit distance is computed.
+func (s *FuzzySpecification) IsMatch(term, word string, edits int) bool {
+	term, word = strings.ToLower(term), strings.ToLower(word)
+	if term == word {
+		return true
+	}
+	if abs(utf8.RuneCountInString(term)-utf8.RuneCountInString(word)) > edits {
+		return false
+	}
+	if WordSimilarity(term, word) < s.SimilarityThreshold(term, edits) {
+		return false
+	}
+	return Levenshtein(term, word) <= edits
+}
+
+// Levenshtein returns the number of single-character insertions, deletions and substitutions
+// needed to turn a into b
+func Levenshtein(a, b string) int {
+	source, target := []rune(a), []rune(b)
+	previous := make([]int, len(target)+1)
+	current := make([]int, len(target)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(source); i++ {
+		current[0] = i
+		for j := 1; j <= len(target); j++ {
+			cost := 1
+			if source[i-1] == target[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(target)]
+}
+
+// Trigrams returns the set of trigrams of a lowercased word, padded like pg_trgm with two
+// leading spaces and one trailing space
+func Trigrams(word string) map[string]struct{} {
+	padded := []rune("  " + strings.ToLower(word) + " ")
+	trigrams := make(map[string]struct{}, len(padded))
+	for i := 0; i+3 <= len(padded); i++ {
+		trigrams[string(padded[i:i+3])] = struct{}{}
+	}
+	return trigrams
+}
+
+// WordSimilarity is the share of term's trigrams that also occur in word
+func WordSimilarity(term, word string) float64 {
+	termTrigrams := Trigrams(term)
+	if len(termTrigrams) == 0 {
+		return 0
+	}
+	wordTrigrams := Trigrams(word)
+
+	shared := 0
+	for trigram := range termTrigrams {
+		if _, ok := wordTrigrams[trigram]; ok {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(termTrigrams))
+}
+
+func abs(value int) int {
+	if value < 0 {
+		return -value
+	}
+	return value
+}
@@ -0,0 +1,103 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFuzzySpecification_NormalizeFuzzy(t *testing.T) {
+	spec := NewFuzzySpecification()
+
+	for _, value := range []string{"", "auto", " AUTO ", "0", "1", "2"} {
+		t.Run("Accepts "+value, func(t *testing.T) {
+			req := &SearchRequest{Fuzzy: value}
+
+			assert.NoError(t, spec.NormalizeFuzzy(req))
+		})
+	}
+
+	t.Run("Lowercases the setting", func(t *testing.T) {
+		req := &SearchRequest{Fuzzy: " Auto"}
+
+		require.NoError(t, spec.NormalizeFuzzy(req))
+		assert.Equal(t, FuzzyAuto, req.Fuzzy)
+	})
+
+	for _, value := range []string{"3", "-1", "yes"} {
+		t.Run("Rejects "+value, func(t *testing.T) {
+			err := spec.NormalizeFuzzy(&SearchRequest{Fuzzy: value})
+
+			require.Error(t, err)
+			domainErr, ok := err.(*DomainError)
+			require.True(t, ok)
+			assert.Equal(t, ErrorCodeInvalidInput, domainErr.Code)
+			assert.Equal(t, "fuzzy", domainErr.Details["field"])
+		})
+	}
+}
+
+func TestFuzzySpecification_MaxEdits(t *testing.T) {
+	spec := NewFuzzySpecification()
+
+	tests := []struct {
+		name     string
+		setting  string
+		term     string
+		expected int
+	}{
+		{"Disabled", "", "kubernetes", 0},
+		{"Auto short term", "auto", "go", 0},
+		{"Auto medium term", "auto", "rust", 1},
+		{"Auto long term", "auto", "kubernets", 2},
+		{"Explicit distance", "1", "kubernets", 1},
+		{"Explicit zero", "0", "kubernets", 0},
+		{"Capped below term length", "2", "go", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, spec.MaxEdits(tt.setting, tt.term))
+		})
+	}
+}
+
+func TestFuzzySpecification_IsMatch(t *testing.T) {
+	spec := NewFuzzySpecification()
+
+	tests := []struct {
+		name     string
+		term     string
+		word     string
+		edits    int
+		expected bool
+	}{
+		{"Missing letter", "kubernets", "kubernetes", 1, true},
+		{"Case is ignored", "Kubernets", "kubernetes", 1, true},
+		{"Transposition needs two edits", "golnag", "golang", 2, true},
+		{"Transposition exceeds one edit", "golnag", "golang", 1, false},
+		{"Unrelated word", "kubernets", "kotlin", 2, false},
+		{"Too long", "rust", "rusting", 2, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, spec.IsMatch(tt.term, tt.word, tt.edits))
+		})
+	}
+}
+
+func TestLevenshtein(t *testing.T) {
+	assert.Equal(t, 0, Levenshtein("golang", "golang"))
+	assert.Equal(t, 1, Levenshtein("kubernets", "kubernetes"))
+	assert.Equal(t, 2, Levenshtein("golnag", "golang"))
+	assert.Equal(t, 3, Levenshtein("", "abc"))
+	assert.Equal(t, 1, Levenshtein("café", "cafe"))
+}
+
+func TestWordSimilarity(t *testing.T) {
+	assert.Equal(t, 1.0, WordSimilarity("go", "GO"))
+	assert.InDelta(t, 0.8, WordSimilarity("kubernets", "kubernetes"), 0.001)
+	assert.Equal(t, 0.0, WordSimilarity("abc", "xyz"))
+}
@@ -0,0 +1,239 @@
+package domain
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	DefaultHighlightPreTag  = "<em>"
+	DefaultHighlightPostTag = "</em>"
+	MaxHighlightTagLength   = 32
+)
+
+// Highlights shows why an item matched: the title with every matched term wrapped in the
+// highlight tags, and the distinct matched terms in title order
+type Highlights struct {
+	Title        string   `json:"title"`
+	MatchedTerms []string `json:"matched_terms"`
+}
+
+type HighlightSpecification struct {
+	fuzzy *FuzzySpecification
+}
+
+func NewHighlightSpecification() *HighlightSpecification {
+	return &HighlightSpecification{fuzzy: NewFuzzySpecification()}
+}
+
+// ValidateHighlight rejects highlight tags that are too long or contain double quotes, which
+// cannot be passed through to ts_headline
+func (s *HighlightSpecification) ValidateHighlight(req *SearchRequest) error {
+	for _, tag := range []struct {
+		field string
+		value string
+	}{{"highlight_pre_tag", req.HighlightPreTag}, {"highlight_post_tag", req.HighlightPostTag}} {
+		if utf8.RuneCountInString(tag.value) > MaxHighlightTagLength {
+			return NewInvalidInputError(tag.field, "must be at most 32 characters")
+		}
+		if strings.Contains(tag.value, `"`) {
+			return NewInvalidInputError(tag.field, "must not contain double quotes")
+		}
+	}
+	return nil
+}
+
+// Tags returns the request's highlight tags, falling back to <em> and </em>
+func (s *HighlightSpecification) Tags(req *SearchRequest) (string, string) {
+	preTag, postTag := req.HighlightPreTag, req.HighlightPostTag
+	if preTag == "" {
+		preTag = DefaultHighlightPreTag
+	}
+	if postTag == "" {
+		postTag = DefaultHighlightPostTag
+	}
+	return preTag, postTag
+}
+
+// HighlightTerms collects the title terms of the given queries, skipping negated terms since
+// they never explain a match
+func HighlightTerms(nodes ...QueryNode) []*TermNode {
+	var terms []*TermNode
+	var collect func(node QueryNode)
+	collect = func(node QueryNode) {
+		switch n := node.(type) {
+		case *TermNode:
+			if n.Field == QueryFieldTitle {
+				terms = append(terms, n)
+			}
+		case *AndNode:
+			for _, child := range n.Children {
+				collect(child)
+			}
+		case *OrNode:
+			for _, child := range n.Children {
+				collect(child)
+			}
+		case *MinimumMatchNode:
+			for _, child := range n.Children {
+				collect(child)
+			}
+		}
+	}
+	for _, node := range nodes {
+		if node != nil {
+			collect(node)
+		}
+	}
+	return terms
+}
+
+// Highlight marks the terms in title the way the SQLite search matches them: a term matches any
+// word containing it, and with fuzzy matching also any word within the allowed edit distance.
+// Highlights always cover whole words. It returns nil when no term occurs in the title.
+func (s *HighlightSpecification) Highlight(title string, terms []*TermNode, fuzzy, preTag, postTag string) *Highlights {
+	runes := []rune(title)
+	lower := make([]rune, len(runes))
+	for i, r := range runes {
+		lower[i] = unicode.ToLower(r)
+	}
+	words := s.wordSpans(runes)
+
+	var spans []highlightSpan
+	for _, term := range terms {
+		needle := []rune(strings.Map(unicode.ToLower, term.Value))
+		for _, start := range s.occurrences(lower, needle) {
+			spans = append(spans, s.expandToWords(highlightSpan{start, start + len(needle)}, words))
+		}
+
+		if term.Phrase {
+			continue
+		}
+		if edits := s.fuzzy.MaxEdits(fuzzy, term.Value); edits > 0 {
+			for _, word := range words {
+				if s.fuzzy.IsMatch(term.Value, string(lower[word.start:word.end]), edits) {
+					spans = append(spans, word)
+				}
+			}
+		}
+	}
+	if len(spans) == 0 {
+		return nil
+	}
+
+	spans = s.mergeSpans(spans)
+	var builder strings.Builder
+	highlights := &Highlights{}
+	seen := make(map[string]bool)
+	position := 0
+	for _, span := range spans {
+		builder.WriteString(string(runes[position:span.start]))
+		builder.WriteString(preTag)
+		builder.WriteString(string(runes[span.start:span.end]))
+		builder.WriteString(postTag)
+		position = span.end
+
+		matched := string(lower[span.start:span.end])
+		if !seen[matched] {
+			seen[matched] = true
+			highlights.MatchedTerms = append(highlights.MatchedTerms, matched)
+		}
+	}
+	builder.WriteString(string(runes[position:]))
+	highlights.Title = builder.String()
+	return highlights
+}
+
+// MatchedTerms extracts the distinct lowercased fragments wrapped in the highlight tags
+func (s *HighlightSpecification) MatchedTerms(highlighted, preTag, postTag string) []string {
+	var terms []string
+	seen := make(map[string]bool)
+	for {
+		start := strings.Index(highlighted, preTag)
+		if start < 0 {
+			break
+		}
+		highlighted = highlighted[start+len(preTag):]
+		end := strings.Index(highlighted, postTag)
+		if end < 0 {
+			break
+		}
+		term := strings.ToLower(highlighted[:end])
+		highlighted = highlighted[end+len(postTag):]
+		if term != "" && !seen[term] {
+			seen[term] = true
+			terms = append(terms, term)
+		}
+	}
+	return terms
+}
+
+// highlightSpan is a half-open range of rune offsets into a title
+type highlightSpan struct {
+	start int
+	end   int
+}
+
+func (s *HighlightSpecification) wordSpans(runes []rune) []highlightSpan {
+	var words []highlightSpan
+	start := -1
+	for i, r := range runes {
+		isWord := unicode.IsLetter(r) || unicode.IsNumber(r)
+		if isWord && start < 0 {
+			start = i
+		} else if !isWord && start >= 0 {
+			words = append(words, highlightSpan{start, i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		words = append(words, highlightSpan{start, len(runes)})
+	}
+	return words
+}
+
+func (s *HighlightSpecification) occurrences(haystack, needle []rune) []int {
+	if len(needle) == 0 {
+		return nil
+	}
+	var positions []int
+	for i := 0; i+len(needle) <= len(haystack); i++ {
+		if string(haystack[i:i+len(needle)]) == string(needle) {
+			positions = append(positions, i)
+		}
+	}
+	return positions
+}
+
+// expandToWords widens a span so that it starts and ends on word boundaries
+func (s *HighlightSpecification) expandToWords(span highlightSpan, words []highlightSpan) highlightSpan {
+	for _, word := range words {
+		if word.start <= span.start && span.start < word.end {
+			span.start = word.start
+		}
+		if word.start < span.end && span.end <= word.end {
+			span.end = word.end
+		}
+	}
+	return span
+}
+
+func (s *HighlightSpecification) mergeSpans(spans []highlightSpan) []highlightSpan {
+	sort.Slice(spans, func(i, j int) bool {
+		return spans[i].start < spans[j].start
+	})
+	merged := []highlightSpan{spans[0]}
+	for _, span := range spans[1:] {
+		last := &merged[len(merged)-1]
+		if span.start <= last.end {
+			if span.end > last.end {
+				last.end = span.end
+			}
+			continue
+		}
+		merged = append(merged, span)
+	}
+	return merged
+}
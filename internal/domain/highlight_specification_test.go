@@ -0,0 +1,148 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHighlightSpecification_ValidateHighlight(t *testing.T) {
+	spec := NewHighlightSpecification()
+
+	assert.NoError(t, spec.ValidateHighlight(&SearchRequest{}))
+	assert.NoError(t, spec.ValidateHighlight(&SearchRequest{HighlightPreTag: "<b class='hit'>", HighlightPostTag: "</b>"}))
+
+	tests := []struct {
+		name  string
+		req   *SearchRequest
+		field string
+	}{
+		{"Pre tag too long", &SearchRequest{HighlightPreTag: strings.Repeat("x", MaxHighlightTagLength+1)}, "highlight_pre_tag"},
+		{"Post tag with double quote", &SearchRequest{HighlightPostTag: `</b">`}, "highlight_post_tag"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := spec.ValidateHighlight(tt.req)
+
+			require.Error(t, err)
+			domainErr, ok := err.(*DomainError)
+			require.True(t, ok)
+			assert.Equal(t, tt.field, domainErr.Details["field"])
+		})
+	}
+}
+
+func TestHighlightSpecification_Tags(t *testing.T) {
+	spec := NewHighlightSpecification()
+
+	preTag, postTag := spec.Tags(&SearchRequest{})
+	assert.Equal(t, "<em>", preTag)
+	assert.Equal(t, "</em>", postTag)
+
+	preTag, postTag = spec.Tags(&SearchRequest{HighlightPreTag: "[", HighlightPostTag: "]"})
+	assert.Equal(t, "[", preTag)
+	assert.Equal(t, "]", postTag)
+}
+
+func TestHighlightTerms(t *testing.T) {
+	node, err := ParseQuery(`(go OR "rust ownership") -beginner type:video`)
+	require.NoError(t, err)
+	filter := &AndNode{Children: []QueryNode{&TermNode{Field: QueryFieldTitle, Value: "async"}, &TermNode{Field: QueryFieldTag, Value: "go"}}}
+
+	terms := HighlightTerms(node, nil, filter)
+
+	values := make([]string, 0, len(terms))
+	for _, term := range terms {
+		values = append(values, term.Value)
+	}
+	assert.Equal(t, []string{"go", "rust ownership", "async"}, values)
+}
+
+func TestHighlightSpecification_Highlight(t *testing.T) {
+	spec := NewHighlightSpecification()
+
+	tests := []struct {
+		name     string
+		title    string
+		terms    []*TermNode
+		fuzzy    string
+		expected string
+		matched  []string
+	}{
+		{
+			"Whole words are marked",
+			"Go Concurrency Patterns",
+			[]*TermNode{{Field: QueryFieldTitle, Value: "concurrency"}},
+			"",
+			"Go [Concurrency] Patterns",
+			[]string{"concurrency"},
+		},
+		{
+			"Partial matches expand to the word",
+			"Learning Golang in 2024",
+			[]*TermNode{{Field: QueryFieldTitle, Value: "go"}},
+			"",
+			"Learning [Golang] in 2024",
+			[]string{"golang"},
+		},
+		{
+			"Every occurrence is marked",
+			"Go, go, GO!",
+			[]*TermNode{{Field: QueryFieldTitle, Value: "go"}},
+			"",
+			"[Go], [go], [GO]!",
+			[]string{"go"},
+		},
+		{
+			"Phrases are marked as one span",
+			"Advanced Go Concurrency",
+			[]*TermNode{{Field: QueryFieldTitle, Value: "go concurrency", Phrase: true}},
+			"",
+			"Advanced [Go Concurrency]",
+			[]string{"go concurrency"},
+		},
+		{
+			"Fuzzy matches are marked",
+			"Kubernetes in Production",
+			[]*TermNode{{Field: QueryFieldTitle, Value: "kubernets"}},
+			FuzzyAuto,
+			"[Kubernetes] in Production",
+			[]string{"kubernetes"},
+		},
+		{
+			"Several terms",
+			"Rust Ownership and Borrowing",
+			[]*TermNode{{Field: QueryFieldTitle, Value: "borrowing"}, {Field: QueryFieldTitle, Value: "rust"}},
+			"",
+			"[Rust] Ownership and [Borrowing]",
+			[]string{"rust", "borrowing"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			highlights := spec.Highlight(tt.title, tt.terms, tt.fuzzy, "[", "]")
+
+			require.NotNil(t, highlights)
+			assert.Equal(t, tt.expected, highlights.Title)
+			assert.Equal(t, tt.matched, highlights.MatchedTerms)
+		})
+	}
+
+	t.Run("No match yields nil", func(t *testing.T) {
+		highlights := spec.Highlight("Rust Ownership", []*TermNode{{Field: QueryFieldTitle, Value: "kubernetes"}}, "", "[", "]")
+
+		assert.Nil(t, highlights)
+	})
+}
+
+func TestHighlightSpecification_MatchedTerms(t *testing.T) {
+	spec := NewHighlightSpecification()
+
+	terms := spec.MatchedTerms("<b>Running</b> and <b>running</b> <b>Fast</b>", "<b>", "</b>")
+
+	assert.Equal(t, []string{"running", "fast"}, terms)
+}
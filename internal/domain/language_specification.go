@@ -0,0 +1,135 @@
+package domain
+
+import (
+	"strings"
+	"unicode"
+)
+
+const (
+	LanguageEnglish = "en"
+	LanguageGerman  = "de"
+	LanguageTurkish = "tr"
+
+	// DefaultLanguage is assumed for content whose language is neither given nor detected
+	DefaultLanguage = LanguageEnglish
+)
+
+// Languages lists the supported content languages as ISO 639-1 codes
+var Languages = []string{LanguageEnglish, LanguageGerman, LanguageTurkish}
+
+// TextSearchConfigs maps each language to the PostgreSQL text search configuration that
+// stems its titles and queries
+var TextSearchConfigs = map[string]string{
+	LanguageEnglish: "english",
+	LanguageGerman:  "german",
+	LanguageTurkish: "turkish",
+}
+
+// languageHints are frequent words used to recognize the language of a title
+var languageHints = map[string]map[string]bool{
+	LanguageEnglish: wordSet("the", "and", "of", "to", "in", "for", "with", "on", "is", "how", "what", "your", "guide", "introduction"),
+	LanguageGerman:  wordSet("der", "die", "das", "und", "mit", "für", "von", "zu", "im", "ist", "ein", "eine", "einführung", "auf", "nicht"),
+	LanguageTurkish: wordSet("ve", "ile", "bir", "bu", "için", "nasıl", "ne", "çok", "daha", "giriş", "rehberi", "nedir"),
+}
+
+// languageLetters are letters found in one supported language only
+var languageLetters = map[rune]string{
+	'ß': LanguageGerman,
+	'ä': LanguageGerman,
+	'ı': LanguageTurkish,
+	'ş': LanguageTurkish,
+	'ğ': LanguageTurkish,
+}
+
+func wordSet(words ...string) map[string]bool {
+	set := make(map[string]bool, len(words))
+	for _, word := range words {
+		set[word] = true
+	}
+	return set
+}
+
+// ParseLanguage resolves a language code or tag such as "de" or "de-DE", reporting false for
+// unsupported languages
+func ParseLanguage(code string) (string, bool) {
+	code = strings.ToLower(strings.TrimSpace(code))
+	if i := strings.IndexAny(code, "-_"); i >= 0 {
+		code = code[:i]
+	}
+	if _, ok := TextSearchConfigs[code]; ok {
+		return code, true
+	}
+	return "", false
+}
+
+// DetectLanguage guesses the language of a title from its frequent words and the letters
+// only one language uses, falling back to DefaultLanguage when nothing points to a language
+func DetectLanguage(text string) string {
+	scores := make(map[string]int, len(Languages))
+	for _, r := range strings.ToLower(text) {
+		if language, ok := languageLetters[r]; ok {
+			scores[language] += 2
+		}
+	}
+	for _, token := range textTokens(text) {
+		for language, hints := range languageHints {
+			if hints[token] {
+				scores[language]++
+			}
+		}
+	}
+
+	detected, best := DefaultLanguage, 0
+	for _, language := range Languages {
+		if scores[language] > best {
+			detected, best = language, scores[language]
+		}
+	}
+	return detected
+}
+
+// ApplyLanguage resolves the language a provider gave for content, detecting it from the
+// title when it is missing or unsupported
+func ApplyLanguage(content *Content) {
+	if language, ok := ParseLanguage(content.Language); ok {
+		content.Language = language
+		return
+	}
+	content.Language = DetectLanguage(content.Title)
+}
+
+// ContentLanguage returns the language of content, which is DefaultLanguage for content
+// stored before languages were tracked
+func ContentLanguage(content *Content) string {
+	if content.Language == "" {
+		return DefaultLanguage
+	}
+	return content.Language
+}
+
+type LanguageSpecification struct{}
+
+func NewLanguageSpecification() *LanguageSpecification {
+	return &LanguageSpecification{}
+}
+
+// NormalizeLanguage resolves the language a search is restricted to, if any
+func (s *LanguageSpecification) NormalizeLanguage(req *SearchRequest) error {
+	if strings.TrimSpace(req.Language) == "" {
+		req.Language = ""
+		return nil
+	}
+	language, ok := ParseLanguage(req.Language)
+	if !ok {
+		return NewInvalidInputError("language", "must be one of: "+strings.Join(Languages, ", "))
+	}
+	req.Language = language
+	return nil
+}
+
+// textTokens splits text into lowercase words and numbers
+func textTokens(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
@@ -0,0 +1,77 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLanguage(t *testing.T) {
+	tests := []struct {
+		code     string
+		expected string
+		ok       bool
+	}{
+		{"de", LanguageGerman, true},
+		{" DE-de ", LanguageGerman, true},
+		{"tr_TR", LanguageTurkish, true},
+		{"en", LanguageEnglish, true},
+		{"ja", "", false},
+		{"", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			language, ok := ParseLanguage(tt.code)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.expected, language)
+		})
+	}
+}
+
+func TestDetectLanguage(t *testing.T) {
+	tests := map[string]string{
+		"Einführung in die Programmierung":  LanguageGerman,
+		"Docker für Anfänger":               LanguageGerman,
+		"Go ile Yazılım Geliştirme Rehberi": LanguageTurkish,
+		"Kubernetes nedir?":                 LanguageTurkish,
+		"The Go Programming Language":       LanguageEnglish,
+		"Kubernetes":                        DefaultLanguage,
+	}
+
+	for title, expected := range tests {
+		t.Run(title, func(t *testing.T) {
+			assert.Equal(t, expected, DetectLanguage(title))
+		})
+	}
+}
+
+func TestApplyLanguage(t *testing.T) {
+	content := &Content{Title: "Docker für Anfänger", Language: "TR"}
+	ApplyLanguage(content)
+	assert.Equal(t, LanguageTurkish, content.Language)
+
+	content = &Content{Title: "Docker für Anfänger", Language: "ja"}
+	ApplyLanguage(content)
+	assert.Equal(t, LanguageGerman, content.Language)
+
+	assert.Equal(t, DefaultLanguage, ContentLanguage(&Content{}))
+}
+
+func TestLanguageSpecification_NormalizeLanguage(t *testing.T) {
+	spec := NewLanguageSpecification()
+
+	req := &SearchRequest{Language: "de-AT"}
+	require.NoError(t, spec.NormalizeLanguage(req))
+	assert.Equal(t, LanguageGerman, req.Language)
+
+	req = &SearchRequest{Language: "  "}
+	require.NoError(t, spec.NormalizeLanguage(req))
+	assert.Empty(t, req.Language)
+
+	err := spec.NormalizeLanguage(&SearchRequest{Language: "ja"})
+	var domainErr *DomainError
+	require.ErrorAs(t, err, &domainErr)
+	assert.Equal(t, "language", domainErr.Details["field"])
+}
@@ -0,0 +1,143 @@
+package domain
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// stemGerman reduces a lowercase German word to its stem with the Snowball German algorithm,
+// folding umlauts so that "Häuser" and "Haus" share a stem
+func stemGerman(word string) string {
+	w := []rune(strings.ReplaceAll(word, "ß", "ss"))
+
+	// u and y between vowels act as consonants
+	for i := 1; i < len(w)-1; i++ {
+		if (w[i] == 'u' || w[i] == 'y') && isGermanVowel(w[i-1]) && isGermanVowel(w[i+1]) {
+			w[i] -= 'a' - 'A'
+		}
+	}
+
+	r1 := germanRegion(w, 0)
+	if r1 < 3 {
+		r1 = 3
+	}
+	r2 := germanRegion(w, r1)
+
+	// Step 1: inflectional endings
+	switch suffix := longestSuffix(w, "ern", "em", "er", "en", "es", "e", "s"); {
+	case suffix == "":
+	case suffix == "s" && !precededBy(w, suffix, "bdfghklmnrt"):
+	case len(w)-len(suffix) >= r1:
+		w = w[:len(w)-len(suffix)]
+		if (suffix == "e" || suffix == "en" || suffix == "es") && runesHaveSuffix(w, "niss") {
+			w = w[:len(w)-1]
+		}
+	}
+
+	// Step 2: comparative and superlative endings
+	switch suffix := longestSuffix(w, "est", "en", "er", "st"); {
+	case suffix == "":
+	case suffix == "st" && (len(w) < 6 || !precededBy(w, suffix, "bdfghklmnt")):
+	case len(w)-len(suffix) >= r1:
+		w = w[:len(w)-len(suffix)]
+	}
+
+	// Step 3: derivational endings
+	switch suffix := longestSuffix(w, "isch", "lich", "heit", "keit", "end", "ung", "ig", "ik"); suffix {
+	case "end", "ung":
+		if len(w)-len(suffix) >= r2 {
+			w = w[:len(w)-len(suffix)]
+			if runesHaveSuffix(w, "ig") && len(w)-2 >= r2 && !runesHaveSuffix(w, "eig") {
+				w = w[:len(w)-2]
+			}
+		}
+	case "ig", "ik", "isch":
+		if len(w)-len(suffix) >= r2 && !precededBy(w, suffix, "e") {
+			w = w[:len(w)-len(suffix)]
+		}
+	case "lich", "heit":
+		if len(w)-len(suffix) >= r2 {
+			w = w[:len(w)-len(suffix)]
+			if (runesHaveSuffix(w, "er") || runesHaveSuffix(w, "en")) && len(w)-2 >= r1 {
+				w = w[:len(w)-2]
+			}
+		}
+	case "keit":
+		if len(w)-len(suffix) >= r2 {
+			w = w[:len(w)-len(suffix)]
+			if runesHaveSuffix(w, "lich") && len(w)-4 >= r2 {
+				w = w[:len(w)-4]
+			} else if runesHaveSuffix(w, "ig") && len(w)-2 >= r2 {
+				w = w[:len(w)-2]
+			}
+		}
+	}
+
+	return strings.NewReplacer("U", "u", "Y", "y", "ä", "a", "ö", "o", "ü", "u").Replace(string(w))
+}
+
+func isGermanVowel(r rune) bool {
+	return strings.ContainsRune("aeiouyäöü", r)
+}
+
+// germanRegion returns the start of the region after the first non-vowel following a vowel
+// at or after start, or the length of the word when there is none
+func germanRegion(w []rune, start int) int {
+	for i := start + 1; i < len(w); i++ {
+		if isGermanVowel(w[i-1]) && !isGermanVowel(w[i]) {
+			return i + 1
+		}
+	}
+	return len(w)
+}
+
+// turkishCaseSuffixes are the ablative, genitive, locative, dative and accusative endings,
+// longest first; turkishPluralSuffixes the plural endings, with a possessive or accusative
+var (
+	turkishCaseSuffixes = []string{
+		"ından", "inden", "undan", "ünden", "ndan", "nden",
+		"nın", "nin", "nun", "nün", "dan", "den", "tan", "ten",
+		"da", "de", "ta", "te", "ya", "ye", "ın", "in", "un", "ün",
+	}
+	turkishPluralSuffixes = []string{"ları", "leri", "lar", "ler"}
+)
+
+// minTurkishStem is the shortest stem the Turkish stemmer leaves
+const minTurkishStem = 2
+
+// stemTurkish strips one case ending and then one plural ending from a lowercase Turkish word,
+// so that "kitaplardan" and "kitap" share a stem. It is a light stemmer: derivational suffixes
+// and consonant mutations are left alone.
+func stemTurkish(word string) string {
+	word = stripTurkishSuffix(word, turkishCaseSuffixes)
+	return stripTurkishSuffix(word, turkishPluralSuffixes)
+}
+
+func stripTurkishSuffix(word string, suffixes []string) string {
+	for _, suffix := range suffixes {
+		if strings.HasSuffix(word, suffix) && utf8.RuneCountInString(word)-utf8.RuneCountInString(suffix) >= minTurkishStem {
+			return strings.TrimSuffix(word, suffix)
+		}
+	}
+	return word
+}
+
+// longestSuffix returns the first of the ASCII suffixes, given longest first, that w ends with
+func longestSuffix(w []rune, suffixes ...string) string {
+	for _, suffix := range suffixes {
+		if runesHaveSuffix(w, suffix) {
+			return suffix
+		}
+	}
+	return ""
+}
+
+func runesHaveSuffix(w []rune, suffix string) bool {
+	return len(w) >= len(suffix) && string(w[len(w)-len(suffix):]) == suffix
+}
+
+// precededBy reports whether the letter before the ASCII suffix is one of letters
+func precededBy(w []rune, suffix string, letters string) bool {
+	i := len(w) - len(suffix) - 1
+	return i >= 0 && strings.ContainsRune(letters, w[i])
+}
@@ -0,0 +1,81 @@
+package domain
+
+import (
+	"time"
+)
+
+const DefaultMetricsHistoryWindow = 30 * 24 * time.Hour
+
+type ContentMetricsSnapshot struct {
+	ID         int64     `json:"-" gorm:"primaryKey;autoIncrement"`
+	ContentID  int64     `json:"-" gorm:"not null;index:idx_metrics_history_content_recorded"`
+	Views      int       `json:"views"`
+	Likes      int       `json:"likes"`
+	Reactions  int       `json:"reactions"`
+	Comments   int       `json:"comments"`
+	Listens    int       `json:"listens"`
+	Score      float64   `json:"score" gorm:"type:decimal(10,4)"`
+	RecordedAt time.Time `json:"recorded_at" gorm:"autoCreateTime;index:idx_metrics_history_content_recorded"`
+}
+
+func (ContentMetricsSnapshot) TableName() string {
+	return "content_metrics_history"
+}
+
+// NewContentMetricsSnapshot captures the current engagement metrics of a content item
+func NewContentMetricsSnapshot(content *Content) *ContentMetricsSnapshot {
+	return &ContentMetricsSnapshot{
+		ContentID: content.ID,
+		Views:     content.Views,
+		Likes:     content.Likes,
+		Reactions: content.Reactions,
+		Comments:  content.Comments,
+		Listens:   content.Listens,
+		Score:     content.Score,
+	}
+}
+
+// HasMetricsChanged reports whether any tracked engagement metric differs between two versions of a content item
+func HasMetricsChanged(previous, current *Content) bool {
+	return previous.Views != current.Views ||
+		previous.Likes != current.Likes ||
+		previous.Reactions != current.Reactions ||
+		previous.Comments != current.Comments ||
+		previous.Listens != current.Listens
+}
+
+type MetricsHistoryRequest struct {
+	From time.Time
+	To   time.Time
+}
+
+type MetricsHistoryResponse struct {
+	ContentID int64                     `json:"content_id"`
+	From      time.Time                 `json:"from"`
+	To        time.Time                 `json:"to"`
+	Points    []*ContentMetricsSnapshot `json:"points"`
+}
+
+type MetricsWindowSpecification struct{}
+
+func NewMetricsWindowSpecification() *MetricsWindowSpecification {
+	return &MetricsWindowSpecification{}
+}
+
+// NormalizeWindow fills in a default window ending now and rejects inverted ranges
+func (s *MetricsWindowSpecification) NormalizeWindow(req *MetricsHistoryRequest, now time.Time) error {
+	if req.To.IsZero() {
+		req.To = now
+	}
+	if req.From.IsZero() {
+		req.From = req.To.Add(-DefaultMetricsHistoryWindow)
+	}
+	if s.isInvertedWindow(req) {
+		return NewInvalidInputError("from", "must be before to")
+	}
+	return nil
+}
+
+func (s *MetricsWindowSpecification) isInvertedWindow(req *MetricsHistoryRequest) bool {
+	return req.From.After(req.To)
+}
@@ -0,0 +1,39 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHasMetricsChanged(t *testing.T) {
+	previous := &Content{Views: 100, Likes: 10}
+
+	assert.False(t, HasMetricsChanged(previous, &Content{Views: 100, Likes: 10, Title: "Renamed"}))
+	assert.True(t, HasMetricsChanged(previous, &Content{Views: 150, Likes: 10}))
+	assert.True(t, HasMetricsChanged(previous, &Content{Views: 100, Likes: 10, Comments: 1}))
+}
+
+func TestMetricsWindowSpecification_NormalizeWindow(t *testing.T) {
+	spec := NewMetricsWindowSpecification()
+	now := time.Date(2024, 3, 15, 12, 0, 0, 0, time.UTC)
+
+	t.Run("Defaults to the last 30 days", func(t *testing.T) {
+		req := &MetricsHistoryRequest{}
+
+		err := spec.NormalizeWindow(req, now)
+
+		assert.NoError(t, err)
+		assert.Equal(t, now, req.To)
+		assert.Equal(t, now.Add(-DefaultMetricsHistoryWindow), req.From)
+	})
+
+	t.Run("Rejects inverted window", func(t *testing.T) {
+		req := &MetricsHistoryRequest{From: now, To: now.Add(-time.Hour)}
+
+		err := spec.NormalizeWindow(req, now)
+
+		assert.True(t, IsInvalidInputError(err))
+	})
+}
@@ -0,0 +1,59 @@
+package domain
+
+import (
+	"fmt"
+)
+
+// MaxMultiSearchQueries limits how many searches one multi-search may run
+const MaxMultiSearchQueries = 10
+
+// MultiSearchRequest is the JSON body accepted by POST /api/v1/msearch: searches take the same
+// fields as the query parameters of GET /api/v1/search
+type MultiSearchRequest struct {
+	Searches []*SearchRequest `json:"searches"`
+}
+
+// MultiSearchResult is the outcome of one search of a multi-search: its response, or the
+// error it failed with
+type MultiSearchResult struct {
+	Response *SearchResponse
+	Err      error
+}
+
+type MultiSearchSpecification struct{}
+
+func NewMultiSearchSpecification() *MultiSearchSpecification {
+	return &MultiSearchSpecification{}
+}
+
+// ValidateMultiSearch requires between one and MaxMultiSearchQueries searches
+func (s *MultiSearchSpecification) ValidateMultiSearch(req *MultiSearchRequest) error {
+	if len(req.Searches) == 0 {
+		return NewInvalidInputError("searches", "must contain at least one search")
+	}
+	if len(req.Searches) > MaxMultiSearchQueries {
+		return NewInvalidInputError("searches", fmt.Sprintf("must contain at most %d searches", MaxMultiSearchQueries))
+	}
+	for i, search := range req.Searches {
+		if search == nil {
+			return NewInvalidInputError(fmt.Sprintf("searches[%d]", i), "must be an object")
+		}
+	}
+	return nil
+}
+
+// NormalizeSearch treats an empty content type as none and rejects unknown ones, as the
+// search endpoint does for its query parameters
+func (s *MultiSearchSpecification) NormalizeSearch(req *SearchRequest) error {
+	if req.ContentType == nil {
+		return nil
+	}
+	if *req.ContentType == "" {
+		req.ContentType = nil
+		return nil
+	}
+	if !req.ContentType.IsValid() {
+		return NewInvalidInputError("content_type", "must be one of: video, text, audio, gallery")
+	}
+	return nil
+}
@@ -0,0 +1,463 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const (
+	QueryFieldTitle    = "title"
+	QueryFieldType     = "type"
+	QueryFieldProvider = "provider"
+	QueryFieldTag      = "tag"
+)
+
+// QueryFields lists the field qualifiers accepted by the query syntax
+var QueryFields = []string{QueryFieldTitle, QueryFieldType, QueryFieldProvider, QueryFieldTag}
+
+// QueryNode is a node of a parsed search query
+type QueryNode interface {
+	String() string
+}
+
+// TermNode matches a single word or quoted phrase against a field
+type TermNode struct {
+	Field  string
+	Value  string
+	Phrase bool
+}
+
+func (n *TermNode) String() string {
+	value := n.Value
+	if n.Phrase {
+		value = `"` + value + `"`
+	}
+	if n.Field == QueryFieldTitle {
+		return value
+	}
+	return n.Field + ":" + value
+}
+
+// AndNode matches content satisfying every child
+type AndNode struct {
+	Children []QueryNode
+}
+
+func (n *AndNode) String() string {
+	return "(" + joinQueryNodes(n.Children, " AND ") + ")"
+}
+
+// OrNode matches content satisfying at least one child
+type OrNode struct {
+	Children []QueryNode
+}
+
+func (n *OrNode) String() string {
+	return "(" + joinQueryNodes(n.Children, " OR ") + ")"
+}
+
+// NotNode matches content that does not satisfy its child
+type NotNode struct {
+	Child QueryNode
+}
+
+func (n *NotNode) String() string {
+	return "-" + n.Child.String()
+}
+
+// RangeNode bounds a numeric or time field; a nil bound is open
+type RangeNode struct {
+	Field          string
+	Lower          interface{}
+	LowerInclusive bool
+	Upper          interface{}
+	UpperInclusive bool
+}
+
+func (n *RangeNode) String() string {
+	var parts []string
+	if n.Lower != nil {
+		operator := ">"
+		if n.LowerInclusive {
+			operator = ">="
+		}
+		parts = append(parts, fmt.Sprintf("%s:%s%v", n.Field, operator, formatRangeBound(n.Lower)))
+	}
+	if n.Upper != nil {
+		operator := "<"
+		if n.UpperInclusive {
+			operator = "<="
+		}
+		parts = append(parts, fmt.Sprintf("%s:%s%v", n.Field, operator, formatRangeBound(n.Upper)))
+	}
+	return strings.Join(parts, " ")
+}
+
+func formatRangeBound(value interface{}) string {
+	if t, ok := value.(time.Time); ok {
+		return t.Format(time.RFC3339)
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+// MinimumMatchNode matches content satisfying at least Minimum of its children
+type MinimumMatchNode struct {
+	Minimum  int
+	Children []QueryNode
+}
+
+func (n *MinimumMatchNode) String() string {
+	return fmt.Sprintf("%d of (%s)", n.Minimum, joinQueryNodes(n.Children, ", "))
+}
+
+func joinQueryNodes(nodes []QueryNode, sep string) string {
+	parts := make([]string, 0, len(nodes))
+	for _, node := range nodes {
+		parts = append(parts, node.String())
+	}
+	return strings.Join(parts, sep)
+}
+
+// ParseQuery parses the search syntax into an AST. Bare words and "quoted phrases" match the
+// title; field:value qualifies a term (title, type, provider, tag), while a colon after any
+// other word is part of the word, as in "10:30"; terms are ANDed by default and can be
+// combined with AND, OR, NOT / -term and parentheses.
+// An empty query yields a nil node.
+func ParseQuery(input string) (QueryNode, error) {
+	tokens, err := lexQuery(input)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, nil
+	}
+
+	parser := &queryParser{tokens: tokens}
+	node, err := parser.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if !parser.atEnd() {
+		return nil, parser.errorf("unexpected %s", parser.peek().describe())
+	}
+	return node, nil
+}
+
+// QueryFreeText returns the positive title terms of a query, for backends that only
+// understand plain keywords
+func QueryFreeText(node QueryNode) string {
+	var terms []string
+	var collect func(QueryNode)
+	collect = func(node QueryNode) {
+		switch n := node.(type) {
+		case *TermNode:
+			if n.Field == QueryFieldTitle {
+				terms = append(terms, n.Value)
+			}
+		case *AndNode:
+			for _, child := range n.Children {
+				collect(child)
+			}
+		case *OrNode:
+			for _, child := range n.Children {
+				collect(child)
+			}
+		case *MinimumMatchNode:
+			for _, child := range n.Children {
+				collect(child)
+			}
+		}
+	}
+	if node != nil {
+		collect(node)
+	}
+	return strings.Join(terms, " ")
+}
+
+type queryTokenKind int
+
+const (
+	queryTokenWord queryTokenKind = iota
+	queryTokenPhrase
+	queryTokenField
+	queryTokenAnd
+	queryTokenOr
+	queryTokenNot
+	queryTokenLParen
+	queryTokenRParen
+)
+
+type queryToken struct {
+	kind queryTokenKind
+	text string
+	pos  int
+}
+
+func (t queryToken) describe() string {
+	switch t.kind {
+	case queryTokenPhrase:
+		return fmt.Sprintf("phrase %q at position %d", t.text, t.pos)
+	case queryTokenField:
+		return fmt.Sprintf("field %q at position %d", t.text, t.pos)
+	case queryTokenLParen:
+		return fmt.Sprintf("'(' at position %d", t.pos)
+	case queryTokenRParen:
+		return fmt.Sprintf("')' at position %d", t.pos)
+	default:
+		return fmt.Sprintf("%q at position %d", t.text, t.pos)
+	}
+}
+
+func lexQuery(input string) ([]queryToken, error) {
+	runes := []rune(input)
+	var tokens []queryToken
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, queryToken{kind: queryTokenLParen, text: "(", pos: i})
+			i++
+		case r == ')':
+			tokens = append(tokens, queryToken{kind: queryTokenRParen, text: ")", pos: i})
+			i++
+		case r == '"':
+			end := i + 1
+			for end < len(runes) && runes[end] != '"' {
+				end++
+			}
+			if end >= len(runes) {
+				return nil, NewInvalidInputError("query", fmt.Sprintf("unterminated phrase starting at position %d", i))
+			}
+			tokens = append(tokens, queryToken{kind: queryTokenPhrase, text: string(runes[i+1 : end]), pos: i})
+			i = end + 1
+		case r == '-' && isNotPrefix(runes, i):
+			tokens = append(tokens, queryToken{kind: queryTokenNot, text: "-", pos: i})
+			i++
+		default:
+			start := i
+			for i < len(runes) && !isQueryDelimiter(runes[i]) {
+				if runes[i] == ':' && isQueryField(strings.ToLower(string(runes[start:i]))) {
+					break
+				}
+				i++
+			}
+			word := string(runes[start:i])
+			if i < len(runes) && runes[i] == ':' {
+				tokens = append(tokens, queryToken{kind: queryTokenField, text: strings.ToLower(word), pos: start})
+				i++
+				continue
+			}
+			tokens = append(tokens, keywordOrWord(word, start))
+		}
+	}
+	return tokens, nil
+}
+
+// isNotPrefix treats '-' as negation only at the start of a term, so "e-mail" stays one word
+func isNotPrefix(runes []rune, i int) bool {
+	if i+1 >= len(runes) || unicode.IsSpace(runes[i+1]) {
+		return false
+	}
+	return i == 0 || unicode.IsSpace(runes[i-1]) || runes[i-1] == '('
+}
+
+func isQueryDelimiter(r rune) bool {
+	return unicode.IsSpace(r) || r == '(' || r == ')' || r == '"'
+}
+
+func keywordOrWord(word string, pos int) queryToken {
+	switch word {
+	case "AND":
+		return queryToken{kind: queryTokenAnd, text: word, pos: pos}
+	case "OR":
+		return queryToken{kind: queryTokenOr, text: word, pos: pos}
+	case "NOT":
+		return queryToken{kind: queryTokenNot, text: word, pos: pos}
+	}
+	return queryToken{kind: queryTokenWord, text: word, pos: pos}
+}
+
+type queryParser struct {
+	tokens []queryToken
+	pos    int
+}
+
+func (p *queryParser) atEnd() bool {
+	return p.pos >= len(p.tokens)
+}
+
+func (p *queryParser) peek() queryToken {
+	return p.tokens[p.pos]
+}
+
+func (p *queryParser) next() queryToken {
+	token := p.tokens[p.pos]
+	p.pos++
+	return token
+}
+
+func (p *queryParser) errorf(format string, args ...interface{}) error {
+	return NewInvalidInputError("query", fmt.Sprintf(format, args...))
+}
+
+func (p *queryParser) parseOr() (QueryNode, error) {
+	first, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	children := []QueryNode{first}
+
+	for !p.atEnd() && p.peek().kind == queryTokenOr {
+		operator := p.next()
+		if p.atEnd() {
+			return nil, p.errorf("expected a term after OR at position %d", operator.pos)
+		}
+		child, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, child)
+	}
+
+	if len(children) == 1 {
+		return first, nil
+	}
+	return &OrNode{Children: children}, nil
+}
+
+func (p *queryParser) parseAnd() (QueryNode, error) {
+	first, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	children := []QueryNode{first}
+
+	for !p.atEnd() {
+		token := p.peek()
+		if token.kind == queryTokenOr || token.kind == queryTokenRParen {
+			break
+		}
+		if token.kind == queryTokenAnd {
+			p.next()
+			if p.atEnd() {
+				return nil, p.errorf("expected a term after AND at position %d", token.pos)
+			}
+		}
+		child, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, child)
+	}
+
+	if len(children) == 1 {
+		return first, nil
+	}
+	return &AndNode{Children: children}, nil
+}
+
+func (p *queryParser) parseUnary() (QueryNode, error) {
+	if p.atEnd() {
+		return nil, p.errorf("unexpected end of query")
+	}
+	if p.peek().kind == queryTokenNot {
+		operator := p.next()
+		if p.atEnd() {
+			return nil, p.errorf("expected a term after %s at position %d", operator.text, operator.pos)
+		}
+		child, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &NotNode{Child: child}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *queryParser) parsePrimary() (QueryNode, error) {
+	token := p.next()
+	switch token.kind {
+	case queryTokenLParen:
+		if !p.atEnd() && p.peek().kind == queryTokenRParen {
+			return nil, p.errorf("empty group at position %d", token.pos)
+		}
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.atEnd() || p.peek().kind != queryTokenRParen {
+			return nil, p.errorf("missing ')' for group opened at position %d", token.pos)
+		}
+		p.next()
+		return node, nil
+	case queryTokenField:
+		return p.parseFieldValue(token)
+	case queryTokenWord:
+		return &TermNode{Field: QueryFieldTitle, Value: token.text}, nil
+	case queryTokenPhrase:
+		return p.phraseNode(QueryFieldTitle, token)
+	default:
+		return nil, p.errorf("unexpected %s", token.describe())
+	}
+}
+
+func (p *queryParser) parseFieldValue(field queryToken) (QueryNode, error) {
+	if p.atEnd() {
+		return nil, p.errorf("expected a value for field %q at position %d", field.text, field.pos)
+	}
+
+	value := p.next()
+	switch value.kind {
+	case queryTokenPhrase:
+		return p.phraseNode(field.text, value)
+	case queryTokenWord:
+		return p.fieldTerm(field.text, value.text, false, value.pos)
+	default:
+		return nil, p.errorf("expected a value for field %q at position %d", field.text, field.pos)
+	}
+}
+
+func (p *queryParser) phraseNode(field string, token queryToken) (QueryNode, error) {
+	if strings.TrimSpace(token.text) == "" {
+		return nil, p.errorf("empty phrase at position %d", token.pos)
+	}
+	return p.fieldTerm(field, strings.TrimSpace(token.text), true, token.pos)
+}
+
+func (p *queryParser) fieldTerm(field, value string, phrase bool, pos int) (QueryNode, error) {
+	normalized, ok := normalizeFieldValue(field, value)
+	if !ok {
+		return nil, p.errorf("unknown type %q at position %d, must be one of: video, text, audio, gallery", value, pos)
+	}
+	return &TermNode{Field: field, Value: normalized, Phrase: phrase && field == QueryFieldTitle}, nil
+}
+
+// normalizeFieldValue brings a term value into the form stored for its field, reporting
+// false for type values that are not a known content type
+func normalizeFieldValue(field, value string) (string, bool) {
+	switch field {
+	case QueryFieldType:
+		contentType := ContentType(strings.ToLower(value))
+		return string(contentType), contentType.IsValid()
+	case QueryFieldTag:
+		return NormalizeTagName(value), true
+	case QueryFieldProvider:
+		return strings.ToLower(value), true
+	default:
+		return value, true
+	}
+}
+
+func isQueryField(field string) bool {
+	for _, known := range QueryFields {
+		if field == known {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,95 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseQuery(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"Single word", "golang", "golang"},
+		{"Implicit AND", "golang tutorial", "(golang AND tutorial)"},
+		{"Phrase", `"go concurrency"`, `"go concurrency"`},
+		{"Field qualified phrase", `title:"go concurrency"`, `"go concurrency"`},
+		{"Negation with minus", "go -beginner", "(go AND -beginner)"},
+		{"Negation with NOT", "go NOT beginner", "(go AND -beginner)"},
+		{"Hyphenated word is not negated", "e-mail", "e-mail"},
+		{"OR binds looser than AND", "go rust OR zig", "((go AND rust) OR zig)"},
+		{"Explicit AND", "go AND rust", "(go AND rust)"},
+		{"Grouping", "(go OR rust) type:video", "((go OR rust) AND type:video)"},
+		{"Type is normalized", "type:VIDEO", "type:video"},
+		{"Tag is normalized", "tag:Best_Practices", "tag:best-practices"},
+		{"Provider is lowercased", "provider:Provider2", "provider:provider2"},
+		{"Unknown field is literal text", "author:rob", "author:rob"},
+		{"Colons in free text", "Star Wars: Episode 10:30", "(Star AND Wars: AND Episode AND 10:30)"},
+		{
+			"Full example",
+			`title:"go concurrency" -beginner type:video provider:provider2`,
+			`("go concurrency" AND -beginner AND type:video AND provider:provider2)`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node, err := ParseQuery(tt.input)
+
+			require.NoError(t, err)
+			require.NotNil(t, node)
+			assert.Equal(t, tt.expected, node.String())
+		})
+	}
+
+	t.Run("Empty query yields nil", func(t *testing.T) {
+		node, err := ParseQuery("   ")
+
+		assert.NoError(t, err)
+		assert.Nil(t, node)
+	})
+}
+
+func TestParseQuery_SyntaxErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		reason string
+	}{
+		{"Unterminated phrase", `"go concurrency`, "unterminated phrase starting at position 0"},
+		{"Unclosed group", "(go OR rust", "missing ')' for group opened at position 0"},
+		{"Unexpected closing parenthesis", "go)", "unexpected ')' at position 2"},
+		{"Empty group", "go ()", "empty group at position 3"},
+		{"Trailing OR", "go OR", "expected a term after OR at position 3"},
+		{"Leading AND", "AND go", `unexpected "AND" at position 0`},
+		{"Dangling NOT", "go NOT", "expected a term after NOT at position 3"},
+		{"Missing field value", "type:", `expected a value for field "type" at position 0`},
+		{"Unknown type", "type:hologram", `unknown type "hologram" at position 5, must be one of: video, text, audio, gallery`},
+		{"Empty phrase", `""`, "empty phrase at position 0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node, err := ParseQuery(tt.input)
+
+			assert.Nil(t, node)
+			require.Error(t, err)
+			domainErr, ok := err.(*DomainError)
+			require.True(t, ok)
+			assert.Equal(t, ErrorCodeInvalidInput, domainErr.Code)
+			assert.Equal(t, "query", domainErr.Details["field"])
+			assert.Equal(t, tt.reason, domainErr.Details["reason"])
+		})
+	}
+}
+
+func TestQueryFreeText(t *testing.T) {
+	node, err := ParseQuery(`title:"go concurrency" -beginner type:video (patterns OR idioms)`)
+	require.NoError(t, err)
+
+	assert.Equal(t, "go concurrency patterns idioms", QueryFreeText(node))
+	assert.Equal(t, "", QueryFreeText(nil))
+}
@@ -0,0 +1,76 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+type RangeFilterSpecification struct{}
+
+func NewRangeFilterSpecification() *RangeFilterSpecification {
+	return &RangeFilterSpecification{}
+}
+
+// NormalizeRangeFilter parses the publish date bounds into PublishedAfterTime and
+// PublishedBeforeTime and checks that every engagement bound forms a valid range.
+// published_after is inclusive and published_before exclusive, so consecutive windows
+// never overlap.
+func (s *RangeFilterSpecification) NormalizeRangeFilter(req *SearchRequest) error {
+	var err error
+	if req.PublishedAfterTime, err = s.parseBound("published_after", req.PublishedAfter); err != nil {
+		return err
+	}
+	if req.PublishedBeforeTime, err = s.parseBound("published_before", req.PublishedBefore); err != nil {
+		return err
+	}
+	if req.PublishedAfterTime != nil && req.PublishedBeforeTime != nil && !req.PublishedBeforeTime.After(*req.PublishedAfterTime) {
+		return NewInvalidInputError("published_before", "must be later than published_after")
+	}
+
+	for _, bounds := range []struct {
+		field    string
+		min, max *int
+	}{{"views", req.MinViews, req.MaxViews}, {"likes", req.MinLikes, req.MaxLikes}} {
+		if s.isNegative(bounds.min) {
+			return NewInvalidInputError("min_"+bounds.field, "must be zero or greater")
+		}
+		if s.isNegative(bounds.max) {
+			return NewInvalidInputError("max_"+bounds.field, "must be zero or greater")
+		}
+		if bounds.min != nil && bounds.max != nil && *bounds.max < *bounds.min {
+			return NewInvalidInputError("max_"+bounds.field, "must be greater than or equal to min_"+bounds.field)
+		}
+	}
+
+	if req.MinScore != nil && req.MaxScore != nil && *req.MaxScore < *req.MinScore {
+		return NewInvalidInputError("max_score", "must be greater than or equal to min_score")
+	}
+	return nil
+}
+
+func (s *RangeFilterSpecification) parseBound(field, value string) (*time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil
+	}
+	t, ok := parseDate(value)
+	if !ok {
+		return nil, NewInvalidInputError(field, "must be an RFC3339 timestamp or YYYY-MM-DD date")
+	}
+	return &t, nil
+}
+
+func (s *RangeFilterSpecification) isNegative(value *int) bool {
+	return value != nil && *value < 0
+}
+
+// parseDate accepts an RFC3339 timestamp or a YYYY-MM-DD date, which means midnight UTC
+func parseDate(value string) (time.Time, bool) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t.UTC(), true
+	}
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		return t, true
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,59 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRangeFilterSpecification_NormalizeRangeFilter(t *testing.T) {
+	spec := NewRangeFilterSpecification()
+	intPtr := func(v int) *int { return &v }
+	floatPtr := func(v float64) *float64 { return &v }
+
+	t.Run("Accepts no filters", func(t *testing.T) {
+		req := &SearchRequest{}
+
+		require.NoError(t, spec.NormalizeRangeFilter(req))
+		assert.Nil(t, req.PublishedAfterTime)
+		assert.Nil(t, req.PublishedBeforeTime)
+	})
+
+	t.Run("Parses dates and timestamps", func(t *testing.T) {
+		req := &SearchRequest{PublishedAfter: "2024-01-01", PublishedBefore: "2024-02-01T12:00:00+02:00"}
+
+		require.NoError(t, spec.NormalizeRangeFilter(req))
+		assert.Equal(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), *req.PublishedAfterTime)
+		assert.Equal(t, time.Date(2024, 2, 1, 10, 0, 0, 0, time.UTC), *req.PublishedBeforeTime)
+	})
+
+	t.Run("Accepts engagement ranges", func(t *testing.T) {
+		req := &SearchRequest{MinViews: intPtr(100), MaxViews: intPtr(100), MinLikes: intPtr(5), MinScore: floatPtr(2.5), MaxScore: floatPtr(10)}
+
+		assert.NoError(t, spec.NormalizeRangeFilter(req))
+	})
+
+	tests := []struct {
+		name  string
+		req   *SearchRequest
+		field string
+	}{
+		{"Rejects malformed date", &SearchRequest{PublishedAfter: "yesterday"}, "published_after"},
+		{"Rejects empty date window", &SearchRequest{PublishedAfter: "2024-02-01", PublishedBefore: "2024-02-01"}, "published_before"},
+		{"Rejects negative views", &SearchRequest{MinViews: intPtr(-1)}, "min_views"},
+		{"Rejects negative likes", &SearchRequest{MaxLikes: intPtr(-5)}, "max_likes"},
+		{"Rejects inverted views", &SearchRequest{MinViews: intPtr(1000), MaxViews: intPtr(10)}, "max_views"},
+		{"Rejects inverted score", &SearchRequest{MinScore: floatPtr(5), MaxScore: floatPtr(1.5)}, "max_score"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := spec.NormalizeRangeFilter(tt.req)
+
+			require.True(t, IsInvalidInputError(err))
+			assert.Equal(t, tt.field, err.(*DomainError).Details["field"])
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package domain
+
+const (
+	// relatedTagWeight is what a shared tag adds to the similarity of two items; a shared
+	// title term adds its inverse document frequency, which is around 1 for common words
+	relatedTagWeight = 1.5
+	// Affinity only raises items that already share a title term or tag
+	relatedTypeWeight     = 0.5
+	relatedProviderWeight = 0.25
+)
+
+type RelatedRequest struct {
+	Page     int `form:"page"`
+	PageSize int `form:"page_size"`
+}
+
+type RelatedSpecification struct{}
+
+func NewRelatedSpecification() *RelatedSpecification {
+	return &RelatedSpecification{}
+}
+
+// NormalizeRelatedRequest applies the pagination defaults of search
+func (s *RelatedSpecification) NormalizeRelatedRequest(req *RelatedRequest) {
+	if req.Page <= 0 {
+		req.Page = DefaultPage
+	}
+	if req.PageSize <= 0 {
+		req.PageSize = DefaultPageSize
+	}
+	if req.PageSize > MaxPageSize {
+		req.PageSize = MaxPageSize
+	}
+}
+
+// CandidateQuery returns a query matching content that shares a title word or a tag with
+// source, or nil when source has neither
+func (s *RelatedSpecification) CandidateQuery(source *Content) QueryNode {
+	var children []QueryNode
+	seen := make(map[string]bool)
+	for _, token := range titleTokens(source.Title) {
+		if !seen[token] {
+			seen[token] = true
+			children = append(children, &TermNode{Field: QueryFieldTitle, Value: token})
+		}
+	}
+	for _, tag := range source.Tags {
+		children = append(children, &TermNode{Field: QueryFieldTag, Value: tag.Name})
+	}
+
+	if len(children) == 0 {
+		return nil
+	}
+	return &OrNode{Children: children}
+}
+
+// Similarity scores how related candidate is to source: the inverse document frequency of
+// every title term they share, each title analyzed in its language, so that rare words count more, plus a fixed weight per shared
+// tag. Items sharing neither score zero; the others gain a little for the same type and
+// provider.
+func (s *RelatedSpecification) Similarity(source, candidate *Content, corpus *BM25Corpus) float64 {
+	candidateTerms := make(map[string]bool)
+	for _, term := range AnalyzeTextIn(candidate.Title, ContentLanguage(candidate)) {
+		candidateTerms[term] = true
+	}
+
+	similarity := 0.0
+	seen := make(map[string]bool)
+	for _, term := range AnalyzeTextIn(source.Title, ContentLanguage(source)) {
+		if !seen[term] && candidateTerms[term] {
+			similarity += corpus.IDF(term)
+		}
+		seen[term] = true
+	}
+
+	candidateTags := make(map[string]bool, len(candidate.Tags))
+	for _, tag := range candidate.Tags {
+		candidateTags[tag.Name] = true
+	}
+	for _, tag := range source.Tags {
+		if candidateTags[tag.Name] {
+			similarity += relatedTagWeight
+		}
+	}
+
+	if similarity == 0 {
+		return 0
+	}
+	if candidate.Type == source.Type {
+		similarity += relatedTypeWeight
+	}
+	if candidate.Provider == source.Provider {
+		similarity += relatedProviderWeight
+	}
+	return similarity
+}
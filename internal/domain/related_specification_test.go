@@ -0,0 +1,72 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRelatedSpecification_NormalizeRelatedRequest(t *testing.T) {
+	spec := NewRelatedSpecification()
+
+	req := &RelatedRequest{}
+	spec.NormalizeRelatedRequest(req)
+	assert.Equal(t, RelatedRequest{Page: DefaultPage, PageSize: DefaultPageSize}, *req)
+
+	req = &RelatedRequest{Page: 3, PageSize: 500}
+	spec.NormalizeRelatedRequest(req)
+	assert.Equal(t, RelatedRequest{Page: 3, PageSize: MaxPageSize}, *req)
+}
+
+func TestRelatedSpecification_CandidateQuery(t *testing.T) {
+	spec := NewRelatedSpecification()
+
+	source := &Content{Title: "The Go Tour: Go Basics", Tags: []Tag{{Name: "golang"}}}
+	assert.Equal(t, "(go OR tour OR basics OR tag:golang)", spec.CandidateQuery(source).String())
+
+	assert.Nil(t, spec.CandidateQuery(&Content{Title: "The"}))
+}
+
+func TestRelatedSpecification_Similarity(t *testing.T) {
+	spec := NewRelatedSpecification()
+	corpus := NewBM25Corpus()
+	for _, title := range []string{"Go Concurrency", "Go Generics", "Go Tutorial", "Rust Concurrency", "Python Tutorial"} {
+		corpus.Add(AnalyzeText(title))
+	}
+
+	source := &Content{Title: "Go Concurrency", Type: ContentTypeVideo, Provider: "provider1", Tags: []Tag{{Name: "golang"}}}
+
+	t.Run("Nothing shared", func(t *testing.T) {
+		candidate := &Content{Title: "Python Tutorial", Type: ContentTypeVideo, Provider: "provider1"}
+
+		assert.Equal(t, 0.0, spec.Similarity(source, candidate, corpus))
+	})
+
+	t.Run("Rare shared terms count more", func(t *testing.T) {
+		common := &Content{Title: "Go Generics", Type: ContentTypeText, Provider: "provider2"}
+		rare := &Content{Title: "Rust Concurrency", Type: ContentTypeText, Provider: "provider2"}
+
+		assert.Greater(t, spec.Similarity(source, rare, corpus), spec.Similarity(source, common, corpus))
+	})
+
+	t.Run("Stemmed terms match", func(t *testing.T) {
+		candidate := &Content{Title: "Concurrent Programs", Type: ContentTypeText, Provider: "provider2"}
+
+		assert.Greater(t, spec.Similarity(source, candidate, corpus), 0.0)
+	})
+
+	t.Run("Tags and affinity add up", func(t *testing.T) {
+		plain := &Content{Title: "Go Generics", Type: ContentTypeText, Provider: "provider2"}
+		tagged := &Content{Title: "Go Generics", Type: ContentTypeText, Provider: "provider2", Tags: []Tag{{Name: "golang"}}}
+		affine := &Content{Title: "Go Generics", Type: ContentTypeVideo, Provider: "provider1", Tags: []Tag{{Name: "golang"}}}
+
+		assert.InDelta(t, spec.Similarity(source, plain, corpus)+relatedTagWeight, spec.Similarity(source, tagged, corpus), 1e-9)
+		assert.InDelta(t, spec.Similarity(source, tagged, corpus)+relatedTypeWeight+relatedProviderWeight, spec.Similarity(source, affine, corpus), 1e-9)
+	})
+
+	t.Run("Shared tag alone", func(t *testing.T) {
+		candidate := &Content{Title: "Python Tutorial", Tags: []Tag{{Name: "golang"}}}
+
+		assert.Equal(t, relatedTagWeight, spec.Similarity(source, candidate, corpus))
+	})
+}
@@ -0,0 +1,165 @@
+package domain
+
+import (
+	"math"
+)
+
+const (
+	SortByRelevance = "relevance"
+
+	// RelevanceScoreScale is the stored score that counts as half of the maximum score
+	// component; scores are unbounded, so they are squashed into [0, 1) like text ranks
+	RelevanceScoreScale = 10.0
+
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// RelevanceWeights sets how much text relevance and the stored popularity score contribute
+// to the blended relevance of sort_by=relevance
+type RelevanceWeights struct {
+	Text  float64
+	Score float64
+}
+
+// DefaultRelevanceWeights favours how well the title matches over popularity
+var DefaultRelevanceWeights = RelevanceWeights{Text: 0.7, Score: 0.3}
+
+type RelevanceSpecification struct {
+	weights RelevanceWeights
+}
+
+// NewRelevanceSpecification falls back to the default weights when the given ones are
+// negative or both zero
+func NewRelevanceSpecification(weights RelevanceWeights) *RelevanceSpecification {
+	if weights.Text < 0 || weights.Score < 0 || weights.Text+weights.Score == 0 {
+		weights = DefaultRelevanceWeights
+	}
+	return &RelevanceSpecification{weights: weights}
+}
+
+func (s *RelevanceSpecification) Weights() RelevanceWeights {
+	return s.weights
+}
+
+// Blend combines a text rank already normalized to [0, 1) with the stored score
+func (s *RelevanceSpecification) Blend(textRank, score float64) float64 {
+	return s.weights.Text*textRank + s.weights.Score*s.NormalizeScore(score)
+}
+
+// NormalizeScore squashes a stored score into [0, 1)
+func (s *RelevanceSpecification) NormalizeScore(score float64) float64 {
+	if score <= 0 {
+		return 0
+	}
+	return score / (score + RelevanceScoreScale)
+}
+
+// NormalizeRank squashes an unbounded text rank such as BM25 into [0, 1), the same way
+// ts_rank_cd normalization 32 does
+func (s *RelevanceSpecification) NormalizeRank(rank float64) float64 {
+	if rank <= 0 {
+		return 0
+	}
+	return rank / (rank + 1)
+}
+
+// RelevanceTerms returns the distinct title tokens of the positive title terms of a query
+func RelevanceTerms(nodes ...QueryNode) []string {
+	seen := make(map[string]bool)
+	var tokens []string
+	for _, term := range HighlightTerms(nodes...) {
+		for _, token := range titleTokens(term.Value) {
+			if !seen[token] {
+				seen[token] = true
+				tokens = append(tokens, token)
+			}
+		}
+	}
+	return tokens
+}
+
+// BM25Corpus holds the document statistics the Okapi BM25 formula needs: the number of
+// documents, their average length and how many documents contain each token
+type BM25Corpus struct {
+	documents     int
+	totalLength   int
+	documentFreqs map[string]int
+}
+
+func NewBM25Corpus() *BM25Corpus {
+	return &BM25Corpus{documentFreqs: make(map[string]int)}
+}
+
+// Add counts one document, given as the tokens of its title
+func (c *BM25Corpus) Add(tokens []string) {
+	c.documents++
+	c.totalLength += len(tokens)
+	seen := make(map[string]bool, len(tokens))
+	for _, token := range tokens {
+		if !seen[token] {
+			seen[token] = true
+			c.documentFreqs[token]++
+		}
+	}
+}
+
+// Remove uncounts a document previously added with the same tokens
+func (c *BM25Corpus) Remove(tokens []string) {
+	c.documents--
+	c.totalLength -= len(tokens)
+	seen := make(map[string]bool, len(tokens))
+	for _, token := range tokens {
+		if !seen[token] {
+			seen[token] = true
+			if c.documentFreqs[token]--; c.documentFreqs[token] <= 0 {
+				delete(c.documentFreqs, token)
+			}
+		}
+	}
+}
+
+// AddTitle counts one document by its title
+func (c *BM25Corpus) AddTitle(title string) {
+	c.Add(titleTokens(title))
+}
+
+// RemoveTitle uncounts a document previously added by its title
+func (c *BM25Corpus) RemoveTitle(title string) {
+	c.Remove(titleTokens(title))
+}
+
+// Score returns the BM25 score of a document for the query tokens
+func (c *BM25Corpus) Score(query []string, document []string) float64 {
+	if c.documents == 0 || len(document) == 0 {
+		return 0
+	}
+
+	frequencies := make(map[string]int, len(document))
+	for _, token := range document {
+		frequencies[token]++
+	}
+	averageLength := float64(c.totalLength) / float64(c.documents)
+	lengthRatio := float64(len(document)) / averageLength
+
+	score := 0.0
+	for _, token := range query {
+		frequency := float64(frequencies[token])
+		if frequency == 0 {
+			continue
+		}
+		score += c.IDF(token) * frequency * (bm25K1 + 1) / (frequency + bm25K1*(1-bm25B+bm25B*lengthRatio))
+	}
+	return score
+}
+
+// ScoreTitle returns the BM25 score of a title for the query tokens
+func (c *BM25Corpus) ScoreTitle(query []string, title string) float64 {
+	return c.Score(query, titleTokens(title))
+}
+
+// IDF is the inverse document frequency of a token, using the variant that never goes negative
+func (c *BM25Corpus) IDF(token string) float64 {
+	df := float64(c.documentFreqs[token])
+	return math.Log(1 + (float64(c.documents)-df+0.5)/(df+0.5))
+}
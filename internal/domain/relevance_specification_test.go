@@ -0,0 +1,84 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewRelevanceSpecification(t *testing.T) {
+	assert.Equal(t, RelevanceWeights{Text: 1, Score: 0}, NewRelevanceSpecification(RelevanceWeights{Text: 1}).Weights())
+	assert.Equal(t, DefaultRelevanceWeights, NewRelevanceSpecification(RelevanceWeights{}).Weights())
+	assert.Equal(t, DefaultRelevanceWeights, NewRelevanceSpecification(RelevanceWeights{Text: -1, Score: 2}).Weights())
+}
+
+func TestRelevanceSpecification_Blend(t *testing.T) {
+	spec := NewRelevanceSpecification(RelevanceWeights{Text: 0.6, Score: 0.4})
+
+	assert.Equal(t, 0.0, spec.NormalizeScore(0))
+	assert.InDelta(t, 0.5, spec.NormalizeScore(RelevanceScoreScale), 1e-9)
+	assert.InDelta(t, 0.5, spec.NormalizeRank(1), 1e-9)
+	assert.InDelta(t, 0.6*0.5+0.4*0.5, spec.Blend(0.5, RelevanceScoreScale), 1e-9)
+
+	// a strong title match outweighs a popular item that barely matches
+	assert.Greater(t, spec.Blend(spec.NormalizeRank(3), 1), spec.Blend(spec.NormalizeRank(0.2), 80))
+}
+
+func TestRelevanceTerms(t *testing.T) {
+	node, err := ParseQuery(`"the Go Tour" OR go -rust type:video`)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"go", "tour"}, RelevanceTerms(node))
+}
+
+func TestBM25Corpus(t *testing.T) {
+	corpus := NewBM25Corpus()
+	titles := []string{
+		"Kubernetes in Production",
+		"Kubernetes Operators Explained",
+		"Rust Ownership",
+		"Rust Ownership and Borrowing in Depth with Many Examples",
+		"Go Concurrency",
+	}
+	for _, title := range titles {
+		corpus.AddTitle(title)
+	}
+
+	t.Run("No matching token scores zero", func(t *testing.T) {
+		assert.Equal(t, 0.0, corpus.ScoreTitle([]string{"python"}, "Rust Ownership"))
+	})
+
+	t.Run("Rare tokens weigh more", func(t *testing.T) {
+		assert.Greater(t, corpus.IDF("concurrency"), corpus.IDF("kubernetes"))
+	})
+
+	t.Run("Shorter documents score higher for the same match", func(t *testing.T) {
+		short := corpus.ScoreTitle([]string{"ownership"}, titles[2])
+		long := corpus.ScoreTitle([]string{"ownership"}, titles[3])
+
+		assert.Greater(t, short, long)
+	})
+
+	t.Run("Matching more tokens scores higher", func(t *testing.T) {
+		one := corpus.ScoreTitle([]string{"rust", "borrowing"}, titles[2])
+		both := corpus.ScoreTitle([]string{"rust", "borrowing"}, titles[3])
+
+		assert.Greater(t, both, one)
+	})
+
+	t.Run("Removing a document restores the statistics", func(t *testing.T) {
+		before := corpus.IDF("haskell")
+		corpus.AddTitle("Haskell Monads")
+		require.Less(t, corpus.IDF("haskell"), before)
+
+		corpus.Remove(titleTokens("Haskell Monads"))
+
+		fresh := NewBM25Corpus()
+		for _, title := range titles {
+			fresh.AddTitle(title)
+		}
+		assert.Equal(t, before, corpus.IDF("haskell"))
+		assert.InDelta(t, fresh.ScoreTitle([]string{"rust"}, titles[2]), corpus.ScoreTitle([]string{"rust"}, titles[2]), 1e-9)
+	})
+}
@@ -15,8 +15,13 @@ func NewContentPopularityScoreSpecification() *ContentPopularityScoreSpecificati
 }
 
 func (s *ContentPopularityScoreSpecification) Calculate(content *Content) float64 {
-	if s.isVideoContent(content) {
+	switch {
+	case s.isVideoContent(content):
 		return float64(content.Views)/1000.0 + float64(content.Likes)/100.0
+	case s.isAudioContent(content):
+		return NewAudioPopularityScoreSpecification().Calculate(content)
+	case s.isGalleryContent(content):
+		return NewGalleryPopularityScoreSpecification().Calculate(content)
 	}
 	return float64(content.ReadingTime) + float64(content.Reactions)/50.0
 }
@@ -25,6 +30,48 @@ func (s *ContentPopularityScoreSpecification) isVideoContent(content *Content) b
 	return content.Type == ContentTypeVideo
 }
 
+func (s *ContentPopularityScoreSpecification) isAudioContent(content *Content) bool {
+	return content.Type == ContentTypeAudio
+}
+
+func (s *ContentPopularityScoreSpecification) isGalleryContent(content *Content) bool {
+	return content.Type == ContentTypeGallery
+}
+
+type AudioPopularityScoreSpecification struct{}
+
+func NewAudioPopularityScoreSpecification() *AudioPopularityScoreSpecification {
+	return &AudioPopularityScoreSpecification{}
+}
+
+func (s *AudioPopularityScoreSpecification) Calculate(content *Content) float64 {
+	if !s.isAudioContent(content) {
+		return 0.0
+	}
+	return float64(content.Listens)/500.0 + float64(content.Likes)/100.0
+}
+
+func (s *AudioPopularityScoreSpecification) isAudioContent(content *Content) bool {
+	return content.Type == ContentTypeAudio
+}
+
+type GalleryPopularityScoreSpecification struct{}
+
+func NewGalleryPopularityScoreSpecification() *GalleryPopularityScoreSpecification {
+	return &GalleryPopularityScoreSpecification{}
+}
+
+func (s *GalleryPopularityScoreSpecification) Calculate(content *Content) float64 {
+	if !s.isGalleryContent(content) {
+		return 0.0
+	}
+	return float64(content.Views)/1000.0 + float64(content.Likes)/100.0 + float64(content.ImageCount)/10.0
+}
+
+func (s *GalleryPopularityScoreSpecification) isGalleryContent(content *Content) bool {
+	return content.Type == ContentTypeGallery
+}
+
 type VideoTypeBoostSpecification struct{}
 
 func NewVideoTypeBoostSpecification() *VideoTypeBoostSpecification {
@@ -87,13 +134,20 @@ func NewContentQualityRatioSpecification() *ContentQualityRatioSpecification {
 }
 
 func (s *ContentQualityRatioSpecification) Calculate(content *Content) float64 {
-	if s.isVideoContent(content) {
+	if s.isVideoContent(content) || s.isGalleryContent(content) {
 		if s.hasNoViews(content) {
 			return 0.0
 		}
 		return (float64(content.Likes) / float64(content.Views)) * 10.0
 	}
 
+	if s.isAudioContent(content) {
+		if s.hasNoListens(content) {
+			return 0.0
+		}
+		return (float64(content.Likes) / float64(content.Listens)) * 10.0
+	}
+
 	if s.hasNoReadingTime(content) {
 		return 0.0
 	}
@@ -104,6 +158,18 @@ func (s *ContentQualityRatioSpecification) isVideoContent(content *Content) bool
 	return content.Type == ContentTypeVideo
 }
 
+func (s *ContentQualityRatioSpecification) isAudioContent(content *Content) bool {
+	return content.Type == ContentTypeAudio
+}
+
+func (s *ContentQualityRatioSpecification) isGalleryContent(content *Content) bool {
+	return content.Type == ContentTypeGallery
+}
+
+func (s *ContentQualityRatioSpecification) hasNoListens(content *Content) bool {
+	return content.Listens == 0
+}
+
 func (s *ContentQualityRatioSpecification) hasNoViews(content *Content) bool {
 	return content.Views == 0
 }
@@ -112,6 +178,23 @@ func (s *ContentQualityRatioSpecification) hasNoReadingTime(content *Content) bo
 	return content.ReadingTime == 0
 }
 
+type DiscussionEngagementScoreSpecification struct{}
+
+func NewDiscussionEngagementScoreSpecification() *DiscussionEngagementScoreSpecification {
+	return &DiscussionEngagementScoreSpecification{}
+}
+
+func (s *DiscussionEngagementScoreSpecification) Calculate(content *Content) float64 {
+	if s.hasNoComments(content) {
+		return 0.0
+	}
+	return float64(content.Comments) / 10.0
+}
+
+func (s *DiscussionEngagementScoreSpecification) hasNoComments(content *Content) bool {
+	return content.Comments <= 0
+}
+
 type CompositeScoreSpecification struct {
 	specs []ScoreSpecification
 }
@@ -146,6 +229,7 @@ func (s *ContentRelevanceScoreSpecification) Calculate(content *Content) float64
 		NewVideoTypeBoostSpecification(),
 		NewRecentContentBoostSpecification(now),
 		NewContentQualityRatioSpecification(),
+		NewDiscussionEngagementScoreSpecification(),
 	)
 	return composite.Calculate(content)
 }
@@ -38,6 +38,21 @@ func TestContentRelevanceScoreSpecification_Calculate(t *testing.T) {
 		expected := 11.0 + 3.0 + 25.0
 		assert.Equal(t, expected, score)
 	})
+
+	t.Run("Comments add to relevance score", func(t *testing.T) {
+		content := &Content{
+			Type:        ContentTypeText,
+			ReadingTime: 10,
+			Reactions:   50,
+			Comments:    25,
+			CreatedAt:   now.Add(-15 * 24 * time.Hour),
+		}
+
+		score := spec.Calculate(content)
+
+		expected := 11.0 + 3.0 + 25.0 + 2.5
+		assert.Equal(t, expected, score)
+	})
 }
 
 func TestCompositeScoreSpecification(t *testing.T) {
@@ -71,3 +86,42 @@ func TestCompositeScoreSpecification(t *testing.T) {
 		assert.Equal(t, expected, score)
 	})
 }
+
+func TestDiscussionEngagementScoreSpecification(t *testing.T) {
+	spec := NewDiscussionEngagementScoreSpecification()
+
+	t.Run("Rewards comments", func(t *testing.T) {
+		content := &Content{Type: ContentTypeText, Comments: 40}
+
+		assert.Equal(t, 4.0, spec.Calculate(content))
+	})
+
+	t.Run("No comments scores zero", func(t *testing.T) {
+		content := &Content{Type: ContentTypeVideo}
+
+		assert.Equal(t, 0.0, spec.Calculate(content))
+	})
+}
+
+func TestContentPopularityScoreSpecification_PerType(t *testing.T) {
+	spec := NewContentPopularityScoreSpecification()
+
+	t.Run("Audio uses listens", func(t *testing.T) {
+		content := &Content{Type: ContentTypeAudio, Listens: 5000, Likes: 200}
+
+		assert.Equal(t, 12.0, spec.Calculate(content))
+	})
+
+	t.Run("Gallery uses views and image count", func(t *testing.T) {
+		content := &Content{Type: ContentTypeGallery, Views: 2000, Likes: 100, ImageCount: 20}
+
+		assert.Equal(t, 5.0, spec.Calculate(content))
+	})
+}
+
+func TestContentQualityRatioSpecification_Audio(t *testing.T) {
+	spec := NewContentQualityRatioSpecification()
+
+	assert.Equal(t, 0.5, spec.Calculate(&Content{Type: ContentTypeAudio, Listens: 2000, Likes: 100}))
+	assert.Equal(t, 0.0, spec.Calculate(&Content{Type: ContentTypeAudio, Likes: 100}))
+}
@@ -0,0 +1,403 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// MaxSearchDocumentDepth limits how deeply bool clauses may be nested
+const MaxSearchDocumentDepth = 8
+
+// SearchDocument is the JSON body accepted by POST /api/v1/search
+type SearchDocument struct {
+	Query    *QueryClause        `json:"query,omitempty"`
+	Sort     []map[string]string `json:"sort,omitempty"`
+	Page     int                 `json:"page,omitempty"`
+	PageSize int                 `json:"page_size,omitempty"`
+	Cursor   string              `json:"cursor,omitempty"`
+}
+
+// QueryClause holds exactly one query type
+type QueryClause struct {
+	Bool        *BoolClause            `json:"bool,omitempty"`
+	Match       map[string]string      `json:"match,omitempty"`
+	MatchPhrase map[string]string      `json:"match_phrase,omitempty"`
+	Term        map[string]string      `json:"term,omitempty"`
+	Terms       map[string][]string    `json:"terms,omitempty"`
+	Range       map[string]RangeBounds `json:"range,omitempty"`
+}
+
+// BoolClause combines clauses: every must and filter clause has to match, no must_not clause
+// may match, and at least minimum_should_match should clauses have to match. As in
+// Elasticsearch, minimum_should_match defaults to 1 when there are no must or filter clauses
+// and to 0 otherwise.
+type BoolClause struct {
+	Must               []QueryClause `json:"must,omitempty"`
+	Filter             []QueryClause `json:"filter,omitempty"`
+	Should             []QueryClause `json:"should,omitempty"`
+	MustNot            []QueryClause `json:"must_not,omitempty"`
+	MinimumShouldMatch *int          `json:"minimum_should_match,omitempty"`
+}
+
+// RangeBounds holds numbers for numeric fields and RFC3339 or YYYY-MM-DD strings for created_at
+type RangeBounds struct {
+	Gte interface{} `json:"gte,omitempty"`
+	Gt  interface{} `json:"gt,omitempty"`
+	Lte interface{} `json:"lte,omitempty"`
+	Lt  interface{} `json:"lt,omitempty"`
+}
+
+type SortField struct {
+	Field string
+	Order string
+}
+
+// DocumentSortFields maps the sort fields accepted in a search document onto columns
+var DocumentSortFields = map[string]string{
+	"score":          "score",
+	"trending_score": "trending_score",
+	"created_at":     "created_at",
+	"views":          "views",
+	"likes":          "likes",
+	"comments":       "comments",
+	"duration":       "duration",
+}
+
+var (
+	documentTextFields  = map[string]bool{"title": true}
+	documentTermFields  = map[string]string{"provider": QueryFieldProvider, "type": QueryFieldType, "tags": QueryFieldTag, "tag": QueryFieldTag}
+	documentRangeFields = map[string]bool{"views": true, "likes": true, "score": true, "created_at": true}
+)
+
+type SearchDocumentSpecification struct{}
+
+func NewSearchDocumentSpecification() *SearchDocumentSpecification {
+	return &SearchDocumentSpecification{}
+}
+
+// ToSearchRequest validates a search document and translates it into a search request whose
+// Filter is the query AST. Validation errors name the offending path, e.g. query.bool.must[1].range.
+func (s *SearchDocumentSpecification) ToSearchRequest(doc *SearchDocument) (*SearchRequest, error) {
+	req := &SearchRequest{
+		Page:     doc.Page,
+		PageSize: doc.PageSize,
+		Cursor:   doc.Cursor,
+	}
+
+	if doc.Query != nil {
+		node, err := s.compileClause(doc.Query, "query", 0)
+		if err != nil {
+			return nil, err
+		}
+		req.Filter = node
+	}
+
+	for i, clause := range doc.Sort {
+		field, err := s.compileSort(clause, fmt.Sprintf("sort[%d]", i))
+		if err != nil {
+			return nil, err
+		}
+		req.Sort = append(req.Sort, field)
+	}
+
+	return req, nil
+}
+
+func (s *SearchDocumentSpecification) compileClause(clause *QueryClause, path string, depth int) (QueryNode, error) {
+	if depth > MaxSearchDocumentDepth {
+		return nil, NewInvalidInputError(path, fmt.Sprintf("bool clauses may be nested at most %d levels deep", MaxSearchDocumentDepth))
+	}
+
+	kinds := s.clauseKinds(clause)
+	if len(kinds) != 1 {
+		return nil, NewInvalidInputError(path, "must contain exactly one of: bool, match, match_phrase, term, terms, range")
+	}
+
+	switch kinds[0] {
+	case "bool":
+		return s.compileBool(clause.Bool, path+".bool", depth)
+	case "match":
+		return s.compileMatch(clause.Match, path+".match", false)
+	case "match_phrase":
+		return s.compileMatch(clause.MatchPhrase, path+".match_phrase", true)
+	case "term":
+		return s.compileTerm(clause.Term, path+".term")
+	case "terms":
+		return s.compileTerms(clause.Terms, path+".terms")
+	default:
+		return s.compileRange(clause.Range, path+".range")
+	}
+}
+
+func (s *SearchDocumentSpecification) clauseKinds(clause *QueryClause) []string {
+	var kinds []string
+	if clause.Bool != nil {
+		kinds = append(kinds, "bool")
+	}
+	if clause.Match != nil {
+		kinds = append(kinds, "match")
+	}
+	if clause.MatchPhrase != nil {
+		kinds = append(kinds, "match_phrase")
+	}
+	if clause.Term != nil {
+		kinds = append(kinds, "term")
+	}
+	if clause.Terms != nil {
+		kinds = append(kinds, "terms")
+	}
+	if clause.Range != nil {
+		kinds = append(kinds, "range")
+	}
+	return kinds
+}
+
+func (s *SearchDocumentSpecification) compileBool(clause *BoolClause, path string, depth int) (QueryNode, error) {
+	var required []QueryNode
+
+	for _, group := range []struct {
+		name    string
+		clauses []QueryClause
+	}{{"must", clause.Must}, {"filter", clause.Filter}} {
+		nodes, err := s.compileClauses(group.clauses, path+"."+group.name, depth)
+		if err != nil {
+			return nil, err
+		}
+		required = append(required, nodes...)
+	}
+
+	mustNot, err := s.compileClauses(clause.MustNot, path+".must_not", depth)
+	if err != nil {
+		return nil, err
+	}
+	for _, node := range mustNot {
+		required = append(required, &NotNode{Child: node})
+	}
+
+	should, err := s.compileClauses(clause.Should, path+".should", depth)
+	if err != nil {
+		return nil, err
+	}
+
+	minimum := 0
+	if len(clause.Must) == 0 && len(clause.Filter) == 0 && len(should) > 0 {
+		minimum = 1
+	}
+	if clause.MinimumShouldMatch != nil {
+		minimum = *clause.MinimumShouldMatch
+		if minimum < 0 || minimum > len(should) {
+			return nil, NewInvalidInputError(path+".minimum_should_match", fmt.Sprintf("must be between 0 and %d", len(should)))
+		}
+	}
+
+	switch {
+	case minimum == 0:
+	case minimum == 1 && len(should) == 1:
+		required = append(required, should[0])
+	case minimum == 1:
+		required = append(required, &OrNode{Children: should})
+	case minimum == len(should):
+		required = append(required, should...)
+	default:
+		required = append(required, &MinimumMatchNode{Minimum: minimum, Children: should})
+	}
+
+	switch len(required) {
+	case 0:
+		return nil, nil
+	case 1:
+		return required[0], nil
+	default:
+		return &AndNode{Children: required}, nil
+	}
+}
+
+// compileClauses compiles a list of sub-clauses, dropping those that match everything
+func (s *SearchDocumentSpecification) compileClauses(clauses []QueryClause, path string, depth int) ([]QueryNode, error) {
+	nodes := make([]QueryNode, 0, len(clauses))
+	for i := range clauses {
+		node, err := s.compileClause(&clauses[i], fmt.Sprintf("%s[%d]", path, i), depth+1)
+		if err != nil {
+			return nil, err
+		}
+		if node != nil {
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes, nil
+}
+
+func (s *SearchDocumentSpecification) compileMatch(match map[string]string, path string, phrase bool) (QueryNode, error) {
+	field, value, err := s.singleField(match, path)
+	if err != nil {
+		return nil, err
+	}
+	if !documentTextFields[field] {
+		return nil, NewInvalidInputError(path, fmt.Sprintf("unknown text field %q, must be: title", field))
+	}
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, NewInvalidInputError(path+"."+field, "must not be empty")
+	}
+
+	if phrase {
+		return &TermNode{Field: QueryFieldTitle, Value: value, Phrase: true}, nil
+	}
+
+	words := strings.Fields(value)
+	if len(words) == 1 {
+		return &TermNode{Field: QueryFieldTitle, Value: words[0]}, nil
+	}
+	children := make([]QueryNode, 0, len(words))
+	for _, word := range words {
+		children = append(children, &TermNode{Field: QueryFieldTitle, Value: word})
+	}
+	return &AndNode{Children: children}, nil
+}
+
+func (s *SearchDocumentSpecification) compileTerm(term map[string]string, path string) (QueryNode, error) {
+	field, value, err := s.singleField(term, path)
+	if err != nil {
+		return nil, err
+	}
+	return s.termNode(field, value, path)
+}
+
+func (s *SearchDocumentSpecification) compileTerms(terms map[string][]string, path string) (QueryNode, error) {
+	if len(terms) != 1 {
+		return nil, NewInvalidInputError(path, "must name exactly one field")
+	}
+	for field, values := range terms {
+		if len(values) == 0 {
+			return nil, NewInvalidInputError(path+"."+field, "must list at least one value")
+		}
+		children := make([]QueryNode, 0, len(values))
+		for i, value := range values {
+			node, err := s.termNode(field, value, fmt.Sprintf("%s.%s[%d]", path, field, i))
+			if err != nil {
+				return nil, err
+			}
+			children = append(children, node)
+		}
+		if len(children) == 1 {
+			return children[0], nil
+		}
+		return &OrNode{Children: children}, nil
+	}
+	return nil, nil
+}
+
+func (s *SearchDocumentSpecification) termNode(field, value, path string) (QueryNode, error) {
+	queryField, ok := documentTermFields[field]
+	if !ok {
+		return nil, NewInvalidInputError(path, fmt.Sprintf("unknown term field %q, must be one of: provider, type, tags", field))
+	}
+	normalized, ok := normalizeFieldValue(queryField, strings.TrimSpace(value))
+	if !ok {
+		return nil, NewInvalidInputError(path, "type must be one of: video, text, audio, gallery")
+	}
+	if normalized == "" {
+		return nil, NewInvalidInputError(path, "must not be empty")
+	}
+	return &TermNode{Field: queryField, Value: normalized}, nil
+}
+
+func (s *SearchDocumentSpecification) compileRange(ranges map[string]RangeBounds, path string) (QueryNode, error) {
+	if len(ranges) != 1 {
+		return nil, NewInvalidInputError(path, "must name exactly one field")
+	}
+	for field, bounds := range ranges {
+		fieldPath := path + "." + field
+		if !documentRangeFields[field] {
+			return nil, NewInvalidInputError(path, fmt.Sprintf("unknown range field %q, must be one of: views, likes, score, created_at", field))
+		}
+		if bounds.Gte != nil && bounds.Gt != nil || bounds.Lte != nil && bounds.Lt != nil {
+			return nil, NewInvalidInputError(fieldPath, "cannot combine gte with gt or lte with lt")
+		}
+
+		node := &RangeNode{Field: field}
+		var err error
+		if bounds.Gte != nil {
+			node.Lower, err = s.rangeValue(field, bounds.Gte, fieldPath+".gte")
+			node.LowerInclusive = true
+		} else if bounds.Gt != nil {
+			node.Lower, err = s.rangeValue(field, bounds.Gt, fieldPath+".gt")
+		}
+		if err != nil {
+			return nil, err
+		}
+		if bounds.Lte != nil {
+			node.Upper, err = s.rangeValue(field, bounds.Lte, fieldPath+".lte")
+			node.UpperInclusive = true
+		} else if bounds.Lt != nil {
+			node.Upper, err = s.rangeValue(field, bounds.Lt, fieldPath+".lt")
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if node.Lower == nil && node.Upper == nil {
+			return nil, NewInvalidInputError(fieldPath, "must set at least one of: gte, gt, lte, lt")
+		}
+		if s.isInvertedRange(node) {
+			return nil, NewInvalidInputError(fieldPath, "lower bound must not exceed upper bound")
+		}
+		return node, nil
+	}
+	return nil, nil
+}
+
+func (s *SearchDocumentSpecification) rangeValue(field string, raw interface{}, path string) (interface{}, error) {
+	if field == "created_at" {
+		value, ok := raw.(string)
+		if !ok {
+			return nil, NewInvalidInputError(path, "must be an RFC3339 timestamp or YYYY-MM-DD date")
+		}
+		if t, ok := parseDate(value); ok {
+			return t, nil
+		}
+		return nil, NewInvalidInputError(path, "must be an RFC3339 timestamp or YYYY-MM-DD date")
+	}
+
+	value, ok := raw.(float64)
+	if !ok {
+		return nil, NewInvalidInputError(path, "must be a number")
+	}
+	return value, nil
+}
+
+func (s *SearchDocumentSpecification) isInvertedRange(node *RangeNode) bool {
+	if node.Lower == nil || node.Upper == nil {
+		return false
+	}
+	if lower, ok := node.Lower.(time.Time); ok {
+		return lower.After(node.Upper.(time.Time))
+	}
+	return node.Lower.(float64) > node.Upper.(float64)
+}
+
+func (s *SearchDocumentSpecification) compileSort(clause map[string]string, path string) (SortField, error) {
+	field, order, err := s.singleField(clause, path)
+	if err != nil {
+		return SortField{}, err
+	}
+	if _, ok := DocumentSortFields[field]; !ok {
+		return SortField{}, NewInvalidInputError(path, fmt.Sprintf("unknown sort field %q", field))
+	}
+	order = strings.ToLower(strings.TrimSpace(order))
+	if order != "asc" && order != "desc" {
+		return SortField{}, NewInvalidInputError(path+"."+field, "must be asc or desc")
+	}
+	return SortField{Field: field, Order: order}, nil
+}
+
+func (s *SearchDocumentSpecification) singleField(fields map[string]string, path string) (string, string, error) {
+	if len(fields) != 1 {
+		return "", "", NewInvalidInputError(path, "must name exactly one field")
+	}
+	for field, value := range fields {
+		return field, value, nil
+	}
+	return "", "", nil
+}
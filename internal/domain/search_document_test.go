@@ -0,0 +1,129 @@
+package domain
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func decodeSearchDocument(t *testing.T, body string) *SearchDocument {
+	var doc SearchDocument
+	require.NoError(t, json.Unmarshal([]byte(body), &doc))
+	return &doc
+}
+
+func TestSearchDocumentSpecification_ToSearchRequest(t *testing.T) {
+	spec := NewSearchDocumentSpecification()
+
+	tests := []struct {
+		name     string
+		body     string
+		expected string
+	}{
+		{"Match splits words", `{"query": {"match": {"title": "go concurrency"}}}`, "(go AND concurrency)"},
+		{"Match phrase", `{"query": {"match_phrase": {"title": "go concurrency"}}}`, `"go concurrency"`},
+		{"Term is normalized", `{"query": {"term": {"type": "VIDEO"}}}`, "type:video"},
+		{"Terms become OR", `{"query": {"terms": {"provider": ["provider1", "Provider2"]}}}`, "(provider:provider1 OR provider:provider2)"},
+		{"Range", `{"query": {"range": {"views": {"gte": 100, "lt": 1000}}}}`, "views:>=100 views:<1000"},
+		{
+			"Bool with must, filter and must_not",
+			`{"query": {"bool": {
+				"must": [{"match": {"title": "go"}}],
+				"filter": [{"term": {"type": "video"}}],
+				"must_not": [{"term": {"provider": "provider2"}}]
+			}}}`,
+			"(go AND type:video AND -provider:provider2)",
+		},
+		{
+			"Should alone requires one match",
+			`{"query": {"bool": {"should": [{"term": {"tags": "go"}}, {"term": {"tags": "rust"}}]}}}`,
+			"(tag:go OR tag:rust)",
+		},
+		{
+			"Should is optional next to must",
+			`{"query": {"bool": {"must": [{"match": {"title": "go"}}], "should": [{"term": {"tags": "rust"}}]}}}`,
+			"go",
+		},
+		{
+			"Minimum should match",
+			`{"query": {"bool": {"minimum_should_match": 2, "should": [
+				{"term": {"tags": "go"}}, {"term": {"tags": "rust"}}, {"term": {"tags": "zig"}}
+			]}}}`,
+			"2 of (tag:go, tag:rust, tag:zig)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := spec.ToSearchRequest(decodeSearchDocument(t, tt.body))
+
+			require.NoError(t, err)
+			require.NotNil(t, req.Filter)
+			assert.Equal(t, tt.expected, req.Filter.String())
+		})
+	}
+
+	t.Run("Sort and pagination", func(t *testing.T) {
+		req, err := spec.ToSearchRequest(decodeSearchDocument(t, `{"sort": [{"views": "DESC"}, {"created_at": "asc"}], "page": 2, "page_size": 5}`))
+
+		require.NoError(t, err)
+		assert.Nil(t, req.Filter)
+		assert.Equal(t, []SortField{{Field: "views", Order: "desc"}, {Field: "created_at", Order: "asc"}}, req.Sort)
+		assert.Equal(t, 2, req.Page)
+		assert.Equal(t, 5, req.PageSize)
+	})
+
+	t.Run("Date ranges parse dates", func(t *testing.T) {
+		req, err := spec.ToSearchRequest(decodeSearchDocument(t, `{"query": {"range": {"created_at": {"gte": "2024-01-01"}}}}`))
+
+		require.NoError(t, err)
+		assert.Equal(t, "created_at:>=2024-01-01T00:00:00Z", req.Filter.String())
+	})
+}
+
+func TestSearchDocumentSpecification_Errors(t *testing.T) {
+	spec := NewSearchDocumentSpecification()
+
+	tests := []struct {
+		name  string
+		body  string
+		field string
+	}{
+		{"Empty clause", `{"query": {}}`, "query"},
+		{"Two query types", `{"query": {"match": {"title": "go"}, "term": {"type": "video"}}}`, "query"},
+		{"Unknown text field", `{"query": {"match": {"provider": "go"}}}`, "query.match"},
+		{"Unknown term field", `{"query": {"term": {"title": "go"}}}`, "query.term"},
+		{"Invalid type", `{"query": {"bool": {"filter": [{"term": {"type": "podcast"}}]}}}`, "query.bool.filter[0].term"},
+		{"Non-numeric range", `{"query": {"bool": {"must": [{"match": {"title": "go"}}, {"range": {"views": {"gte": "many"}}}]}}}`, "query.bool.must[1].range.views.gte"},
+		{"Inverted range", `{"query": {"range": {"likes": {"gte": 10, "lte": 1}}}}`, "query.range.likes"},
+		{"Empty range", `{"query": {"range": {"score": {}}}}`, "query.range.score"},
+		{"Minimum should match too high", `{"query": {"bool": {"minimum_should_match": 2, "should": [{"term": {"tags": "go"}}]}}}`, "query.bool.minimum_should_match"},
+		{"Unknown sort field", `{"sort": [{"title": "asc"}]}`, "sort[0]"},
+		{"Invalid sort order", `{"sort": [{"views": "up"}]}`, "sort[0].views"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := spec.ToSearchRequest(decodeSearchDocument(t, tt.body))
+
+			require.Error(t, err)
+			domainErr, ok := err.(*DomainError)
+			require.True(t, ok)
+			assert.Equal(t, ErrorCodeInvalidInput, domainErr.Code)
+			assert.Equal(t, tt.field, domainErr.Details["field"])
+		})
+	}
+
+	t.Run("Nesting is limited", func(t *testing.T) {
+		clause := QueryClause{Term: map[string]string{"type": "video"}}
+		for i := 0; i <= MaxSearchDocumentDepth; i++ {
+			clause = QueryClause{Bool: &BoolClause{Must: []QueryClause{clause}}}
+		}
+
+		_, err := spec.ToSearchRequest(&SearchDocument{Query: &clause})
+
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,129 @@
+package domain
+
+import (
+	"strings"
+	"unicode"
+)
+
+// SpellingDictionary maps the words of indexed titles and tags to how often they occur
+type SpellingDictionary map[string]int
+
+// Add counts one more occurrence of every word of text
+func (d SpellingDictionary) Add(text string, occurrences int) {
+	for _, word := range titleTokens(text) {
+		d[word] += occurrences
+	}
+}
+
+// Remove takes back occurrences of the words of text counted by Add, dropping words that no
+// longer occur
+func (d SpellingDictionary) Remove(text string, occurrences int) {
+	for _, word := range titleTokens(text) {
+		d[word] -= occurrences
+		if d[word] <= 0 {
+			delete(d, word)
+		}
+	}
+}
+
+type SpellingSpecification struct {
+	fuzzy *FuzzySpecification
+}
+
+func NewSpellingSpecification() *SpellingSpecification {
+	return &SpellingSpecification{fuzzy: NewFuzzySpecification()}
+}
+
+// CorrectQuery replaces every word of the query that is not in the dictionary with its closest
+// dictionary word, keeping operators, quotes, negation and field qualifiers intact. Only bare
+// words and title: and tag: values are corrected. It returns "" when nothing was corrected.
+func (s *SpellingSpecification) CorrectQuery(query string, dictionary SpellingDictionary) string {
+	chunks := strings.Fields(query)
+	changed := false
+	for i, chunk := range chunks {
+		if corrected, ok := s.correctChunk(chunk, dictionary); ok {
+			chunks[i] = corrected
+			changed = true
+		}
+	}
+	if !changed {
+		return ""
+	}
+	return strings.Join(chunks, " ")
+}
+
+func (s *SpellingSpecification) correctChunk(chunk string, dictionary SpellingDictionary) (string, bool) {
+	if chunk == "AND" || chunk == "OR" || chunk == "NOT" {
+		return chunk, false
+	}
+
+	start := strings.IndexFunc(chunk, func(r rune) bool { return !strings.ContainsRune(`-("`, r) })
+	if start < 0 {
+		return chunk, false
+	}
+	end := strings.LastIndexFunc(chunk, func(r rune) bool { return !strings.ContainsRune(`)"`, r) }) + 1
+	prefix, core, suffix := chunk[:start], chunk[start:end], chunk[end:]
+
+	if field, value, qualified := strings.Cut(core, ":"); qualified {
+		field = strings.ToLower(field)
+		if field != QueryFieldTitle && field != QueryFieldTag {
+			return chunk, false
+		}
+		valueStart := strings.IndexFunc(value, func(r rune) bool { return r != '"' })
+		if valueStart < 0 {
+			return chunk, false
+		}
+		corrected, ok := s.CorrectWord(value[valueStart:], dictionary)
+		if !ok {
+			return chunk, false
+		}
+		return prefix + core[:len(core)-len(value)] + value[:valueStart] + corrected + suffix, true
+	}
+
+	corrected, ok := s.CorrectWord(core, dictionary)
+	if !ok {
+		return chunk, false
+	}
+	return prefix + corrected + suffix, true
+}
+
+// CorrectWord returns the dictionary word closest to an unknown word. Candidates within the
+// auto fuzzy edit distance are ranked by edit distance, then by frequency.
+func (s *SpellingSpecification) CorrectWord(word string, dictionary SpellingDictionary) (string, bool) {
+	word = strings.ToLower(word)
+	if !s.isCorrectable(word) || dictionary[word] > 0 {
+		return word, false
+	}
+
+	edits := s.fuzzy.MaxEdits(FuzzyAuto, word)
+	if edits == 0 {
+		return word, false
+	}
+
+	best, bestDistance, bestFrequency := "", edits+1, 0
+	for candidate, frequency := range dictionary {
+		if !s.fuzzy.IsMatch(word, candidate, edits) {
+			continue
+		}
+		distance := Levenshtein(word, candidate)
+		if distance < bestDistance ||
+			distance == bestDistance && frequency > bestFrequency ||
+			distance == bestDistance && frequency == bestFrequency && candidate < best {
+			best, bestDistance, bestFrequency = candidate, distance, frequency
+		}
+	}
+	return best, best != ""
+}
+
+// isCorrectable accepts plain words that are not stopwords
+func (s *SpellingSpecification) isCorrectable(word string) bool {
+	if word == "" || titleStopwords[word] {
+		return false
+	}
+	for _, r := range word {
+		if !unicode.IsLetter(r) && !unicode.IsNumber(r) {
+			return false
+		}
+	}
+	return true
+}
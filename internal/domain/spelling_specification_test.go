@@ -0,0 +1,83 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSpellingDictionary_Add(t *testing.T) {
+	dictionary := make(SpellingDictionary)
+	dictionary.Add("The Kubernetes Handbook", 1)
+	dictionary.Add("best-practices", 3)
+
+	assert.Equal(t, SpellingDictionary{"kubernetes": 1, "handbook": 1, "best": 3, "practices": 3}, dictionary)
+}
+
+func TestSpellingDictionary_Remove(t *testing.T) {
+	dictionary := make(SpellingDictionary)
+	dictionary.Add("Kubernetes Handbook", 1)
+	dictionary.Add("kubernetes", 2)
+
+	dictionary.Remove("The Kubernetes Handbook", 1)
+
+	assert.Equal(t, SpellingDictionary{"kubernetes": 2}, dictionary)
+}
+
+func TestSpellingSpecification_CorrectWord(t *testing.T) {
+	spec := NewSpellingSpecification()
+	dictionary := SpellingDictionary{"kubernetes": 4, "golang": 2, "goland": 9, "rust": 3, "docker": 1}
+
+	tests := []struct {
+		name     string
+		word     string
+		expected string
+		ok       bool
+	}{
+		{"Known word is kept", "rust", "rust", false},
+		{"Missing letter", "kubernets", "kubernetes", true},
+		{"Case is ignored", "Kubernets", "kubernetes", true},
+		{"Closest distance wins over frequency", "golangg", "golang", true},
+		{"Frequency breaks distance ties", "golanx", "goland", true},
+		{"Too far from any word", "python", "python", false},
+		{"Short words are not corrected", "go", "go", false},
+		{"Stopwords are not corrected", "the", "the", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			corrected, ok := spec.CorrectWord(tt.word, dictionary)
+
+			assert.Equal(t, tt.ok, ok)
+			if ok {
+				assert.Equal(t, tt.expected, corrected)
+			}
+		})
+	}
+}
+
+func TestSpellingSpecification_CorrectQuery(t *testing.T) {
+	spec := NewSpellingSpecification()
+	dictionary := SpellingDictionary{"kubernetes": 4, "operators": 2, "docker": 3, "video": 5}
+
+	tests := []struct {
+		name     string
+		query    string
+		expected string
+	}{
+		{"Single word", "kubernets", "kubernetes"},
+		{"Several words", "kubernets operatrs", "kubernetes operators"},
+		{"Operators and negation are kept", "kubernets AND -dockr", "kubernetes AND -docker"},
+		{"Quotes and parentheses are kept", `("kubernets operatrs") OR dockr`, `("kubernetes operators") OR docker`},
+		{"Title and tag qualifiers", `title:"kubernets" tag:dockr`, `title:"kubernetes" tag:docker`},
+		{"Other qualifiers are left alone", "type:vide kubernets", "type:vide kubernetes"},
+		{"Nothing to correct", "kubernetes docker", ""},
+		{"Nothing correctable", "zzzzzz", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, spec.CorrectQuery(tt.query, dictionary))
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package domain
+
+import (
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	DefaultSuggestLimit = 10
+	MaxSuggestLimit     = 50
+	MaxSuggestPrefix    = 100
+)
+
+// SearchQueryLog counts how often a query was searched; popular queries feed autocomplete
+type SearchQueryLog struct {
+	Query          string    `gorm:"primaryKey;type:varchar(255)"`
+	Count          int       `gorm:"not null;default:0"`
+	LastSearchedAt time.Time `gorm:"not null"`
+}
+
+func (SearchQueryLog) TableName() string {
+	return "search_queries"
+}
+
+type SuggestRequest struct {
+	Prefix      string       `form:"prefix"`
+	ContentType *ContentType `form:"type"`
+	Limit       int          `form:"limit"`
+}
+
+// Suggestion is one completion; Weight is the content score for titles and the search count for queries
+type Suggestion struct {
+	Text   string      `json:"text"`
+	Type   ContentType `json:"type,omitempty"`
+	Weight float64     `json:"weight"`
+}
+
+type SuggestResponse struct {
+	Prefix  string        `json:"prefix"`
+	Titles  []*Suggestion `json:"titles"`
+	Queries []*Suggestion `json:"queries"`
+}
+
+type SuggestSpecification struct{}
+
+func NewSuggestSpecification() *SuggestSpecification {
+	return &SuggestSpecification{}
+}
+
+// NormalizeSuggestRequest trims the prefix, validates the type filter and applies the limit defaults
+func (s *SuggestSpecification) NormalizeSuggestRequest(req *SuggestRequest) error {
+	req.Prefix = strings.TrimSpace(req.Prefix)
+	if req.Prefix == "" {
+		return NewInvalidInputError("prefix", "is required")
+	}
+	if utf8.RuneCountInString(req.Prefix) > MaxSuggestPrefix {
+		return NewInvalidInputError("prefix", "must be at most 100 characters")
+	}
+
+	if req.ContentType != nil && *req.ContentType == "" {
+		req.ContentType = nil
+	}
+	if req.ContentType != nil && !req.ContentType.IsValid() {
+		return NewInvalidInputError("type", "must be one of: video, text, audio, gallery")
+	}
+
+	if req.Limit <= 0 {
+		req.Limit = DefaultSuggestLimit
+	}
+	if req.Limit > MaxSuggestLimit {
+		req.Limit = MaxSuggestLimit
+	}
+	return nil
+}
+
+// SuggestKey normalizes text for prefix matching: lowercased with runs of whitespace collapsed
+func SuggestKey(text string) string {
+	return strings.Join(strings.Fields(strings.ToLower(text)), " ")
+}
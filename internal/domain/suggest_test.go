@@ -0,0 +1,57 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSuggestSpecification_NormalizeSuggestRequest(t *testing.T) {
+	spec := NewSuggestSpecification()
+
+	t.Run("Applies defaults", func(t *testing.T) {
+		emptyType := ContentType("")
+		req := &SuggestRequest{Prefix: "  kube ", ContentType: &emptyType}
+
+		require.NoError(t, spec.NormalizeSuggestRequest(req))
+		assert.Equal(t, "kube", req.Prefix)
+		assert.Nil(t, req.ContentType)
+		assert.Equal(t, DefaultSuggestLimit, req.Limit)
+	})
+
+	t.Run("Caps the limit", func(t *testing.T) {
+		req := &SuggestRequest{Prefix: "go", Limit: 500}
+
+		require.NoError(t, spec.NormalizeSuggestRequest(req))
+		assert.Equal(t, MaxSuggestLimit, req.Limit)
+	})
+
+	podcast := ContentType("podcast")
+	tests := []struct {
+		name  string
+		req   *SuggestRequest
+		field string
+	}{
+		{"Missing prefix", &SuggestRequest{Prefix: "   "}, "prefix"},
+		{"Prefix too long", &SuggestRequest{Prefix: strings.Repeat("a", MaxSuggestPrefix+1)}, "prefix"},
+		{"Invalid type", &SuggestRequest{Prefix: "go", ContentType: &podcast}, "type"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := spec.NormalizeSuggestRequest(tt.req)
+
+			require.Error(t, err)
+			domainErr, ok := err.(*DomainError)
+			require.True(t, ok)
+			assert.Equal(t, tt.field, domainErr.Details["field"])
+		})
+	}
+}
+
+func TestSuggestKey(t *testing.T) {
+	assert.Equal(t, "intro to kubernetes", SuggestKey("  Intro   to\tKubernetes "))
+	assert.Equal(t, "", SuggestKey("   "))
+}
@@ -0,0 +1,190 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+const (
+	maxSynonymSetNameLength = 100
+	maxSynonymTermLength    = 100
+)
+
+// SynonymRule either makes all its terms interchangeable (an equivalence, "k8s, kubernetes")
+// or, when Synonyms is set, expands each term to the synonyms but not the other way round
+// (one-way, "js => javascript"). Terms of several words match quoted phrases.
+type SynonymRule struct {
+	Terms    []string `json:"terms"`
+	Synonyms []string `json:"synonyms,omitempty"`
+}
+
+// SynonymSet is a named group of synonym rules managed through the admin API
+type SynonymSet struct {
+	ID        int64         `json:"id" gorm:"primaryKey;autoIncrement"`
+	Name      string        `json:"name" gorm:"type:varchar(100);not null;uniqueIndex"`
+	Rules     []SynonymRule `json:"rules" gorm:"type:text;not null;serializer:json"`
+	CreatedAt time.Time     `json:"created_at"`
+	UpdatedAt time.Time     `json:"updated_at"`
+}
+
+func (SynonymSet) TableName() string {
+	return "synonym_sets"
+}
+
+type SynonymSpecification struct{}
+
+func NewSynonymSpecification() *SynonymSpecification {
+	return &SynonymSpecification{}
+}
+
+// NormalizeSynonymSet trims the name, lowercases every term and checks that each rule can
+// expand something: an equivalence needs two terms, a one-way rule a term and a synonym
+func (s *SynonymSpecification) NormalizeSynonymSet(set *SynonymSet) error {
+	set.Name = strings.TrimSpace(set.Name)
+	if set.Name == "" {
+		return NewInvalidInputError("name", "is required")
+	}
+	if len(set.Name) > maxSynonymSetNameLength {
+		return NewInvalidInputError("name", "must be at most 100 characters")
+	}
+	if len(set.Rules) == 0 {
+		return NewInvalidInputError("rules", "must contain at least one rule")
+	}
+
+	for i := range set.Rules {
+		rule := &set.Rules[i]
+		var err error
+		if rule.Terms, err = s.normalizeTerms(rule.Terms); err != nil {
+			return err
+		}
+		if rule.Synonyms, err = s.normalizeTerms(rule.Synonyms); err != nil {
+			return err
+		}
+		if len(rule.Synonyms) == 0 && len(rule.Terms) < 2 {
+			return NewInvalidInputError("rules", "an equivalence rule needs at least two terms")
+		}
+		if len(rule.Synonyms) > 0 && len(rule.Terms) == 0 {
+			return NewInvalidInputError("rules", "a one-way rule needs at least one term")
+		}
+	}
+	return nil
+}
+
+func (s *SynonymSpecification) normalizeTerms(terms []string) ([]string, error) {
+	normalized := make([]string, 0, len(terms))
+	seen := make(map[string]bool, len(terms))
+	for _, term := range terms {
+		term = SynonymKey(term)
+		if term == "" {
+			return nil, NewInvalidInputError("rules", "terms cannot be empty")
+		}
+		if len(term) > maxSynonymTermLength {
+			return nil, NewInvalidInputError("rules", "terms must be at most 100 characters")
+		}
+		if !seen[term] {
+			seen[term] = true
+			normalized = append(normalized, term)
+		}
+	}
+	return normalized, nil
+}
+
+// SynonymKey is the form terms are looked up by: lowercase with single spaces
+func SynonymKey(term string) string {
+	return strings.Join(strings.Fields(strings.ToLower(term)), " ")
+}
+
+// SynonymDictionary maps each term to the terms a query for it should also match
+type SynonymDictionary struct {
+	expansions map[string][]string
+}
+
+func NewSynonymDictionary(sets []*SynonymSet) *SynonymDictionary {
+	d := &SynonymDictionary{expansions: make(map[string][]string)}
+	for _, set := range sets {
+		for _, rule := range set.Rules {
+			if len(rule.Synonyms) > 0 {
+				for _, term := range rule.Terms {
+					d.add(term, rule.Synonyms)
+				}
+				continue
+			}
+			for _, term := range rule.Terms {
+				d.add(term, rule.Terms)
+			}
+		}
+	}
+	return d
+}
+
+func (d *SynonymDictionary) add(term string, synonyms []string) {
+	key := SynonymKey(term)
+	for _, synonym := range synonyms {
+		synonym = SynonymKey(synonym)
+		if synonym == key || synonym == "" {
+			continue
+		}
+		duplicate := false
+		for _, existing := range d.expansions[key] {
+			if existing == synonym {
+				duplicate = true
+				break
+			}
+		}
+		if !duplicate {
+			d.expansions[key] = append(d.expansions[key], synonym)
+		}
+	}
+}
+
+// Expansions returns the synonyms of a term, not including the term itself
+func (d *SynonymDictionary) Expansions(term string) []string {
+	return d.expansions[SynonymKey(term)]
+}
+
+// Len returns the number of terms that have synonyms
+func (d *SynonymDictionary) Len() int {
+	return len(d.expansions)
+}
+
+// Expand returns a copy of a parsed query in which every title term with synonyms matches
+// either the term or one of its synonyms. Synonyms are not expanded in turn.
+func (d *SynonymDictionary) Expand(node QueryNode) QueryNode {
+	switch n := node.(type) {
+	case *TermNode:
+		if n.Field != QueryFieldTitle {
+			return n
+		}
+		synonyms := d.Expansions(n.Value)
+		if len(synonyms) == 0 {
+			return n
+		}
+		children := []QueryNode{n}
+		for _, synonym := range synonyms {
+			children = append(children, &TermNode{
+				Field:  QueryFieldTitle,
+				Value:  synonym,
+				Phrase: strings.Contains(synonym, " "),
+			})
+		}
+		return &OrNode{Children: children}
+	case *AndNode:
+		return &AndNode{Children: d.expandAll(n.Children)}
+	case *OrNode:
+		return &OrNode{Children: d.expandAll(n.Children)}
+	case *MinimumMatchNode:
+		return &MinimumMatchNode{Minimum: n.Minimum, Children: d.expandAll(n.Children)}
+	case *NotNode:
+		return &NotNode{Child: d.Expand(n.Child)}
+	default:
+		return node
+	}
+}
+
+func (d *SynonymDictionary) expandAll(nodes []QueryNode) []QueryNode {
+	expanded := make([]QueryNode, 0, len(nodes))
+	for _, node := range nodes {
+		expanded = append(expanded, d.Expand(node))
+	}
+	return expanded
+}
@@ -0,0 +1,121 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSynonymSpecification_NormalizeSynonymSet(t *testing.T) {
+	spec := NewSynonymSpecification()
+
+	t.Run("Normalizes terms", func(t *testing.T) {
+		set := &SynonymSet{
+			Name: "  infra ",
+			Rules: []SynonymRule{
+				{Terms: []string{"K8s", " Kubernetes ", "k8s"}},
+				{Terms: []string{"JS"}, Synonyms: []string{"JavaScript", "ECMA   Script"}},
+			},
+		}
+
+		require.NoError(t, spec.NormalizeSynonymSet(set))
+		assert.Equal(t, "infra", set.Name)
+		assert.Equal(t, []string{"k8s", "kubernetes"}, set.Rules[0].Terms)
+		assert.Equal(t, []string{"js"}, set.Rules[1].Terms)
+		assert.Equal(t, []string{"javascript", "ecma script"}, set.Rules[1].Synonyms)
+	})
+
+	tests := []struct {
+		name  string
+		set   *SynonymSet
+		field string
+	}{
+		{"Missing name", &SynonymSet{Rules: []SynonymRule{{Terms: []string{"a", "b"}}}}, "name"},
+		{"Long name", &SynonymSet{Name: strings.Repeat("x", 101), Rules: []SynonymRule{{Terms: []string{"a", "b"}}}}, "name"},
+		{"No rules", &SynonymSet{Name: "set"}, "rules"},
+		{"Empty term", &SynonymSet{Name: "set", Rules: []SynonymRule{{Terms: []string{"a", " "}}}}, "rules"},
+		{"Single equivalent term", &SynonymSet{Name: "set", Rules: []SynonymRule{{Terms: []string{"a", "A"}}}}, "rules"},
+		{"One-way rule without terms", &SynonymSet{Name: "set", Rules: []SynonymRule{{Synonyms: []string{"a"}}}}, "rules"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := spec.NormalizeSynonymSet(tt.set)
+
+			var domainErr *DomainError
+			require.ErrorAs(t, err, &domainErr)
+			assert.Equal(t, ErrorCodeInvalidInput, domainErr.Code)
+			assert.Equal(t, tt.field, domainErr.Details["field"])
+		})
+	}
+}
+
+func TestSynonymDictionary(t *testing.T) {
+	dictionary := NewSynonymDictionary([]*SynonymSet{
+		{Rules: []SynonymRule{
+			{Terms: []string{"k8s", "kubernetes"}},
+			{Terms: []string{"js"}, Synonyms: []string{"javascript"}},
+			{Terms: []string{"ml"}, Synonyms: []string{"machine learning"}},
+		}},
+		{Rules: []SynonymRule{
+			{Terms: []string{"kubernetes", "kube"}},
+		}},
+	})
+
+	t.Run("Expansions", func(t *testing.T) {
+		assert.Equal(t, []string{"kubernetes"}, dictionary.Expansions("K8s"))
+		assert.Equal(t, []string{"k8s", "kube"}, dictionary.Expansions("kubernetes"))
+		assert.Equal(t, []string{"javascript"}, dictionary.Expansions("js"))
+		assert.Empty(t, dictionary.Expansions("javascript"))
+		assert.Equal(t, 5, dictionary.Len())
+	})
+
+	tests := []struct {
+		name     string
+		query    string
+		expected string
+	}{
+		{"Equivalence", "k8s", "(k8s OR kubernetes)"},
+		{"One-way", "javascript", "javascript"},
+		{"Multi-word synonym becomes a phrase", "ml", `(ml OR "machine learning")`},
+		{"Nested", `js -k8s tag:k8s`, "((js OR javascript) AND -(k8s OR kubernetes) AND tag:k8s)"},
+		{"Not transitive", "kube", "(kube OR kubernetes)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node, err := ParseQuery(tt.query)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.expected, dictionary.Expand(node).String())
+		})
+	}
+
+	t.Run("Minimum match", func(t *testing.T) {
+		node := &MinimumMatchNode{Minimum: 1, Children: []QueryNode{
+			&TermNode{Field: QueryFieldTitle, Value: "js"},
+			&TermNode{Field: QueryFieldTitle, Value: "go"},
+		}}
+
+		assert.Equal(t, "1 of ((js OR javascript), go)", dictionary.Expand(node).String())
+	})
+
+	t.Run("Does not modify the query", func(t *testing.T) {
+		node, err := ParseQuery("k8s go")
+		require.NoError(t, err)
+
+		dictionary.Expand(node)
+		assert.Equal(t, "(k8s AND go)", node.String())
+	})
+}
+
+func TestSearchQuery(t *testing.T) {
+	node, err := SearchQuery(&SearchRequest{Query: "go"})
+	require.NoError(t, err)
+	assert.Equal(t, "go", node.String())
+
+	expanded := &OrNode{Children: []QueryNode{&TermNode{Field: QueryFieldTitle, Value: "go"}, &TermNode{Field: QueryFieldTitle, Value: "golang"}}}
+	node, err = SearchQuery(&SearchRequest{Query: "go", AnalyzedQuery: expanded})
+	require.NoError(t, err)
+	assert.Equal(t, expanded, node)
+}
@@ -0,0 +1,66 @@
+package domain
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+const (
+	TagModeAny = "any"
+	TagModeAll = "all"
+)
+
+type Tag struct {
+	ID   int64  `json:"-" gorm:"primaryKey;autoIncrement"`
+	Name string `json:"name" gorm:"type:varchar(100);not null;uniqueIndex"`
+}
+
+func (Tag) TableName() string {
+	return "tags"
+}
+
+// MarshalJSON renders a tag as its bare name so API consumers get a flat list of strings
+func (t Tag) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.Name)
+}
+
+// UnmarshalJSON accepts the bare name produced by MarshalJSON
+func (t *Tag) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err != nil {
+		return err
+	}
+	t.Name = name
+	return nil
+}
+
+// NormalizeTagName lowercases a tag and collapses whitespace and underscores into single dashes
+func NormalizeTagName(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	name = strings.ReplaceAll(name, "_", " ")
+	return strings.Join(strings.Fields(name), "-")
+}
+
+// NewTags normalizes raw provider tags, dropping empty values and duplicates
+func NewTags(names ...string) []Tag {
+	tags := make([]Tag, 0, len(names))
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		normalized := NormalizeTagName(name)
+		if normalized == "" || seen[normalized] {
+			continue
+		}
+		seen[normalized] = true
+		tags = append(tags, Tag{Name: normalized})
+	}
+	return tags
+}
+
+// TagNames returns the names of the given tags in order
+func TagNames(tags []Tag) []string {
+	names := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		names = append(names, tag.Name)
+	}
+	return names
+}
@@ -0,0 +1,38 @@
+package domain
+
+import (
+	"strings"
+)
+
+type TagFilterSpecification struct{}
+
+func NewTagFilterSpecification() *TagFilterSpecification {
+	return &TagFilterSpecification{}
+}
+
+// NormalizeTagFilter splits comma-separated tag values, normalizes each tag and
+// defaults the match mode to "any"
+func (s *TagFilterSpecification) NormalizeTagFilter(req *SearchRequest) error {
+	var raw []string
+	for _, value := range req.Tags {
+		raw = append(raw, strings.Split(value, ",")...)
+	}
+	req.Tags = TagNames(NewTags(raw...))
+
+	req.TagMode = strings.ToLower(strings.TrimSpace(req.TagMode))
+	if s.isEmptyMode(req.TagMode) {
+		req.TagMode = TagModeAny
+	}
+	if !s.isValidMode(req.TagMode) {
+		return NewInvalidInputError("tag_mode", "must be one of: any, all")
+	}
+	return nil
+}
+
+func (s *TagFilterSpecification) isEmptyMode(mode string) bool {
+	return mode == ""
+}
+
+func (s *TagFilterSpecification) isValidMode(mode string) bool {
+	return mode == TagModeAny || mode == TagModeAll
+}
@@ -0,0 +1,45 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewTags(t *testing.T) {
+	tags := NewTags("DevOps", " devops ", "Best Practices", "ci_cd", "")
+
+	assert.Equal(t, []string{"devops", "best-practices", "ci-cd"}, TagNames(tags))
+}
+
+func TestTagFilterSpecification_NormalizeTagFilter(t *testing.T) {
+	spec := NewTagFilterSpecification()
+
+	t.Run("Splits comma separated tags", func(t *testing.T) {
+		req := &SearchRequest{Tags: []string{"devops,Containers", "kubernetes"}}
+
+		err := spec.NormalizeTagFilter(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"devops", "containers", "kubernetes"}, req.Tags)
+		assert.Equal(t, TagModeAny, req.TagMode)
+	})
+
+	t.Run("Accepts all mode", func(t *testing.T) {
+		req := &SearchRequest{Tags: []string{"devops"}, TagMode: "ALL"}
+
+		err := spec.NormalizeTagFilter(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, TagModeAll, req.TagMode)
+	})
+
+	t.Run("Rejects unknown mode", func(t *testing.T) {
+		req := &SearchRequest{Tags: []string{"devops"}, TagMode: "some"}
+
+		err := spec.NormalizeTagFilter(req)
+
+		assert.Error(t, err)
+		assert.True(t, IsInvalidInputError(err))
+	})
+}
@@ -0,0 +1,345 @@
+package domain
+
+import (
+	"strings"
+)
+
+// builtinStopwords are the words the analysis of each language drops from titles and queries:
+// the articles, as titleTokens drops for English. PostgreSQL drops its own, longer lists.
+var builtinStopwords = map[string]map[string]bool{
+	LanguageEnglish: titleStopwords,
+	LanguageGerman:  wordSet("der", "die", "das", "ein", "eine"),
+	LanguageTurkish: wordSet("bir"),
+}
+
+var defaultTextAnalyzer = &TextAnalyzer{stopwords: builtinStopwords}
+
+// TextAnalyzer turns text into index terms per language: lowercased words and numbers without
+// stopwords, each reduced to its stem
+type TextAnalyzer struct {
+	stopwords map[string]map[string]bool
+}
+
+// NewTextAnalyzer creates an analyzer dropping custom stopwords, keyed by language, on top of
+// the built-in ones
+func NewTextAnalyzer(custom map[string][]string) (*TextAnalyzer, error) {
+	stopwords := make(map[string]map[string]bool, len(Languages))
+	for _, language := range Languages {
+		stopwords[language] = make(map[string]bool)
+		for word := range builtinStopwords[language] {
+			stopwords[language][word] = true
+		}
+	}
+	for code, words := range custom {
+		language, ok := ParseLanguage(code)
+		if !ok {
+			return nil, NewInvalidInputError("stopwords", "unsupported language "+code)
+		}
+		for _, word := range words {
+			if word = strings.ToLower(strings.TrimSpace(word)); word != "" {
+				stopwords[language][word] = true
+			}
+		}
+	}
+	return &TextAnalyzer{stopwords: stopwords}, nil
+}
+
+// Analyze splits text in the given language into index terms
+func (a *TextAnalyzer) Analyze(text, language string) []string {
+	tokens := textTokens(text)
+	terms := tokens[:0]
+	for _, token := range tokens {
+		if !a.IsStopword(token, language) {
+			terms = append(terms, StemIn(token, language))
+		}
+	}
+	return terms
+}
+
+// IsStopword reports whether a word is dropped from text in the given language
+func (a *TextAnalyzer) IsStopword(word, language string) bool {
+	return a.stopwords[language][strings.ToLower(word)]
+}
+
+// RemoveStopwords returns a copy of a parsed query without the title terms that are stopwords
+// in the given language. Phrases are kept whole, and a query of stopwords only is returned
+// unchanged rather than matching everything.
+func (a *TextAnalyzer) RemoveStopwords(node QueryNode, language string) QueryNode {
+	if node == nil {
+		return nil
+	}
+	if stripped := a.removeStopwords(node, language); stripped != nil {
+		return stripped
+	}
+	return node
+}
+
+func (a *TextAnalyzer) removeStopwords(node QueryNode, language string) QueryNode {
+	switch n := node.(type) {
+	case *TermNode:
+		if n.Field == QueryFieldTitle && !n.Phrase && a.IsStopword(n.Value, language) {
+			return nil
+		}
+		return n
+	case *AndNode:
+		if children := a.removeAll(n.Children, language); len(children) > 0 {
+			return &AndNode{Children: children}
+		}
+		return nil
+	case *OrNode:
+		if children := a.removeAll(n.Children, language); len(children) > 0 {
+			return &OrNode{Children: children}
+		}
+		return nil
+	case *NotNode:
+		if child := a.removeStopwords(n.Child, language); child != nil {
+			return &NotNode{Child: child}
+		}
+		return nil
+	default:
+		// Minimum-match groups keep every clause so that their minimum keeps its meaning
+		return node
+	}
+}
+
+func (a *TextAnalyzer) removeAll(nodes []QueryNode, language string) []QueryNode {
+	kept := make([]QueryNode, 0, len(nodes))
+	for _, node := range nodes {
+		if child := a.removeStopwords(node, language); child != nil {
+			kept = append(kept, child)
+		}
+	}
+	return kept
+}
+
+// AnalyzeText splits English text into the terms a search index stores: lowercased words and
+// numbers without articles, each reduced to its stem so that "tutorials" and "tutorial" match
+func AnalyzeText(text string) []string {
+	return defaultTextAnalyzer.Analyze(text, LanguageEnglish)
+}
+
+// AnalyzeTextIn splits text in the given language into index terms with the built-in stopwords
+func AnalyzeTextIn(text, language string) []string {
+	return defaultTextAnalyzer.Analyze(text, language)
+}
+
+// StemIn reduces a lowercase word to its stem in the given language. Languages without a
+// stemmer keep words unchanged.
+func StemIn(word, language string) string {
+	switch language {
+	case LanguageEnglish:
+		return Stem(word)
+	case LanguageGerman:
+		return stemGerman(word)
+	case LanguageTurkish:
+		return stemTurkish(word)
+	}
+	return word
+}
+
+// SearchQuery returns the parsed query of a search, after stopword removal and synonym
+// expansion when the service has analyzed it
+func SearchQuery(req *SearchRequest) (QueryNode, error) {
+	if req.AnalyzedQuery != nil {
+		return req.AnalyzedQuery, nil
+	}
+	return ParseQuery(req.Query)
+}
+
+// Stem reduces a lowercase English word to its stem with the Porter algorithm. Words with
+// non-ASCII letters or digits and words of two letters or fewer are returned unchanged.
+func Stem(word string) string {
+	if len(word) <= 2 {
+		return word
+	}
+	for i := 0; i < len(word); i++ {
+		if word[i] < 'a' || word[i] > 'z' {
+			return word
+		}
+	}
+
+	w := []byte(word)
+	w = stemStep1a(w)
+	w = stemStep1b(w)
+	w = stemStep1c(w)
+	w = replaceSuffixes(w, 0, step2Suffixes)
+	w = replaceSuffixes(w, 0, step3Suffixes)
+	w = stemStep4(w)
+	w = stemStep5(w)
+	return string(w)
+}
+
+type suffixRule struct {
+	suffix      string
+	replacement string
+}
+
+var step2Suffixes = []suffixRule{
+	{"ational", "ate"}, {"tional", "tion"}, {"enci", "ence"}, {"anci", "ance"}, {"izer", "ize"},
+	{"abli", "able"}, {"alli", "al"}, {"entli", "ent"}, {"eli", "e"}, {"ousli", "ous"},
+	{"ization", "ize"}, {"ation", "ate"}, {"ator", "ate"}, {"alism", "al"}, {"iveness", "ive"},
+	{"fulness", "ful"}, {"ousness", "ous"}, {"aliti", "al"}, {"iviti", "ive"}, {"biliti", "ble"},
+}
+
+var step3Suffixes = []suffixRule{
+	{"icate", "ic"}, {"ative", ""}, {"alize", "al"}, {"iciti", "ic"}, {"ical", "ic"},
+	{"ful", ""}, {"ness", ""},
+}
+
+// step4Suffixes lists longer suffixes before the shorter ones they end with
+var step4Suffixes = []string{
+	"al", "ance", "ence", "er", "ic", "able", "ible", "ant", "ement", "ment", "ent",
+	"ion", "ou", "ism", "ate", "iti", "ous", "ive", "ize",
+}
+
+func stemStep1a(w []byte) []byte {
+	switch {
+	case hasSuffix(w, "sses"), hasSuffix(w, "ies"):
+		return w[:len(w)-2]
+	case hasSuffix(w, "ss"):
+		return w
+	case hasSuffix(w, "s"):
+		return w[:len(w)-1]
+	}
+	return w
+}
+
+func stemStep1b(w []byte) []byte {
+	if hasSuffix(w, "eed") {
+		if measure(w[:len(w)-3]) > 0 {
+			return w[:len(w)-1]
+		}
+		return w
+	}
+
+	var stem []byte
+	switch {
+	case hasSuffix(w, "ed") && containsVowel(w[:len(w)-2]):
+		stem = w[:len(w)-2]
+	case hasSuffix(w, "ing") && containsVowel(w[:len(w)-3]):
+		stem = w[:len(w)-3]
+	default:
+		return w
+	}
+
+	switch {
+	case hasSuffix(stem, "at"), hasSuffix(stem, "bl"), hasSuffix(stem, "iz"):
+		return append(stem, 'e')
+	case endsWithDoubleConsonant(stem):
+		if last := stem[len(stem)-1]; last != 'l' && last != 's' && last != 'z' {
+			return stem[:len(stem)-1]
+		}
+	case measure(stem) == 1 && endsWithCVC(stem):
+		return append(stem, 'e')
+	}
+	return stem
+}
+
+func stemStep1c(w []byte) []byte {
+	if hasSuffix(w, "y") && containsVowel(w[:len(w)-1]) {
+		w[len(w)-1] = 'i'
+	}
+	return w
+}
+
+// replaceSuffixes replaces the first matching suffix when the remaining stem has a measure
+// above minMeasure
+func replaceSuffixes(w []byte, minMeasure int, rules []suffixRule) []byte {
+	for _, rule := range rules {
+		if !hasSuffix(w, rule.suffix) {
+			continue
+		}
+		stem := w[:len(w)-len(rule.suffix)]
+		if measure(stem) > minMeasure {
+			return append(stem, rule.replacement...)
+		}
+		return w
+	}
+	return w
+}
+
+func stemStep4(w []byte) []byte {
+	for _, suffix := range step4Suffixes {
+		if !hasSuffix(w, suffix) {
+			continue
+		}
+		stem := w[:len(w)-len(suffix)]
+		if measure(stem) <= 1 {
+			return w
+		}
+		if suffix == "ion" && !hasSuffix(stem, "s") && !hasSuffix(stem, "t") {
+			return w
+		}
+		return stem
+	}
+	return w
+}
+
+func stemStep5(w []byte) []byte {
+	if hasSuffix(w, "e") {
+		stem := w[:len(w)-1]
+		if m := measure(stem); m > 1 || (m == 1 && !endsWithCVC(stem)) {
+			w = stem
+		}
+	}
+	if hasSuffix(w, "ll") && measure(w) > 1 {
+		w = w[:len(w)-1]
+	}
+	return w
+}
+
+func hasSuffix(w []byte, suffix string) bool {
+	return strings.HasSuffix(string(w), suffix)
+}
+
+// isConsonant follows Porter: y is a consonant at the start of a word or after a vowel
+func isConsonant(w []byte, i int) bool {
+	switch w[i] {
+	case 'a', 'e', 'i', 'o', 'u':
+		return false
+	case 'y':
+		return i == 0 || !isConsonant(w, i-1)
+	}
+	return true
+}
+
+// measure counts the vowel-consonant sequences of a stem, m in [C](VC)^m[V]
+func measure(w []byte) int {
+	m := 0
+	inVowels := false
+	for i := range w {
+		if isConsonant(w, i) {
+			if inVowels {
+				m++
+			}
+			inVowels = false
+		} else {
+			inVowels = true
+		}
+	}
+	return m
+}
+
+func containsVowel(w []byte) bool {
+	for i := range w {
+		if !isConsonant(w, i) {
+			return true
+		}
+	}
+	return false
+}
+
+func endsWithDoubleConsonant(w []byte) bool {
+	n := len(w)
+	return n >= 2 && w[n-1] == w[n-2] && isConsonant(w, n-1)
+}
+
+// endsWithCVC reports a consonant-vowel-consonant ending whose last letter is not w, x or y
+func endsWithCVC(w []byte) bool {
+	n := len(w)
+	if n < 3 || !isConsonant(w, n-1) || isConsonant(w, n-2) || !isConsonant(w, n-3) {
+		return false
+	}
+	last := w[n-1]
+	return last != 'w' && last != 'x' && last != 'y'
+}
@@ -0,0 +1,116 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStem(t *testing.T) {
+	tests := map[string]string{
+		"caresses":       "caress",
+		"ponies":         "poni",
+		"cats":           "cat",
+		"feed":           "feed",
+		"agreed":         "agre",
+		"plastered":      "plaster",
+		"motoring":       "motor",
+		"sing":           "sing",
+		"hopping":        "hop",
+		"falling":        "fall",
+		"filing":         "file",
+		"happy":          "happi",
+		"relational":     "relat",
+		"generalization": "gener",
+		"running":        "run",
+		"tutorials":      "tutori",
+		"tutorial":       "tutori",
+		"programming":    "program",
+		"programs":       "program",
+		"go":             "go",
+		"k8s":            "k8s",
+		"café":           "café",
+	}
+
+	for word, want := range tests {
+		t.Run(word, func(t *testing.T) {
+			assert.Equal(t, want, Stem(word))
+		})
+	}
+}
+
+func TestAnalyzeText(t *testing.T) {
+	assert.Equal(t, []string{"go", "program", "tutori"}, AnalyzeText("The Go Programming Tutorials"))
+	assert.Equal(t, []string{"run", "2024"}, AnalyzeText("Running, 2024!"))
+	assert.Empty(t, AnalyzeText("a the"))
+}
+
+func TestStemIn(t *testing.T) {
+	tests := []struct {
+		language string
+		word     string
+		want     string
+	}{
+		{LanguageGerman, "häuser", "haus"},
+		{LanguageGerman, "katzen", "katz"},
+		{LanguageGerman, "programmierung", "programmier"},
+		{LanguageGerman, "schönheit", "schonheit"},
+		{LanguageGerman, "möglichkeit", "moglich"},
+		{LanguageGerman, "straße", "strass"},
+		{LanguageTurkish, "kitaplardan", "kitap"},
+		{LanguageTurkish, "kitaplar", "kitap"},
+		{LanguageTurkish, "evlerde", "ev"},
+		{LanguageTurkish, "ev", "ev"},
+		{LanguageEnglish, "programming", "program"},
+		{"xx", "programming", "programming"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.language+" "+tt.word, func(t *testing.T) {
+			assert.Equal(t, tt.want, StemIn(tt.word, tt.language))
+		})
+	}
+}
+
+func TestTextAnalyzer(t *testing.T) {
+	analyzer, err := NewTextAnalyzer(map[string][]string{"en": {"Tutorial"}, "de-DE": {"für"}})
+	require.NoError(t, err)
+
+	t.Run("Analyze", func(t *testing.T) {
+		assert.Equal(t, []string{"haus", "katz"}, analyzer.Analyze("Die Häuser für Katzen", LanguageGerman))
+		assert.Equal(t, []string{"go"}, analyzer.Analyze("The Go Tutorial", LanguageEnglish))
+		assert.Equal(t, []string{"go", "tutori"}, AnalyzeTextIn("The Go Tutorial", LanguageEnglish))
+	})
+
+	tests := []struct {
+		name     string
+		query    string
+		language string
+		expected string
+	}{
+		{"Drops stopwords", "the go tutorial", LanguageEnglish, "(go)"},
+		{"Language specific", "der die katzen", LanguageGerman, "(katzen)"},
+		{"Other language keeps words", "the go tutorial", LanguageGerman, "(the AND go AND tutorial)"},
+		{"Keeps phrases", `"the go tutorial"`, LanguageEnglish, `"the go tutorial"`},
+		{"Keeps other fields", "tag:tutorial go", LanguageEnglish, "(tag:tutorial AND go)"},
+		{"Drops negated stopwords", "go -the", LanguageEnglish, "(go)"},
+		{"Keeps all-stopword queries", "the tutorial", LanguageEnglish, "(the AND tutorial)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node, err := ParseQuery(tt.query)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.expected, analyzer.RemoveStopwords(node, tt.language).String())
+		})
+	}
+
+	t.Run("Unsupported language", func(t *testing.T) {
+		_, err := NewTextAnalyzer(map[string][]string{"xx": {"a"}})
+
+		var domainErr *DomainError
+		require.ErrorAs(t, err, &domainErr)
+		assert.Equal(t, ErrorCodeInvalidInput, domainErr.Code)
+	})
+}
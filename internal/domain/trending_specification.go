@@ -0,0 +1,152 @@
+package domain
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultTrendingWindow = 24 * time.Hour
+	MaxTrendingWindow     = 90 * 24 * time.Hour
+)
+
+// TrendingScoreSpecification scores content by how fast its engagement grew since a baseline
+// snapshot, over the time between the baseline and now
+type TrendingScoreSpecification struct {
+	baseline *ContentMetricsSnapshot
+	now      time.Time
+}
+
+func NewTrendingScoreSpecification(baseline *ContentMetricsSnapshot, now time.Time) *TrendingScoreSpecification {
+	return &TrendingScoreSpecification{
+		baseline: baseline,
+		now:      now,
+	}
+}
+
+func (s *TrendingScoreSpecification) Calculate(content *Content) float64 {
+	if s.hasNoBaseline() {
+		return 0.0
+	}
+
+	growth := float64(content.Views-s.baseline.Views)/1000.0 +
+		float64(content.Likes-s.baseline.Likes)/100.0 +
+		float64(content.Reactions-s.baseline.Reactions)/50.0 +
+		float64(content.Listens-s.baseline.Listens)/500.0 +
+		float64(content.Comments-s.baseline.Comments)/10.0
+
+	if growth <= 0 {
+		return 0.0
+	}
+	return growth / s.elapsedHours()
+}
+
+// TrendingBaseline estimates the metrics of content at since, the start of a trending window,
+// interpolating linearly between the last snapshot taken at or before since and the first one
+// taken after it. Content first seen inside the window starts from its first snapshot instead,
+// so the baseline never lies before the window.
+func TrendingBaseline(before, after *ContentMetricsSnapshot, since time.Time) *ContentMetricsSnapshot {
+	if before == nil {
+		return after
+	}
+	baseline := *before
+	baseline.RecordedAt = since
+	if after == nil {
+		return &baseline
+	}
+
+	ratio := float64(since.Sub(before.RecordedAt)) / float64(after.RecordedAt.Sub(before.RecordedAt))
+	interpolate := func(from, to int) int {
+		return from + int(math.Round(float64(to-from)*ratio))
+	}
+	baseline.Views = interpolate(before.Views, after.Views)
+	baseline.Likes = interpolate(before.Likes, after.Likes)
+	baseline.Reactions = interpolate(before.Reactions, after.Reactions)
+	baseline.Comments = interpolate(before.Comments, after.Comments)
+	baseline.Listens = interpolate(before.Listens, after.Listens)
+	baseline.Score = before.Score + (after.Score-before.Score)*ratio
+	return &baseline
+}
+
+func (s *TrendingScoreSpecification) hasNoBaseline() bool {
+	return s.baseline == nil
+}
+
+// elapsedHours is floored at one hour so that back-to-back ingests do not produce runaway velocities
+func (s *TrendingScoreSpecification) elapsedHours() float64 {
+	hours := s.now.Sub(s.baseline.RecordedAt).Hours()
+	if hours < 1 {
+		return 1
+	}
+	return hours
+}
+
+type TrendingRequest struct {
+	ContentType *ContentType  `form:"type"`
+	Window      string        `form:"window"`
+	Page        int           `form:"page"`
+	PageSize    int           `form:"page_size"`
+	Duration    time.Duration `form:"-"`
+}
+
+type TrendingWindowSpecification struct{}
+
+func NewTrendingWindowSpecification() *TrendingWindowSpecification {
+	return &TrendingWindowSpecification{}
+}
+
+// NormalizeTrendingRequest parses the window ("24h", "90m", "7d") and applies pagination defaults
+func (s *TrendingWindowSpecification) NormalizeTrendingRequest(req *TrendingRequest) error {
+	if req.ContentType != nil && *req.ContentType == "" {
+		req.ContentType = nil
+	}
+	if req.ContentType != nil && !req.ContentType.IsValid() {
+		return NewInvalidInputError("type", "must be one of: video, text, audio, gallery")
+	}
+
+	window, err := s.parseWindow(req.Window)
+	if err != nil {
+		return err
+	}
+	req.Duration = window
+
+	if req.Page <= 0 {
+		req.Page = DefaultPage
+	}
+	if req.PageSize <= 0 {
+		req.PageSize = DefaultPageSize
+	}
+	if req.PageSize > MaxPageSize {
+		req.PageSize = MaxPageSize
+	}
+	return nil
+}
+
+func (s *TrendingWindowSpecification) parseWindow(raw string) (time.Duration, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return DefaultTrendingWindow, nil
+	}
+
+	var window time.Duration
+	if days, found := strings.CutSuffix(raw, "d"); found {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, NewInvalidInputError("window", "must be a duration such as 24h or 7d")
+		}
+		window = time.Duration(n) * 24 * time.Hour
+	} else {
+		parsed, err := time.ParseDuration(raw)
+		if err != nil {
+			return 0, NewInvalidInputError("window", "must be a duration such as 24h or 7d")
+		}
+		window = parsed
+	}
+
+	if window <= 0 || window > MaxTrendingWindow {
+		return 0, NewInvalidInputError("window", "must be positive and at most 90d")
+	}
+	return window, nil
+}
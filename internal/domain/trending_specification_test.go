@@ -0,0 +1,116 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrendingScoreSpecification_Calculate(t *testing.T) {
+	now := time.Date(2024, 3, 15, 12, 0, 0, 0, time.UTC)
+
+	t.Run("Scores growth per hour", func(t *testing.T) {
+		baseline := &ContentMetricsSnapshot{Views: 1000, Likes: 100, RecordedAt: now.Add(-10 * time.Hour)}
+		spec := NewTrendingScoreSpecification(baseline, now)
+
+		score := spec.Calculate(&Content{Views: 21000, Likes: 300})
+
+		assert.Equal(t, (20.0+2.0)/10.0, score)
+	})
+
+	t.Run("Floors elapsed time at one hour", func(t *testing.T) {
+		baseline := &ContentMetricsSnapshot{Views: 0, RecordedAt: now.Add(-time.Minute)}
+		spec := NewTrendingScoreSpecification(baseline, now)
+
+		assert.Equal(t, 5.0, spec.Calculate(&Content{Views: 5000}))
+	})
+
+	t.Run("No growth scores zero", func(t *testing.T) {
+		baseline := &ContentMetricsSnapshot{Views: 5000, RecordedAt: now.Add(-time.Hour)}
+		spec := NewTrendingScoreSpecification(baseline, now)
+
+		assert.Equal(t, 0.0, spec.Calculate(&Content{Views: 4000}))
+	})
+
+	t.Run("No baseline scores zero", func(t *testing.T) {
+		spec := NewTrendingScoreSpecification(nil, now)
+
+		assert.Equal(t, 0.0, spec.Calculate(&Content{Views: 5000}))
+	})
+}
+
+func TestTrendingBaseline(t *testing.T) {
+	since := time.Date(2024, 3, 14, 12, 0, 0, 0, time.UTC)
+	before := &ContentMetricsSnapshot{Views: 1000, Likes: 10, RecordedAt: since.Add(-30 * time.Hour)}
+	after := &ContentMetricsSnapshot{Views: 4000, Likes: 40, RecordedAt: since.Add(10 * time.Hour)}
+
+	t.Run("Interpolates at the start of the window", func(t *testing.T) {
+		baseline := TrendingBaseline(before, after, since)
+
+		assert.Equal(t, since, baseline.RecordedAt)
+		assert.Equal(t, 3250, baseline.Views)
+		assert.Equal(t, 33, baseline.Likes)
+	})
+
+	t.Run("Unchanged since an older snapshot", func(t *testing.T) {
+		baseline := TrendingBaseline(before, nil, since)
+
+		assert.Equal(t, since, baseline.RecordedAt)
+		assert.Equal(t, 1000, baseline.Views)
+	})
+
+	t.Run("First seen inside the window", func(t *testing.T) {
+		assert.Equal(t, after, TrendingBaseline(nil, after, since))
+	})
+
+	t.Run("Old baselines do not dilute velocity", func(t *testing.T) {
+		now := since.Add(24 * time.Hour)
+		score := NewTrendingScoreSpecification(TrendingBaseline(before, after, since), now).Calculate(&Content{Views: 4000, Likes: 40})
+
+		assert.InDelta(t, (0.75+0.07)/24.0, score, 1e-9)
+	})
+}
+
+func TestTrendingWindowSpecification_NormalizeTrendingRequest(t *testing.T) {
+	spec := NewTrendingWindowSpecification()
+
+	t.Run("Defaults window and pagination", func(t *testing.T) {
+		req := &TrendingRequest{}
+
+		err := spec.NormalizeTrendingRequest(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, DefaultTrendingWindow, req.Duration)
+		assert.Equal(t, DefaultPage, req.Page)
+		assert.Equal(t, DefaultPageSize, req.PageSize)
+	})
+
+	t.Run("Parses day windows", func(t *testing.T) {
+		req := &TrendingRequest{Window: "7d"}
+
+		err := spec.NormalizeTrendingRequest(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 7*24*time.Hour, req.Duration)
+	})
+
+	t.Run("Rejects invalid windows", func(t *testing.T) {
+		for _, window := range []string{"soon", "-1h", "365d"} {
+			req := &TrendingRequest{Window: window}
+
+			err := spec.NormalizeTrendingRequest(req)
+
+			assert.True(t, IsInvalidInputError(err), window)
+		}
+	})
+
+	t.Run("Rejects unknown content type", func(t *testing.T) {
+		ct := ContentType("hologram")
+		req := &TrendingRequest{ContentType: &ct}
+
+		err := spec.NormalizeTrendingRequest(req)
+
+		assert.True(t, IsInvalidInputError(err))
+	})
+}
@@ -17,6 +17,10 @@ import (
 type Cache interface {
 	Get(ctx context.Context, key string) ([]*domain.Content, bool)
 	Set(ctx context.Context, key string, value []*domain.Content, ttl time.Duration) error
+	// GetTotal and SetTotal cache a number next to the contents, such as how many results a
+	// search has in all when only one page of them is cached
+	GetTotal(ctx context.Context, key string) (int, bool)
+	SetTotal(ctx context.Context, key string, total int, ttl time.Duration) error
 	Delete(ctx context.Context, key string) error
 	Clear(ctx context.Context) error
 	Close() error
@@ -92,6 +96,25 @@ func (c *RedisCache) Set(ctx context.Context, key string, value []*domain.Conten
 	return c.client.Set(ctx, key, data, ttl).Err()
 }
 
+func (c *RedisCache) GetTotal(ctx context.Context, key string) (int, bool) {
+	total, err := c.client.Get(ctx, key).Int()
+	if err == redis.Nil {
+		return 0, false
+	}
+	if err != nil {
+		c.log.Warn("Failed to get from cache", zap.Error(err))
+		return 0, false
+	}
+	return total, true
+}
+
+func (c *RedisCache) SetTotal(ctx context.Context, key string, total int, ttl time.Duration) error {
+	if ttl == 0 {
+		ttl = c.ttl
+	}
+	return c.client.Set(ctx, key, total, ttl).Err()
+}
+
 func (c *RedisCache) Delete(ctx context.Context, key string) error {
 	return c.client.Del(ctx, key).Err()
 }
@@ -114,26 +137,37 @@ type InMemoryCache struct {
 
 type cacheItem struct {
 	value     []*domain.Content
+	total     int
 	expiresAt time.Time
 }
 
 func (c *InMemoryCache) Get(ctx context.Context, key string) ([]*domain.Content, bool) {
+	item, ok := c.get(key)
+	return item.value, ok
+}
+
+func (c *InMemoryCache) GetTotal(ctx context.Context, key string) (int, bool) {
+	item, ok := c.get(key)
+	return item.total, ok
+}
+
+func (c *InMemoryCache) get(key string) (cacheItem, bool) {
 	c.mu.RLock()
 	item, exists := c.data[key]
 	c.mu.RUnlock()
 
 	if !exists {
-		return nil, false
+		return cacheItem{}, false
 	}
 
 	if c.hasExpiredItem(item) {
 		c.mu.Lock()
 		delete(c.data, key)
 		c.mu.Unlock()
-		return nil, false
+		return cacheItem{}, false
 	}
 
-	return item.value, true
+	return item, true
 }
 
 func (c *InMemoryCache) hasExpiredItem(item cacheItem) bool {
@@ -141,6 +175,16 @@ func (c *InMemoryCache) hasExpiredItem(item cacheItem) bool {
 }
 
 func (c *InMemoryCache) Set(ctx context.Context, key string, value []*domain.Content, ttl time.Duration) error {
+	c.set(key, cacheItem{value: value}, ttl)
+	return nil
+}
+
+func (c *InMemoryCache) SetTotal(ctx context.Context, key string, total int, ttl time.Duration) error {
+	c.set(key, cacheItem{total: total}, ttl)
+	return nil
+}
+
+func (c *InMemoryCache) set(key string, item cacheItem, ttl time.Duration) {
 	if ttl == 0 {
 		ttl = c.ttl
 	}
@@ -152,12 +196,8 @@ func (c *InMemoryCache) Set(ctx context.Context, key string, value []*domain.Con
 		c.evictOldestEntryLocked()
 	}
 
-	c.data[key] = cacheItem{
-		value:     value,
-		expiresAt: time.Now().Add(ttl),
-	}
-
-	return nil
+	item.expiresAt = time.Now().Add(ttl)
+	c.data[key] = item
 }
 
 func (c *InMemoryCache) isCacheFull() bool {
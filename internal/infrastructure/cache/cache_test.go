@@ -56,6 +56,25 @@ func TestInMemoryCache_GetSet(t *testing.T) {
 	})
 }
 
+func TestInMemoryCache_GetSetTotal(t *testing.T) {
+	cache := NewInMemory()
+	ctx := context.Background()
+
+	t.Run("Get non-existent key", func(t *testing.T) {
+		_, found := cache.GetTotal(ctx, "nonexistent")
+		assert.False(t, found)
+	})
+
+	t.Run("Set and Get", func(t *testing.T) {
+		err := cache.SetTotal(ctx, "total-key", 42, 5*time.Minute)
+		assert.NoError(t, err)
+
+		total, found := cache.GetTotal(ctx, "total-key")
+		assert.True(t, found)
+		assert.Equal(t, 42, total)
+	})
+}
+
 func TestInMemoryCache_TTLExpiration(t *testing.T) {
 	cache := NewInMemory()
 	ctx := context.Background()
@@ -235,6 +254,41 @@ func TestRedisCache_GetSet(t *testing.T) {
 	})
 }
 
+func TestRedisCache_GetSetTotal(t *testing.T) {
+	cfg := config.CacheConfig{
+		Host: "localhost",
+		Port: 6379,
+		DB:   1,
+		TTL:  5 * time.Minute,
+	}
+
+	cache, err := NewRedis(cfg)
+	if err != nil {
+		t.Skip("Redis not available, skipping Redis cache tests")
+		return
+	}
+	defer cache.Close()
+
+	ctx := context.Background()
+
+	err = cache.Clear(ctx)
+	require.NoError(t, err)
+
+	t.Run("Get non-existent key", func(t *testing.T) {
+		_, found := cache.GetTotal(ctx, "nonexistent")
+		assert.False(t, found)
+	})
+
+	t.Run("Set and Get", func(t *testing.T) {
+		err := cache.SetTotal(ctx, "total-key", 42, 5*time.Minute)
+		assert.NoError(t, err)
+
+		total, found := cache.GetTotal(ctx, "total-key")
+		assert.True(t, found)
+		assert.Equal(t, 42, total)
+	})
+}
+
 func TestRedisCache_TTLExpiration(t *testing.T) {
 	cfg := config.CacheConfig{
 		Host: "localhost",
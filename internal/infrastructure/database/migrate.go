@@ -2,6 +2,7 @@ package database
 
 import (
 	"fmt"
+	"strings"
 
 	"search-engine-go/internal/domain"
 
@@ -13,6 +14,10 @@ func Migrate(db *gorm.DB) error {
 		return fmt.Errorf("failed to create enum type: %w", err)
 	}
 
+	if err := createExtensions(db); err != nil {
+		return fmt.Errorf("failed to create extensions: %w", err)
+	}
+
 	var tableExists bool
 	if err := db.Raw(`
 		SELECT EXISTS (
@@ -50,6 +55,30 @@ func Migrate(db *gorm.DB) error {
 		_ = db.AutoMigrate(&domain.Content{})
 	}
 
+	if err := createTagTables(db); err != nil {
+		return fmt.Errorf("failed to create tag tables: %w", err)
+	}
+
+	if err := createMetricsHistoryTable(db); err != nil {
+		return fmt.Errorf("failed to create metrics history table: %w", err)
+	}
+
+	if err := createSearchQueriesTable(db); err != nil {
+		return fmt.Errorf("failed to create search queries table: %w", err)
+	}
+
+	if err := createSynonymSetsTable(db); err != nil {
+		return fmt.Errorf("failed to create synonym sets table: %w", err)
+	}
+
+	if err := createSearchConfigFunction(db); err != nil {
+		return fmt.Errorf("failed to create search config function: %w", err)
+	}
+
+	if err := createSearchVector(db); err != nil {
+		return fmt.Errorf("failed to create search vector: %w", err)
+	}
+
 	if err := createCustomIndexes(db); err != nil {
 		return fmt.Errorf("failed to create custom indexes: %w", err)
 	}
@@ -69,7 +98,21 @@ func createTable(db *gorm.DB) error {
 			likes INTEGER DEFAULT 0,
 			reading_time INTEGER DEFAULT 0,
 			reactions INTEGER DEFAULT 0,
+			duration INTEGER DEFAULT 0,
+			comments INTEGER DEFAULT 0,
+			listens INTEGER DEFAULT 0,
+			image_count INTEGER DEFAULT 0,
 			score DECIMAL(10, 4) DEFAULT 0,
+			trending_score DECIMAL(12, 4) DEFAULT 0,
+			canonical_id BIGINT REFERENCES contents(id) ON DELETE SET NULL,
+			normalized_title VARCHAR(500),
+			sim_hash BIGINT DEFAULT 0,
+			sim_hash_band0 INTEGER DEFAULT 0,
+			sim_hash_band1 INTEGER DEFAULT 0,
+			sim_hash_band2 INTEGER DEFAULT 0,
+			sim_hash_band3 INTEGER DEFAULT 0,
+			language VARCHAR(8) NOT NULL DEFAULT 'en',
+			search_vector TSVECTOR,
 			created_at TIMESTAMP NOT NULL DEFAULT NOW(),
 			updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
 			deleted_at TIMESTAMP,
@@ -82,6 +125,100 @@ func createTable(db *gorm.DB) error {
 	return nil
 }
 
+func createTagTables(db *gorm.DB) error {
+	if err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS tags (
+			id BIGSERIAL PRIMARY KEY,
+			name VARCHAR(100) NOT NULL UNIQUE
+		)
+	`).Error; err != nil {
+		return fmt.Errorf("failed to create tags table: %w", err)
+	}
+
+	if err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS content_tags (
+			content_id BIGINT NOT NULL REFERENCES contents(id) ON DELETE CASCADE,
+			tag_id BIGINT NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+			PRIMARY KEY (content_id, tag_id)
+		)
+	`).Error; err != nil {
+		return fmt.Errorf("failed to create content_tags table: %w", err)
+	}
+
+	if err := db.Exec(`
+		CREATE INDEX IF NOT EXISTS idx_content_tags_tag_id 
+		ON content_tags(tag_id)
+	`).Error; err != nil {
+		return fmt.Errorf("failed to create content_tags tag index: %w", err)
+	}
+
+	return nil
+}
+
+func createMetricsHistoryTable(db *gorm.DB) error {
+	if err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS content_metrics_history (
+			id BIGSERIAL PRIMARY KEY,
+			content_id BIGINT NOT NULL REFERENCES contents(id) ON DELETE CASCADE,
+			views INTEGER DEFAULT 0,
+			likes INTEGER DEFAULT 0,
+			reactions INTEGER DEFAULT 0,
+			comments INTEGER DEFAULT 0,
+			listens INTEGER DEFAULT 0,
+			score DECIMAL(10, 4) DEFAULT 0,
+			recorded_at TIMESTAMP NOT NULL DEFAULT NOW()
+		)
+	`).Error; err != nil {
+		return fmt.Errorf("failed to create content_metrics_history table: %w", err)
+	}
+
+	if err := db.Exec(`
+		CREATE INDEX IF NOT EXISTS idx_metrics_history_content_recorded 
+		ON content_metrics_history(content_id, recorded_at)
+	`).Error; err != nil {
+		return fmt.Errorf("failed to create metrics history index: %w", err)
+	}
+
+	return nil
+}
+
+func createSearchQueriesTable(db *gorm.DB) error {
+	if err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS search_queries (
+			query VARCHAR(255) PRIMARY KEY,
+			count INTEGER NOT NULL DEFAULT 0,
+			last_searched_at TIMESTAMP NOT NULL DEFAULT NOW()
+		)
+	`).Error; err != nil {
+		return fmt.Errorf("failed to create search_queries table: %w", err)
+	}
+
+	if err := db.Exec(`
+		CREATE INDEX IF NOT EXISTS idx_search_queries_count 
+		ON search_queries(count DESC)
+	`).Error; err != nil {
+		return fmt.Errorf("failed to create search queries count index: %w", err)
+	}
+
+	return nil
+}
+
+func createSynonymSetsTable(db *gorm.DB) error {
+	if err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS synonym_sets (
+			id BIGSERIAL PRIMARY KEY,
+			name VARCHAR(100) NOT NULL UNIQUE,
+			rules TEXT NOT NULL DEFAULT '[]',
+			created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+			updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+		)
+	`).Error; err != nil {
+		return fmt.Errorf("failed to create synonym_sets table: %w", err)
+	}
+
+	return nil
+}
+
 func createEnumType(db *gorm.DB) error {
 	var exists bool
 	if err := db.Raw(`
@@ -94,12 +231,74 @@ func createEnumType(db *gorm.DB) error {
 
 	if !exists {
 		if err := db.Exec(`
-			CREATE TYPE content_type AS ENUM ('video', 'text')
+			CREATE TYPE content_type AS ENUM ('video', 'text', 'audio', 'gallery')
 		`).Error; err != nil {
 			return fmt.Errorf("failed to create enum type: %w", err)
 		}
+		return nil
+	}
+
+	for _, value := range []string{"audio", "gallery"} {
+		if err := db.Exec(fmt.Sprintf(`ALTER TYPE content_type ADD VALUE IF NOT EXISTS '%s'`, value)).Error; err != nil {
+			return fmt.Errorf("failed to add enum value %s: %w", value, err)
+		}
+	}
+
+	return nil
+}
+
+// createExtensions enables pg_trgm, which provides the word similarity and trigram index used
+// by fuzzy search
+func createExtensions(db *gorm.DB) error {
+	if err := db.Exec(`CREATE EXTENSION IF NOT EXISTS pg_trgm`).Error; err != nil {
+		return fmt.Errorf("failed to create pg_trgm extension: %w", err)
+	}
+	return nil
+}
+
+// createSearchConfigFunction creates content_search_config, which maps a content language to
+// its text search configuration. It is immutable so that the title search index can use it.
+func createSearchConfigFunction(db *gorm.DB) error {
+	var cases strings.Builder
+	for _, language := range domain.Languages {
+		fmt.Fprintf(&cases, " WHEN '%s' THEN '%s'", language, domain.TextSearchConfigs[language])
+	}
+	if err := db.Exec(`
+		CREATE OR REPLACE FUNCTION content_search_config(language VARCHAR)
+		RETURNS regconfig LANGUAGE sql IMMUTABLE AS $$
+			SELECT (CASE language` + cases.String() + ` ELSE 'simple' END)::regconfig
+		$$
+	`).Error; err != nil {
+		return fmt.Errorf("failed to create content_search_config function: %w", err)
+	}
+	return nil
+}
+
+// createSearchVector adds the weighted search vector of titles (A), tag names (B) and providers
+// (C), fills it in for rows stored before it existed and indexes it. Ingest keeps it up to date.
+func createSearchVector(db *gorm.DB) error {
+	if err := db.Exec(`ALTER TABLE contents ADD COLUMN IF NOT EXISTS search_vector TSVECTOR`).Error; err != nil {
+		return fmt.Errorf("failed to add search_vector column: %w", err)
+	}
+
+	if err := db.Exec(`
+		UPDATE contents SET search_vector =
+			setweight(to_tsvector(content_search_config(language), title), 'A') ||
+			setweight(to_tsvector(content_search_config(language), COALESCE((SELECT string_agg(tags.name, ' ')
+				FROM content_tags JOIN tags ON tags.id = content_tags.tag_id
+				WHERE content_tags.content_id = contents.id), '')), 'B') ||
+			setweight(to_tsvector(content_search_config(language), provider), 'C')
+		WHERE search_vector IS NULL
+	`).Error; err != nil {
+		return fmt.Errorf("failed to fill search_vector column: %w", err)
 	}
 
+	if err := db.Exec(`
+		CREATE INDEX IF NOT EXISTS idx_contents_search_vector 
+		ON contents USING gin(search_vector)
+	`).Error; err != nil {
+		return fmt.Errorf("failed to create search vector index: %w", err)
+	}
 	return nil
 }
 
@@ -111,11 +310,21 @@ func createCustomIndexes(db *gorm.DB) error {
 		return fmt.Errorf("failed to create provider index: %w", err)
 	}
 
+	if err := db.Exec(`DROP INDEX IF EXISTS idx_contents_title_search`).Error; err != nil {
+		return fmt.Errorf("failed to drop english title search index: %w", err)
+	}
+
+	// titles are matched through the search vector, which has its own index
+	if err := db.Exec(`DROP INDEX IF EXISTS idx_contents_title_language_search`).Error; err != nil {
+		return fmt.Errorf("failed to drop title search index: %w", err)
+	}
+
+	// fuzzy title terms are matched with the word similarity operator <%, which this index serves
 	if err := db.Exec(`
-		CREATE INDEX IF NOT EXISTS idx_contents_title_search 
-		ON contents USING gin(to_tsvector('english', title))
+		CREATE INDEX IF NOT EXISTS idx_contents_title_trgm 
+		ON contents USING gin(title gin_trgm_ops)
 	`).Error; err != nil {
-		return fmt.Errorf("failed to create title search index: %w", err)
+		return fmt.Errorf("failed to create title trigram index: %w", err)
 	}
 
 	if err := db.Exec(`
@@ -132,5 +341,54 @@ func createCustomIndexes(db *gorm.DB) error {
 		return fmt.Errorf("failed to create type_created_at index: %w", err)
 	}
 
+	if err := db.Exec(`
+		CREATE INDEX IF NOT EXISTS idx_contents_type_duration 
+		ON contents(type, duration)
+	`).Error; err != nil {
+		return fmt.Errorf("failed to create type_duration index: %w", err)
+	}
+
+	if err := db.Exec(`
+		CREATE INDEX IF NOT EXISTS idx_contents_type_trending_score 
+		ON contents(type, trending_score DESC)
+	`).Error; err != nil {
+		return fmt.Errorf("failed to create type_trending_score index: %w", err)
+	}
+
+	if err := db.Exec(`
+		CREATE INDEX IF NOT EXISTS idx_contents_canonical_id 
+		ON contents(canonical_id)
+	`).Error; err != nil {
+		return fmt.Errorf("failed to create canonical_id index: %w", err)
+	}
+
+	if err := db.Exec(`
+		CREATE INDEX IF NOT EXISTS idx_contents_normalized_title 
+		ON contents(normalized_title)
+	`).Error; err != nil {
+		return fmt.Errorf("failed to create normalized_title index: %w", err)
+	}
+
+	for band := 0; band < domain.SimHashBands; band++ {
+		if err := db.Exec(fmt.Sprintf(`
+			CREATE INDEX IF NOT EXISTS idx_contents_sim_hash_band%[1]d 
+			ON contents(sim_hash_band%[1]d)
+		`, band)).Error; err != nil {
+			return fmt.Errorf("failed to create sim_hash_band%d index: %w", band, err)
+		}
+	}
+
+	// fill in the bands of content fingerprinted before they were stored
+	if err := db.Exec(`
+		UPDATE contents SET
+			sim_hash_band0 = sim_hash & 65535,
+			sim_hash_band1 = (sim_hash >> 16) & 65535,
+			sim_hash_band2 = (sim_hash >> 32) & 65535,
+			sim_hash_band3 = (sim_hash >> 48) & 65535
+		WHERE sim_hash <> 0 AND sim_hash_band0 = 0 AND sim_hash_band1 = 0 AND sim_hash_band2 = 0 AND sim_hash_band3 = 0
+	`).Error; err != nil {
+		return fmt.Errorf("failed to fill in sim_hash bands: %w", err)
+	}
+
 	return nil
 }
@@ -0,0 +1,6 @@
+-- Drop indexes
+DROP INDEX IF EXISTS idx_content_tags_tag_id;
+
+-- Drop tables
+DROP TABLE IF EXISTS content_tags;
+DROP TABLE IF EXISTS tags;
@@ -0,0 +1,15 @@
+-- Create normalized tags table
+CREATE TABLE tags (
+    id BIGSERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL UNIQUE
+);
+
+-- Create content <-> tag join table
+CREATE TABLE content_tags (
+    content_id BIGINT NOT NULL REFERENCES contents(id) ON DELETE CASCADE,
+    tag_id BIGINT NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+    PRIMARY KEY (content_id, tag_id)
+);
+
+-- Create index for tag lookups
+CREATE INDEX idx_content_tags_tag_id ON content_tags(tag_id);
@@ -0,0 +1,5 @@
+-- Drop indexes
+DROP INDEX IF EXISTS idx_contents_type_duration;
+
+-- Drop column
+ALTER TABLE contents DROP COLUMN IF EXISTS duration;
@@ -0,0 +1,5 @@
+-- Add normalized duration (in seconds) to contents
+ALTER TABLE contents ADD COLUMN duration INTEGER DEFAULT 0;
+
+-- Create index for duration range filters
+CREATE INDEX idx_contents_type_duration ON contents(type, duration);
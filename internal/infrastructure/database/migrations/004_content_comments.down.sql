@@ -0,0 +1,2 @@
+-- Drop column
+ALTER TABLE contents DROP COLUMN IF EXISTS comments;
@@ -0,0 +1,2 @@
+-- Add comment count engagement metric to contents
+ALTER TABLE contents ADD COLUMN comments INTEGER DEFAULT 0;
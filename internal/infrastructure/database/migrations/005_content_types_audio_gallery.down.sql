@@ -0,0 +1,12 @@
+-- Remove content that uses the new types
+DELETE FROM contents WHERE type IN ('audio', 'gallery');
+
+-- Drop type-specific metrics
+ALTER TABLE contents DROP COLUMN IF EXISTS image_count;
+ALTER TABLE contents DROP COLUMN IF EXISTS listens;
+
+-- Recreate enum without the new values (Postgres cannot drop enum values in place)
+ALTER TYPE content_type RENAME TO content_type_old;
+CREATE TYPE content_type AS ENUM ('video', 'text');
+ALTER TABLE contents ALTER COLUMN type TYPE content_type USING type::text::content_type;
+DROP TYPE content_type_old;
@@ -0,0 +1,7 @@
+-- Extend content_type enum with podcasts/audio and image galleries
+ALTER TYPE content_type ADD VALUE IF NOT EXISTS 'audio';
+ALTER TYPE content_type ADD VALUE IF NOT EXISTS 'gallery';
+
+-- Add type-specific metrics
+ALTER TABLE contents ADD COLUMN listens INTEGER DEFAULT 0;
+ALTER TABLE contents ADD COLUMN image_count INTEGER DEFAULT 0;
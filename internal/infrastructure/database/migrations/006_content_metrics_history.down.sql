@@ -0,0 +1,5 @@
+-- Drop indexes
+DROP INDEX IF EXISTS idx_metrics_history_content_recorded;
+
+-- Drop table
+DROP TABLE IF EXISTS content_metrics_history;
@@ -0,0 +1,15 @@
+-- Create metrics history table for per-ingest snapshots
+CREATE TABLE content_metrics_history (
+    id BIGSERIAL PRIMARY KEY,
+    content_id BIGINT NOT NULL REFERENCES contents(id) ON DELETE CASCADE,
+    views INTEGER DEFAULT 0,
+    likes INTEGER DEFAULT 0,
+    reactions INTEGER DEFAULT 0,
+    comments INTEGER DEFAULT 0,
+    listens INTEGER DEFAULT 0,
+    score DECIMAL(10, 4) DEFAULT 0,
+    recorded_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- Create index for time series lookups
+CREATE INDEX idx_metrics_history_content_recorded ON content_metrics_history(content_id, recorded_at);
@@ -0,0 +1,5 @@
+-- Drop indexes
+DROP INDEX IF EXISTS idx_contents_type_trending_score;
+
+-- Drop column
+ALTER TABLE contents DROP COLUMN IF EXISTS trending_score;
@@ -0,0 +1,5 @@
+-- Add engagement velocity score refreshed on every ingest
+ALTER TABLE contents ADD COLUMN trending_score DECIMAL(12, 4) DEFAULT 0;
+
+-- Create index for trending sort
+CREATE INDEX idx_contents_type_trending_score ON contents(type, trending_score DESC);
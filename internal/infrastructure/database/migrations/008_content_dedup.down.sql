@@ -0,0 +1,8 @@
+-- Drop indexes
+DROP INDEX IF EXISTS idx_contents_normalized_title;
+DROP INDEX IF EXISTS idx_contents_canonical_id;
+
+-- Drop columns
+ALTER TABLE contents DROP COLUMN IF EXISTS sim_hash;
+ALTER TABLE contents DROP COLUMN IF EXISTS normalized_title;
+ALTER TABLE contents DROP COLUMN IF EXISTS canonical_id;
@@ -0,0 +1,8 @@
+-- Add duplicate fingerprint and canonical link columns
+ALTER TABLE contents ADD COLUMN canonical_id BIGINT REFERENCES contents(id) ON DELETE SET NULL;
+ALTER TABLE contents ADD COLUMN normalized_title VARCHAR(500);
+ALTER TABLE contents ADD COLUMN sim_hash BIGINT DEFAULT 0;
+
+-- Create indexes for cluster lookups and exact-title matching
+CREATE INDEX idx_contents_canonical_id ON contents(canonical_id);
+CREATE INDEX idx_contents_normalized_title ON contents(normalized_title);
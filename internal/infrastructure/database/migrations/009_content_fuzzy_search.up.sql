@@ -0,0 +1,2 @@
+-- Enable trigram matching for typo-tolerant title search
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
@@ -0,0 +1,5 @@
+-- Drop indexes
+DROP INDEX IF EXISTS idx_search_queries_count;
+
+-- Drop table
+DROP TABLE IF EXISTS search_queries;
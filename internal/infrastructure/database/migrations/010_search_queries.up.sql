@@ -0,0 +1,9 @@
+-- Create query log table backing popular query suggestions
+CREATE TABLE search_queries (
+    query VARCHAR(255) PRIMARY KEY,
+    count INTEGER NOT NULL DEFAULT 0,
+    last_searched_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- Create index for loading the most popular queries
+CREATE INDEX idx_search_queries_count ON search_queries(count DESC);
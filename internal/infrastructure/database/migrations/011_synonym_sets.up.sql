@@ -0,0 +1,9 @@
+-- Create synonym sets used to expand search queries; rules is a JSON array of
+-- {"terms": [...], "synonyms": [...]} objects
+CREATE TABLE synonym_sets (
+    id BIGSERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL UNIQUE,
+    rules TEXT NOT NULL DEFAULT '[]',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
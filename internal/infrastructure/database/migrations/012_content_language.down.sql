@@ -0,0 +1,7 @@
+-- Restore the english-only title search index
+DROP INDEX IF EXISTS idx_contents_title_language_search;
+CREATE INDEX idx_contents_title_search ON contents USING gin(to_tsvector('english', title));
+
+-- Drop language function and column
+DROP FUNCTION IF EXISTS content_search_config(VARCHAR);
+ALTER TABLE contents DROP COLUMN IF EXISTS language;
@@ -0,0 +1,17 @@
+-- Track the language of content so that titles are stemmed with the matching configuration
+ALTER TABLE contents ADD COLUMN language VARCHAR(8) NOT NULL DEFAULT 'en';
+
+-- Map a content language to its text search configuration; immutable so that it can be indexed
+CREATE OR REPLACE FUNCTION content_search_config(language VARCHAR)
+RETURNS regconfig LANGUAGE sql IMMUTABLE AS $$
+    SELECT (CASE language
+        WHEN 'en' THEN 'english'
+        WHEN 'de' THEN 'german'
+        WHEN 'tr' THEN 'turkish'
+        ELSE 'simple'
+    END)::regconfig
+$$;
+
+-- Replace the english-only title search index with one analyzed per language
+DROP INDEX IF EXISTS idx_contents_title_search;
+CREATE INDEX idx_contents_title_language_search ON contents USING gin(to_tsvector(content_search_config(language), title));
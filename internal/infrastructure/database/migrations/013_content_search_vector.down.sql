@@ -0,0 +1,3 @@
+-- Drop search vector index and column
+DROP INDEX IF EXISTS idx_contents_search_vector;
+ALTER TABLE contents DROP COLUMN IF EXISTS search_vector;
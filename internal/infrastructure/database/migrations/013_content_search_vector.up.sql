@@ -0,0 +1,13 @@
+-- Add a weighted search vector: title (A), tag names (B) and provider (C)
+ALTER TABLE contents ADD COLUMN search_vector TSVECTOR;
+
+-- Fill it in for existing content; ingest keeps it up to date afterwards
+UPDATE contents SET search_vector =
+    setweight(to_tsvector(content_search_config(language), title), 'A') ||
+    setweight(to_tsvector(content_search_config(language), COALESCE((SELECT string_agg(tags.name, ' ')
+        FROM content_tags JOIN tags ON tags.id = content_tags.tag_id
+        WHERE content_tags.content_id = contents.id), '')), 'B') ||
+    setweight(to_tsvector(content_search_config(language), provider), 'C');
+
+-- Create index for matching the search vector
+CREATE INDEX idx_contents_search_vector ON contents USING gin(search_vector);
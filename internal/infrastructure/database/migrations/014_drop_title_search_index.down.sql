@@ -0,0 +1,2 @@
+-- Restore the per-language title search index
+CREATE INDEX idx_contents_title_language_search ON contents USING gin(to_tsvector(content_search_config(language), title));
@@ -0,0 +1,2 @@
+-- Titles are matched through the search vector, so the per-language title index is unused
+DROP INDEX IF EXISTS idx_contents_title_language_search;
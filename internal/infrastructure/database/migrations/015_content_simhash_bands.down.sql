@@ -0,0 +1,9 @@
+-- Drop simhash band indexes and columns
+DROP INDEX IF EXISTS idx_contents_sim_hash_band0;
+DROP INDEX IF EXISTS idx_contents_sim_hash_band1;
+DROP INDEX IF EXISTS idx_contents_sim_hash_band2;
+DROP INDEX IF EXISTS idx_contents_sim_hash_band3;
+ALTER TABLE contents DROP COLUMN IF EXISTS sim_hash_band0;
+ALTER TABLE contents DROP COLUMN IF EXISTS sim_hash_band1;
+ALTER TABLE contents DROP COLUMN IF EXISTS sim_hash_band2;
+ALTER TABLE contents DROP COLUMN IF EXISTS sim_hash_band3;
@@ -0,0 +1,18 @@
+-- Split the title simhash into four 16-bit bands; near-duplicates always share one
+ALTER TABLE contents ADD COLUMN sim_hash_band0 INTEGER DEFAULT 0;
+ALTER TABLE contents ADD COLUMN sim_hash_band1 INTEGER DEFAULT 0;
+ALTER TABLE contents ADD COLUMN sim_hash_band2 INTEGER DEFAULT 0;
+ALTER TABLE contents ADD COLUMN sim_hash_band3 INTEGER DEFAULT 0;
+
+-- Fill them in for existing content; ingest keeps them up to date afterwards
+UPDATE contents SET
+    sim_hash_band0 = sim_hash & 65535,
+    sim_hash_band1 = (sim_hash >> 16) & 65535,
+    sim_hash_band2 = (sim_hash >> 32) & 65535,
+    sim_hash_band3 = (sim_hash >> 48) & 65535;
+
+-- Create indexes for looking up duplicate candidates by band
+CREATE INDEX idx_contents_sim_hash_band0 ON contents(sim_hash_band0);
+CREATE INDEX idx_contents_sim_hash_band1 ON contents(sim_hash_band1);
+CREATE INDEX idx_contents_sim_hash_band2 ON contents(sim_hash_band2);
+CREATE INDEX idx_contents_sim_hash_band3 ON contents(sim_hash_band3);
@@ -0,0 +1,2 @@
+-- Drop the title trigram index
+DROP INDEX IF EXISTS idx_contents_title_trgm;
@@ -0,0 +1,2 @@
+-- Index titles by trigram so that fuzzy terms matched with the word similarity operator <% do not scan every row
+CREATE INDEX IF NOT EXISTS idx_contents_title_trgm ON contents USING gin(title gin_trgm_ops);
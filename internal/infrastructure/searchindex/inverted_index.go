@@ -0,0 +1,439 @@
+package searchindex
+
+import (
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"search-engine-go/internal/domain"
+
+	"go.uber.org/zap"
+)
+
+// snapshotVersion changes whenever the analyzer or the snapshot layout does, so that stale
+// snapshots are rebuilt instead of loaded
+const snapshotVersion = 3
+
+// Index is an in-process inverted index over the fields the SQL search matches title terms
+// against: content titles, tag names and providers. It keeps the position of every term for
+// phrase matching and the statistics BM25 ranks titles with, is updated incrementally as
+// content is stored and is snapshotted to disk so that it survives restarts. Fields are
+// analyzed in the language of their content, and query terms in the language of each item
+// they are matched against.
+type Index struct {
+	mu   sync.RWMutex
+	path string
+	docs map[int64][]string
+	// fields holds the terms of each tag name and of the provider of the indexed content
+	fields    map[int64][][]string
+	languages map[int64]string
+	// titles counts the indexed titles of each language
+	titles   map[string]int
+	postings map[string]map[int64][]int
+	corpus   *domain.BM25Corpus
+	// updatedAt is the latest update time of the indexed content, and dirty whether the index
+	// changed since it was last snapshotted
+	updatedAt time.Time
+	dirty     bool
+
+	stopCh   chan struct{}
+	stopOnce sync.Once
+}
+
+// snapshot is the on-disk form of an index; postings are rebuilt from the documents on load
+type snapshot struct {
+	Version   int
+	Docs      map[int64][]string
+	Fields    map[int64][][]string
+	Languages map[int64]string
+	UpdatedAt time.Time
+}
+
+// New returns an empty index that is kept in memory only
+func New() *Index {
+	return &Index{
+		docs:      make(map[int64][]string),
+		fields:    make(map[int64][][]string),
+		languages: make(map[int64]string),
+		titles:    make(map[string]int),
+		postings:  make(map[string]map[int64][]int),
+		corpus:    domain.NewBM25Corpus(),
+		stopCh:    make(chan struct{}),
+	}
+}
+
+// Open loads the snapshot at path, or returns an empty index when there is none yet or it was
+// written by an older version. The index is snapshotted back to path by Save, Watch and
+// Shutdown.
+func Open(path string) (*Index, error) {
+	index := New()
+	index.path = path
+
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return index, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open search index snapshot: %w", err)
+	}
+	defer file.Close()
+
+	var snap snapshot
+	if err := gob.NewDecoder(file).Decode(&snap); err != nil {
+		return nil, fmt.Errorf("failed to read search index snapshot: %w", err)
+	}
+	if snap.Version != snapshotVersion {
+		return index, nil
+	}
+	for id, terms := range snap.Docs {
+		index.put(id, snap.Languages[id], terms, snap.Fields[id])
+	}
+	index.updatedAt = snap.UpdatedAt
+	return index, nil
+}
+
+// Len returns the number of indexed titles
+func (i *Index) Len() int {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	return len(i.docs)
+}
+
+// IndexedUntil returns the latest update time of the indexed content. Content updated after it
+// is missing from the index, for instance when it was stored after the snapshot was written.
+func (i *Index) IndexedUntil() time.Time {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	return i.updatedAt
+}
+
+// Index adds new content and replaces updated content, which must come with its tags. Updates
+// are kept in memory until the next snapshot.
+func (i *Index) Index(contents []*domain.Content) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	for _, content := range contents {
+		language := domain.ContentLanguage(content)
+		i.remove(content.ID)
+		i.put(content.ID, language, domain.AnalyzeTextIn(content.Title, language), analyzeFields(content, language))
+		if content.UpdatedAt.After(i.updatedAt) {
+			i.updatedAt = content.UpdatedAt
+		}
+	}
+	i.dirty = true
+	return nil
+}
+
+// Watch snapshots the index every interval while it has unsaved updates, until Shutdown
+func (i *Index) Watch(interval time.Duration, log *zap.Logger) {
+	if interval <= 0 || i.path == "" {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				if err := i.Save(); err != nil {
+					log.Warn("Failed to save search index", zap.Error(err))
+				}
+			case <-i.stopCh:
+				log.Info("Search index snapshot goroutine stopped")
+				return
+			}
+		}
+	}()
+}
+
+// Shutdown stops Watch and writes the updates it has not snapshotted yet
+func (i *Index) Shutdown() error {
+	i.stopOnce.Do(func() {
+		close(i.stopCh)
+	})
+	return i.Save()
+}
+
+// Save writes the index to its path, replacing the previous snapshot atomically. It does
+// nothing when the index has no path or no updates since the last snapshot.
+func (i *Index) Save() error {
+	if i.path == "" || !i.takeDirty() {
+		return nil
+	}
+	if err := i.write(); err != nil {
+		i.mu.Lock()
+		i.dirty = true
+		i.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// takeDirty reports whether the index has unsaved updates and marks them as saved
+func (i *Index) takeDirty() bool {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	dirty := i.dirty
+	i.dirty = false
+	return dirty
+}
+
+func (i *Index) write() error {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	if err := os.MkdirAll(filepath.Dir(i.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create search index directory: %w", err)
+	}
+	file, err := os.CreateTemp(filepath.Dir(i.path), filepath.Base(i.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write search index snapshot: %w", err)
+	}
+	defer os.Remove(file.Name())
+
+	if err := gob.NewEncoder(file).Encode(snapshot{Version: snapshotVersion, Docs: i.docs, Fields: i.fields, Languages: i.languages, UpdatedAt: i.updatedAt}); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write search index snapshot: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write search index snapshot: %w", err)
+	}
+	if err := os.Rename(file.Name(), i.path); err != nil {
+		return fmt.Errorf("failed to write search index snapshot: %w", err)
+	}
+	return nil
+}
+
+// MatchTitle returns the IDs of the content in the given language, or in any language when it
+// is empty, whose title, tag names and provider contain every term of a word, or whose title,
+// a tag name or provider contains the terms of a phrase next to each other. Fuzzy words also match indexed terms within the edit distance the fuzzy setting
+// allows.
+func (i *Index) MatchTitle(term *domain.TermNode, fuzzy, language string) []int64 {
+	languages := domain.Languages
+	if language != "" {
+		languages = []string{language}
+	}
+	edits := 0
+	if !term.Phrase {
+		edits = domain.NewFuzzySpecification().MaxEdits(fuzzy, term.Value)
+	}
+
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	var matched map[int64]bool
+	for _, language := range languages {
+		if i.titles[language] == 0 {
+			continue
+		}
+		ids := i.matchTitleIn(term, edits, language)
+		if ids == nil {
+			continue
+		}
+		if matched == nil {
+			matched = make(map[int64]bool)
+		}
+		for id := range ids {
+			if i.languages[id] == language {
+				matched[id] = true
+			}
+		}
+	}
+	if matched == nil {
+		return nil
+	}
+	return sortedIDs(matched)
+}
+
+// matchTitleIn matches a term analyzed in one language against every title, returning nil
+// when the term has no index terms in that language
+func (i *Index) matchTitleIn(term *domain.TermNode, edits int, language string) map[int64]bool {
+	terms := domain.AnalyzeTextIn(term.Value, language)
+	if len(terms) == 0 {
+		return nil
+	}
+	if term.Phrase {
+		return i.matchPhrase(terms)
+	}
+
+	var matched map[int64]bool
+	for _, t := range terms {
+		ids := i.matchTerm(t, edits)
+		if matched != nil {
+			for id := range matched {
+				if !ids[id] {
+					delete(matched, id)
+				}
+			}
+		} else {
+			matched = ids
+		}
+	}
+	return matched
+}
+
+// RankTitles returns the BM25 rank of the titles of ids for the terms of a query
+func (i *Index) RankTitles(terms []*domain.TermNode, ids []int64) map[int64]float64 {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	queries := make(map[string][]string)
+	ranks := make(map[int64]float64, len(ids))
+	for _, id := range ids {
+		language := i.languages[id]
+		query, ok := queries[language]
+		if !ok {
+			query = analyzeQuery(terms, language)
+			queries[language] = query
+		}
+		ranks[id] = i.corpus.Score(query, i.docs[id])
+	}
+	return ranks
+}
+
+// analyzeQuery returns the distinct index terms of query terms in a language
+func analyzeQuery(terms []*domain.TermNode, language string) []string {
+	seen := make(map[string]bool)
+	var query []string
+	for _, term := range terms {
+		for _, t := range domain.AnalyzeTextIn(term.Value, language) {
+			if !seen[t] {
+				seen[t] = true
+				query = append(query, t)
+			}
+		}
+	}
+	return query
+}
+
+// matchTerm returns the content containing a term, or a term within edits of it
+func (i *Index) matchTerm(term string, edits int) map[int64]bool {
+	ids := make(map[int64]bool)
+	for id := range i.postings[term] {
+		ids[id] = true
+	}
+	if edits == 0 {
+		return ids
+	}
+
+	fuzzySpec := domain.NewFuzzySpecification()
+	for indexed, postings := range i.postings {
+		if indexed == term || !fuzzySpec.IsMatch(term, indexed, edits) {
+			continue
+		}
+		for id := range postings {
+			ids[id] = true
+		}
+	}
+	return ids
+}
+
+// matchPhrase returns the content containing the terms at consecutive positions
+func (i *Index) matchPhrase(terms []string) map[int64]bool {
+	ids := make(map[int64]bool)
+	for id, positions := range i.postings[terms[0]] {
+		for _, start := range positions {
+			if i.hasPhraseAt(id, terms[1:], start+1) {
+				ids[id] = true
+				break
+			}
+		}
+	}
+	return ids
+}
+
+func (i *Index) hasPhraseAt(id int64, terms []string, position int) bool {
+	for offset, term := range terms {
+		found := false
+		for _, p := range i.postings[term][id] {
+			if p == position+offset {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// analyzeFields returns the terms of each tag name and of the provider of content
+func analyzeFields(content *domain.Content, language string) [][]string {
+	fields := make([][]string, 0, len(content.Tags)+1)
+	for _, tag := range content.Tags {
+		fields = append(fields, domain.AnalyzeTextIn(tag.Name, language))
+	}
+	return append(fields, domain.AnalyzeTextIn(content.Provider, language))
+}
+
+// put indexes the title and the other fields of content. Each field starts one position after
+// the end of the previous one, so that phrases do not match across fields.
+func (i *Index) put(id int64, language string, terms []string, fields [][]string) {
+	i.docs[id] = terms
+	i.fields[id] = fields
+	i.languages[id] = language
+	i.titles[language]++
+	i.corpus.Add(terms)
+
+	position := i.addPostings(id, terms, 0)
+	for _, field := range fields {
+		position = i.addPostings(id, field, position+1)
+	}
+}
+
+// addPostings records terms at consecutive positions from start and returns the position after
+// the last one
+func (i *Index) addPostings(id int64, terms []string, start int) int {
+	for offset, term := range terms {
+		postings, ok := i.postings[term]
+		if !ok {
+			postings = make(map[int64][]int)
+			i.postings[term] = postings
+		}
+		postings[id] = append(postings[id], start+offset)
+	}
+	return start + len(terms)
+}
+
+func (i *Index) remove(id int64) {
+	terms, ok := i.docs[id]
+	if !ok {
+		return
+	}
+	fields := i.fields[id]
+	delete(i.docs, id)
+	delete(i.fields, id)
+	i.titles[i.languages[id]]--
+	delete(i.languages, id)
+	i.corpus.Remove(terms)
+	i.removePostings(id, terms)
+	for _, field := range fields {
+		i.removePostings(id, field)
+	}
+}
+
+func (i *Index) removePostings(id int64, terms []string) {
+	for _, term := range terms {
+		delete(i.postings[term], id)
+		if len(i.postings[term]) == 0 {
+			delete(i.postings, term)
+		}
+	}
+}
+
+func sortedIDs(set map[int64]bool) []int64 {
+	ids := make([]int64, 0, len(set))
+	for id := range set {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(a, b int) bool { return ids[a] < ids[b] })
+	return ids
+}
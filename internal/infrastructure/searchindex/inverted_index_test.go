@@ -0,0 +1,197 @@
+package searchindex
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"search-engine-go/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testContents() []*domain.Content {
+	return []*domain.Content{
+		{ID: 1, Title: "Go Programming Tutorial"},
+		{ID: 2, Title: "Advanced Go Tutorials"},
+		{ID: 3, Title: "Tutorial: Programming in Rust"},
+		{ID: 4, Title: "Kubernetes Networking"},
+	}
+}
+
+func TestIndex_MatchTitle(t *testing.T) {
+	index := New()
+	require.NoError(t, index.Index(testContents()))
+
+	tests := []struct {
+		name  string
+		term  *domain.TermNode
+		fuzzy string
+		want  []int64
+	}{
+		{"Stemmed word", &domain.TermNode{Field: domain.QueryFieldTitle, Value: "tutorials"}, "", []int64{1, 2, 3}},
+		{"Case insensitive", &domain.TermNode{Field: domain.QueryFieldTitle, Value: "KUBERNETES"}, "", []int64{4}},
+		{"Every word of a term", &domain.TermNode{Field: domain.QueryFieldTitle, Value: "go-tutorial"}, "", []int64{1, 2}},
+		{"Phrase in order", &domain.TermNode{Field: domain.QueryFieldTitle, Value: "programming tutorial", Phrase: true}, "", []int64{1}},
+		{"Phrase out of order", &domain.TermNode{Field: domain.QueryFieldTitle, Value: "tutorial programming", Phrase: true}, "", []int64{3}},
+		{"No match", &domain.TermNode{Field: domain.QueryFieldTitle, Value: "python"}, "", []int64{}},
+		{"Typo without fuzzy", &domain.TermNode{Field: domain.QueryFieldTitle, Value: "kubermetes"}, "", []int64{}},
+		{"Typo with fuzzy", &domain.TermNode{Field: domain.QueryFieldTitle, Value: "kubermetes"}, "auto", []int64{4}},
+		{"Stopwords only", &domain.TermNode{Field: domain.QueryFieldTitle, Value: "the"}, "", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, index.MatchTitle(tt.term, tt.fuzzy, ""))
+		})
+	}
+}
+
+func TestIndex_MatchFields(t *testing.T) {
+	index := New()
+	require.NoError(t, index.Index([]*domain.Content{
+		{ID: 1, Title: "Weekly Roundup", Provider: "provider1", Tags: []domain.Tag{{Name: "golang"}, {Name: "web development"}}},
+		{ID: 2, Title: "Development Diaries", Provider: "provider2", Tags: []domain.Tag{{Name: "web"}}},
+	}))
+
+	tests := []struct {
+		name string
+		term *domain.TermNode
+		want []int64
+	}{
+		{"Tag name", &domain.TermNode{Field: domain.QueryFieldTitle, Value: "golang"}, []int64{1}},
+		{"Provider", &domain.TermNode{Field: domain.QueryFieldTitle, Value: "provider2"}, []int64{2}},
+		{"Words across fields", &domain.TermNode{Field: domain.QueryFieldTitle, Value: "roundup golang"}, []int64{1}},
+		{"Phrase in a tag", &domain.TermNode{Field: domain.QueryFieldTitle, Value: "web development", Phrase: true}, []int64{1}},
+		{"Phrase across fields", &domain.TermNode{Field: domain.QueryFieldTitle, Value: "diaries web", Phrase: true}, []int64{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, index.MatchTitle(tt.term, "", ""))
+		})
+	}
+}
+
+func TestIndex_RankTitles(t *testing.T) {
+	index := New()
+	require.NoError(t, index.Index(testContents()))
+
+	terms := []*domain.TermNode{{Field: domain.QueryFieldTitle, Value: "go"}, {Field: domain.QueryFieldTitle, Value: "tutorial"}}
+	ranks := index.RankTitles(terms, []int64{1, 2, 3, 4})
+
+	assert.Greater(t, ranks[1], ranks[3])
+	assert.Greater(t, ranks[2], ranks[3])
+	assert.Equal(t, 0.0, ranks[4])
+}
+
+func TestIndex_Languages(t *testing.T) {
+	index := New()
+	require.NoError(t, index.Index([]*domain.Content{
+		{ID: 1, Title: "Die schönsten Häuser", Language: domain.LanguageGerman},
+		{ID: 2, Title: "Kitaplardan öğrenmek", Language: domain.LanguageTurkish},
+		{ID: 3, Title: "Haus music history", Language: domain.LanguageEnglish},
+	}))
+
+	tests := []struct {
+		name     string
+		value    string
+		language string
+		want     []int64
+	}{
+		{"German plural matches singular", "haus", domain.LanguageGerman, []int64{1}},
+		{"Turkish case ending matches stem", "kitap", domain.LanguageTurkish, []int64{2}},
+		{"Any language", "haus", "", []int64{1, 3}},
+		{"German stopword", "die", domain.LanguageGerman, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			term := &domain.TermNode{Field: domain.QueryFieldTitle, Value: tt.value}
+			assert.Equal(t, tt.want, index.MatchTitle(term, "", tt.language))
+		})
+	}
+}
+
+func TestIndex_IncrementalUpdates(t *testing.T) {
+	index := New()
+	require.NoError(t, index.Index(testContents()))
+	rust := &domain.TermNode{Field: domain.QueryFieldTitle, Value: "rust"}
+	before := index.RankTitles([]*domain.TermNode{rust}, []int64{3})[3]
+
+	require.NoError(t, index.Index([]*domain.Content{
+		{ID: 4, Title: "Rust Networking"},
+		{ID: 5, Title: "Python Basics"},
+	}))
+
+	assert.Equal(t, 5, index.Len())
+	assert.Equal(t, []int64{3, 4}, index.MatchTitle(rust, "", ""))
+	assert.Empty(t, index.MatchTitle(&domain.TermNode{Field: domain.QueryFieldTitle, Value: "kubernetes"}, "", ""))
+	assert.Equal(t, []int64{5}, index.MatchTitle(&domain.TermNode{Field: domain.QueryFieldTitle, Value: "python"}, "", ""))
+	// rust is now less rare, so the same title ranks lower
+	assert.Less(t, index.RankTitles([]*domain.TermNode{rust}, []int64{3})[3], before)
+}
+
+func TestIndex_Snapshot(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "index", "search.gob")
+
+	t.Run("Missing snapshot opens empty", func(t *testing.T) {
+		index, err := Open(path)
+
+		require.NoError(t, err)
+		assert.Equal(t, 0, index.Len())
+	})
+
+	t.Run("Updates are kept in memory until saved", func(t *testing.T) {
+		index, err := Open(path)
+		require.NoError(t, err)
+		require.NoError(t, index.Index(testContents()))
+
+		reopened, err := Open(path)
+
+		require.NoError(t, err)
+		assert.Equal(t, 0, reopened.Len())
+	})
+
+	t.Run("Saved updates are written to the snapshot", func(t *testing.T) {
+		index, err := Open(path)
+		require.NoError(t, err)
+		require.NoError(t, index.Index(testContents()))
+		require.NoError(t, index.Save())
+
+		reopened, err := Open(path)
+
+		require.NoError(t, err)
+		assert.Equal(t, 4, reopened.Len())
+		term := &domain.TermNode{Field: domain.QueryFieldTitle, Value: "programming tutorial", Phrase: true}
+		assert.Equal(t, index.MatchTitle(term, "", ""), reopened.MatchTitle(term, "", ""))
+		terms := []*domain.TermNode{{Field: domain.QueryFieldTitle, Value: "go"}}
+		assert.Equal(t, index.RankTitles(terms, []int64{1, 2}), reopened.RankTitles(terms, []int64{1, 2}))
+	})
+
+	t.Run("Snapshot keeps the latest indexed update", func(t *testing.T) {
+		updatedAt := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+		index, err := Open(path)
+		require.NoError(t, err)
+		require.NoError(t, index.Index([]*domain.Content{
+			{ID: 5, Title: "Python Basics", UpdatedAt: updatedAt},
+			{ID: 6, Title: "Rust Basics", UpdatedAt: updatedAt.Add(-time.Hour)},
+		}))
+		require.NoError(t, index.Shutdown())
+
+		reopened, err := Open(path)
+
+		require.NoError(t, err)
+		assert.True(t, updatedAt.Equal(reopened.IndexedUntil()))
+	})
+
+	t.Run("Corrupt snapshot fails", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(path, []byte("not a snapshot"), 0o644))
+
+		_, err := Open(path)
+
+		assert.Error(t, err)
+	})
+}
@@ -4,120 +4,1831 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"search-engine-go/internal/domain"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type ContentRepository struct {
-	db *gorm.DB
+	db        *gorm.DB
+	relevance *domain.RelevanceSpecification
+	// backend matches and ranks titles in place of the database's text search when set
+	backend SearchBackend
+	// vocabulary holds the words fuzzy terms are expanded against on SQLite
+	vocabulary *searchVocabulary
+	// corpus holds the statistics relevance is ranked against in-process
+	corpus *relevanceCorpus
+	// afterCommit collects the in-memory updates of a repository bound to a transaction by
+	// Transaction, which are applied once it commits
+	afterCommit *[]func() error
 }
 
 func NewContentRepository(db *gorm.DB) *ContentRepository {
-	return &ContentRepository{db: db}
+	return &ContentRepository{
+		db:         db,
+		relevance:  domain.NewRelevanceSpecification(domain.DefaultRelevanceWeights),
+		vocabulary: newSearchVocabulary(),
+		corpus:     newRelevanceCorpus(),
+	}
+}
+
+// Transaction runs fn with a repository whose writes all happen in one database transaction.
+// Updates to the title vocabulary, the relevance corpus and the search backend are applied once
+// it commits.
+func (r *ContentRepository) Transaction(ctx context.Context, fn func(repo *ContentRepository) error) error {
+	if r.afterCommit != nil {
+		return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			repo := *r
+			repo.db = tx
+			return fn(&repo)
+		})
+	}
+
+	var committed []func() error
+	if err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		repo := *r
+		repo.db = tx
+		repo.afterCommit = &committed
+		return fn(&repo)
+	}); err != nil {
+		return err
+	}
+	for _, apply := range committed {
+		if err := apply(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// onCommit applies an in-memory update once the repository's transaction commits, or right
+// away outside of one
+func (r *ContentRepository) onCommit(apply func() error) error {
+	if r.afterCommit != nil {
+		*r.afterCommit = append(*r.afterCommit, apply)
+		return nil
+	}
+	return apply()
+}
+
+// SetRelevanceWeights changes how sort_by=relevance blends text relevance with the stored score
+func (r *ContentRepository) SetRelevanceWeights(weights domain.RelevanceWeights) {
+	r.relevance = domain.NewRelevanceSpecification(weights)
+}
+
+// SetSearchBackend makes searches match and rank titles with backend, and keeps it up to date
+// with stored content
+func (r *ContentRepository) SetSearchBackend(backend SearchBackend) {
+	r.backend = backend
+}
+
+// ReindexSearchBackend feeds the search backend the content stored or updated since the latest
+// update it has indexed: all of it when it starts without a snapshot, and the content stored
+// after the snapshot was written otherwise
+func (r *ContentRepository) ReindexSearchBackend(ctx context.Context) error {
+	if r.backend == nil {
+		return nil
+	}
+	query := r.db.WithContext(ctx).Model(&domain.Content{}).
+		Select("id", "title", "language", "provider", "updated_at").
+		Preload("Tags")
+	// content updated in the same instant as the latest indexed update may still be missing
+	if until := r.backend.IndexedUntil(); !until.IsZero() {
+		query = query.Where("updated_at >= ?", until)
+	}
+	var batch []*domain.Content
+	return query.
+		FindInBatches(&batch, 500, func(tx *gorm.DB, _ int) error {
+			return r.backend.Index(batch)
+		}).Error
+}
+
+func (r *ContentRepository) isRecordNotFound(err error) bool {
+	return errors.Is(err, gorm.ErrRecordNotFound)
+}
+
+func (r *ContentRepository) hasError(err error) bool {
+	return err != nil
+}
+
+func (r *ContentRepository) Search(ctx context.Context, req *domain.SearchRequest) ([]*domain.Content, int, error) {
+	var contents []*domain.Content
+	var total int
+	err := r.withTrigramThreshold(ctx, req, func(repo *ContentRepository) error {
+		var err error
+		contents, total, err = repo.search(ctx, req)
+		return err
+	})
+	return contents, total, err
+}
+
+func (r *ContentRepository) search(ctx context.Context, req *domain.SearchRequest) ([]*domain.Content, int, error) {
+	offset := (req.Page - 1) * req.PageSize
+	if req.After != nil {
+		offset = 0
+	}
+	if r.ranksInProcess(req) {
+		return r.searchByRelevance(ctx, req)
+	}
+
+	query, err := r.searchQuery(ctx, req)
+	if err != nil {
+		return nil, 0, err
+	}
+	keys, err := r.sortKeys(req)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if req.Collapse != "" {
+		return r.searchCollapsed(ctx, query, req, keys)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	page := query.Order(clause.OrderBy{Expression: r.searchOrder(keys)})
+	if req.After != nil {
+		after, err := r.afterCondition(keys, req.After)
+		if err != nil {
+			return nil, 0, err
+		}
+		page = page.Where(after)
+	}
+
+	var contents []*domain.Content
+	if err := page.Preload("Tags").Offset(offset).Limit(req.PageSize).Find(&contents).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if err := r.attachSources(r.db.WithContext(ctx), contents); err != nil {
+		return nil, 0, err
+	}
+
+	if err := r.attachHighlights(ctx, contents, req); err != nil {
+		return nil, 0, err
+	}
+
+	if err := r.attachRelevance(ctx, contents, keys); err != nil {
+		return nil, 0, err
+	}
+
+	return contents, int(total), nil
+}
+
+// NextCursor returns the cursor of the page following contents, or "" when nothing follows.
+// It works for offset pages too, so a client can switch to cursors after the first page.
+func (r *ContentRepository) NextCursor(ctx context.Context, req *domain.SearchRequest, contents []*domain.Content) (string, error) {
+	if len(contents) == 0 || len(contents) < req.PageSize {
+		return "", nil
+	}
+	var cursor string
+	err := r.withTrigramThreshold(ctx, req, func(repo *ContentRepository) error {
+		var err error
+		cursor, err = repo.nextCursor(ctx, req, contents)
+		return err
+	})
+	return cursor, err
+}
+
+func (r *ContentRepository) nextCursor(ctx context.Context, req *domain.SearchRequest, contents []*domain.Content) (string, error) {
+	last := contents[len(contents)-1]
+
+	if r.ranksInProcess(req) {
+		return r.nextRelevanceCursor(ctx, req, last)
+	}
+
+	keys, err := r.sortKeys(req)
+	if err != nil {
+		return "", err
+	}
+	cursor, err := r.cursorAfter(ctx, req, keys, last)
+	if err != nil {
+		return "", err
+	}
+	after, err := r.afterCondition(keys, cursor)
+	if err != nil {
+		return "", err
+	}
+
+	query, err := r.searchQuery(ctx, req)
+	if err != nil {
+		return "", err
+	}
+	if req.Collapse != "" {
+		query = r.collapsedGroups(ctx, query, req, keys)
+	}
+	var ids []int64
+	if err := query.Where(after).Limit(1).Pluck("id", &ids).Error; err != nil {
+		return "", err
+	}
+	if len(ids) == 0 {
+		return "", nil
+	}
+	return cursor.Encode()
+}
+
+// cursorAfter returns the keyset position of last, looking up its exact-match rank when the
+// search ranks exact matches first
+func (r *ContentRepository) cursorAfter(ctx context.Context, req *domain.SearchRequest, keys []sortKey, last *domain.Content) (*domain.SearchCursor, error) {
+	exact := 0
+	if keys[0].field == exactRankField {
+		if err := r.db.WithContext(ctx).Model(&domain.Content{}).
+			Select(keys[0].expr, keys[0].vars...).
+			Where("id = ?", last.ID).
+			Row().Scan(&exact); err != nil {
+			return nil, err
+		}
+	}
+	cursorSpec := domain.NewCursorSpecification()
+	return cursorSpec.CursorAfter(req, last, exact)
+}
+
+// ExportSearch passes every match of a search to fn in batches of batchSize, in search order.
+// Unlike paging through Search it never counts the matches: each batch is a single keyset
+// query for the rows after the last one of the previous batch, so the work per batch and the
+// memory use do not grow with the number of matches. sort_by=relevance is therefore always
+// ranked by the database, which SQLite cannot do. The page and cursor of the request are
+// ignored.
+func (r *ContentRepository) ExportSearch(ctx context.Context, req *domain.SearchRequest, batchSize int, fn func([]*domain.Content) error) error {
+	return r.withTrigramThreshold(ctx, req, func(repo *ContentRepository) error {
+		return repo.exportSearch(ctx, req, batchSize, fn)
+	})
+}
+
+func (r *ContentRepository) exportSearch(ctx context.Context, req *domain.SearchRequest, batchSize int, fn func([]*domain.Content) error) error {
+	if !r.isPostgreSQL() && r.ranksInProcess(req) {
+		return domain.NewInvalidInputError("sort_by", "relevance exports need PostgreSQL text ranking")
+	}
+
+	keys, err := r.sortKeys(req)
+	if err != nil {
+		return err
+	}
+	query, err := r.searchQuery(ctx, req)
+	if err != nil {
+		return err
+	}
+	if req.Collapse != "" {
+		query = r.collapsedGroups(ctx, query, req, keys)
+	}
+	// a new session lets every batch add its own keyset condition to the same filters
+	query = query.Session(&gorm.Session{})
+
+	var after *domain.SearchCursor
+	for {
+		batch := query
+		if after != nil {
+			condition, err := r.afterCondition(keys, after)
+			if err != nil {
+				return err
+			}
+			batch = batch.Where(condition)
+		}
+		var ids []int64
+		if err := batch.Order(clause.OrderBy{Expression: r.searchOrder(keys)}).
+			Limit(batchSize).
+			Pluck("id", &ids).Error; err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+
+		contents, err := r.findInOrder(ctx, ids)
+		if err != nil {
+			return err
+		}
+		if err := r.attachRelevance(ctx, contents, keys); err != nil {
+			return err
+		}
+		if err := fn(contents); err != nil {
+			return err
+		}
+		if len(ids) < batchSize {
+			return nil
+		}
+		if after, err = r.cursorAfter(ctx, req, keys, contents[len(contents)-1]); err != nil {
+			return err
+		}
+	}
+}
+
+// findInOrder loads the content with the given IDs, with their tags, in the order of ids
+func (r *ContentRepository) findInOrder(ctx context.Context, ids []int64) ([]*domain.Content, error) {
+	var found []*domain.Content
+	if err := r.db.WithContext(ctx).Preload("Tags").Where("id IN ?", ids).Find(&found).Error; err != nil {
+		return nil, err
+	}
+	byID := make(map[int64]*domain.Content, len(found))
+	for _, content := range found {
+		byID[content.ID] = content
+	}
+
+	contents := make([]*domain.Content, 0, len(ids))
+	for _, id := range ids {
+		if content, ok := byID[id]; ok {
+			contents = append(contents, content)
+		}
+	}
+	return contents, nil
+}
+
+// searchQuery builds the canonical content query with every search filter applied
+func (r *ContentRepository) searchQuery(ctx context.Context, req *domain.SearchRequest) (*gorm.DB, error) {
+	query := r.db.WithContext(ctx).Model(&domain.Content{}).Where("canonical_id IS NULL")
+
+	node, err := domain.SearchQuery(req)
+	if err != nil {
+		return nil, err
+	}
+	compiler, err := r.newQueryCompiler(ctx, req.Fuzzy, req.Language)
+	if err != nil {
+		return nil, err
+	}
+	for _, node := range []domain.QueryNode{node, req.Filter} {
+		if node == nil {
+			continue
+		}
+		condition, args, err := compiler.compile(node)
+		if err != nil {
+			return nil, err
+		}
+		query = query.Where(condition, args...)
+	}
+
+	if req.ContentType != nil {
+		query = query.Where("type = ?", *req.ContentType)
+	}
+
+	if req.Language != "" {
+		query = query.Where("language = ?", req.Language)
+	}
+
+	if len(req.Tags) > 0 {
+		query = r.applyTagFilter(query, req.Tags, req.TagMode)
+	}
+
+	// a duration of 0 means the provider gave none or an unparseable one, so duration bounds
+	// only match content whose duration is known
+	if req.MinDuration != nil || req.MaxDuration != nil {
+		query = query.Where("duration > 0")
+	}
+
+	if req.MinDuration != nil {
+		query = query.Where("duration >= ?", *req.MinDuration)
+	}
+
+	if req.MaxDuration != nil {
+		query = query.Where("duration <= ?", *req.MaxDuration)
+	}
+
+	if req.PublishedAfterTime != nil {
+		query = query.Where("created_at >= ?", *req.PublishedAfterTime)
+	}
+
+	if req.PublishedBeforeTime != nil {
+		query = query.Where("created_at < ?", *req.PublishedBeforeTime)
+	}
+
+	if req.MinViews != nil {
+		query = query.Where("views >= ?", *req.MinViews)
+	}
+
+	if req.MaxViews != nil {
+		query = query.Where("views <= ?", *req.MaxViews)
+	}
+
+	if req.MinLikes != nil {
+		query = query.Where("likes >= ?", *req.MinLikes)
+	}
+
+	if req.MaxLikes != nil {
+		query = query.Where("likes <= ?", *req.MaxLikes)
+	}
+
+	if req.MinScore != nil {
+		query = query.Where("score >= ?", *req.MinScore)
+	}
+
+	if req.MaxScore != nil {
+		query = query.Where("score <= ?", *req.MaxScore)
+	}
+
+	return query, nil
+}
+
+// withTrigramThreshold runs fn with a repository on which the fuzzy title terms of req can use
+// the title trigram index. The <% operator compares word similarity with the
+// pg_trgm.word_similarity_threshold setting rather than a bound value, so on PostgreSQL fn runs
+// in a transaction with the setting lowered to the lowest threshold of the terms.
+func (r *ContentRepository) withTrigramThreshold(ctx context.Context, req *domain.SearchRequest, fn func(repo *ContentRepository) error) error {
+	if !r.isPostgreSQL() || r.backend != nil {
+		return fn(r)
+	}
+	node, err := domain.SearchQuery(req)
+	if err != nil {
+		// searchQuery reports the error
+		return fn(r)
+	}
+	threshold, ok := fuzzyThreshold(req.Fuzzy, node, req.Filter)
+	if !ok {
+		return fn(r)
+	}
+	return r.Transaction(ctx, func(repo *ContentRepository) error {
+		if err := repo.db.WithContext(ctx).
+			Exec("SELECT set_config('pg_trgm.word_similarity_threshold', ?, true)", strconv.FormatFloat(threshold, 'f', -1, 64)).
+			Error; err != nil {
+			return err
+		}
+		return fn(repo)
+	})
+}
+
+// newQueryCompiler returns a compiler for the given fuzzy setting and language. SQLite has no
+// trigram support, so fuzzy terms are matched in-process against the search vocabulary instead.
+func (r *ContentRepository) newQueryCompiler(ctx context.Context, fuzzy, language string) (*queryCompiler, error) {
+	compiler := &queryCompiler{postgres: r.isPostgreSQL(), language: language, fuzzy: fuzzy, backend: r.backend}
+	if fuzzy == "" || fuzzy == "0" || compiler.postgres || compiler.backend != nil {
+		return compiler, nil
+	}
+
+	if err := r.vocabulary.load(ctx, r.db); err != nil {
+		return nil, err
+	}
+	compiler.vocabulary = r.vocabulary
+	return compiler, nil
+}
+
+// exactRankField names the sort key that ranks exact matches ahead of fuzzy ones
+const exactRankField = "exact"
+
+// sortKey is one ORDER BY term of a search
+type sortKey struct {
+	field string
+	expr  string
+	vars  []interface{}
+	desc  bool
+}
+
+// sortKeys returns the ORDER BY terms of a search. With fuzzy matching enabled, items matching
+// the query exactly rank ahead of those that only match fuzzily. The ID always comes last so
+// that the order is total, which keyset pagination relies on.
+func (r *ContentRepository) sortKeys(req *domain.SearchRequest) ([]sortKey, error) {
+	var keys []sortKey
+	exact, err := r.exactRankKey(req)
+	if err != nil {
+		return nil, err
+	}
+	if exact != nil {
+		keys = append(keys, *exact)
+	}
+
+	for _, field := range domain.SearchSortFields(req) {
+		if field.Field == domain.SortByRelevance {
+			key, err := r.relevanceKey(req)
+			if err != nil {
+				return nil, err
+			}
+			key.desc = field.Order == "desc"
+			keys = append(keys, key)
+			continue
+		}
+		keys = append(keys, sortKey{field: field.Field, expr: domain.DocumentSortFields[field.Field], desc: field.Order == "desc"})
+	}
+
+	return append(keys, sortKey{field: "id", expr: "id"}), nil
+}
+
+// exactRankKey returns the key ranking exact matches first, or nil when fuzzy matching is off
+func (r *ContentRepository) exactRankKey(req *domain.SearchRequest) (*sortKey, error) {
+	if req.Fuzzy == "" || req.Fuzzy == "0" {
+		return nil, nil
+	}
+	node, err := domain.SearchQuery(req)
+	if err != nil || node == nil {
+		return nil, err
+	}
+	exact := &queryCompiler{postgres: r.isPostgreSQL(), language: req.Language, backend: r.backend}
+	condition, args, err := exact.compile(node)
+	if err != nil {
+		return nil, err
+	}
+	return &sortKey{field: exactRankField, expr: "CASE WHEN " + condition + " THEN 0 ELSE 1 END", vars: args}, nil
+}
+
+// relevanceKey returns the PostgreSQL sort key of sort_by=relevance: ts_rank_cd of the search
+// vector against the query's title terms, with the field boosts as the weights of A, B and C,
+// normalized to [0, 1) by option 32, blended with the normalized score. Everything is computed
+// in float8 so that cursor values compare exactly.
+func (r *ContentRepository) relevanceKey(req *domain.SearchRequest) (sortKey, error) {
+	node, err := domain.SearchQuery(req)
+	if err != nil {
+		return sortKey{}, err
+	}
+	weights := r.relevance.Weights()
+	scoreExpr := "?::float8 * CASE WHEN score > 0 THEN score::float8 / (score::float8 + ?::float8) ELSE 0 END"
+	scoreVars := []interface{}{weights.Score, domain.RelevanceScoreScale}
+
+	tsQuery, tsVars := r.titleTSQuery(domain.HighlightTerms(node, req.Filter))
+	if tsQuery == "" {
+		return sortKey{field: domain.SortByRelevance, expr: "(" + scoreExpr + ")", vars: scoreVars}, nil
+	}
+
+	boosts := domain.SearchBoosts(req).Normalized()
+	rankWeights := fmt.Sprintf("{0,%g,%g,%g}", boosts.Provider, boosts.Tags, boosts.Title)
+	vars := append([]interface{}{weights.Text, rankWeights}, tsVars...)
+	return sortKey{
+		field: domain.SortByRelevance,
+		expr:  fmt.Sprintf("(?::float8 * ts_rank_cd(?::float4[], search_vector, %s, 32)::float8 + %s)", tsQuery, scoreExpr),
+		vars:  append(vars, scoreVars...),
+	}, nil
+}
+
+// titleTSQuery ORs the title terms into one tsquery expression analyzed in the language of
+// each row, or returns "" without terms
+func (r *ContentRepository) titleTSQuery(terms []*domain.TermNode) (string, []interface{}) {
+	queries := make([]string, 0, len(terms))
+	args := make([]interface{}, 0, len(terms))
+	for _, term := range terms {
+		if term.Phrase {
+			queries = append(queries, "phraseto_tsquery(content_search_config(language), ?)")
+		} else {
+			queries = append(queries, "plainto_tsquery(content_search_config(language), ?)")
+		}
+		args = append(args, term.Value)
+	}
+	if len(queries) == 0 {
+		return "", nil
+	}
+	return "(" + strings.Join(queries, " || ") + ")", args
+}
+
+// searchOrder joins sort keys into an ORDER BY expression
+func (r *ContentRepository) searchOrder(keys []sortKey) clause.Expr {
+	clauses := make([]string, 0, len(keys))
+	var vars []interface{}
+	for _, key := range keys {
+		direction := "ASC"
+		if key.desc {
+			direction = "DESC"
+		}
+		clauses = append(clauses, fmt.Sprintf("%s %s", key.expr, direction))
+		vars = append(vars, key.vars...)
+	}
+	return clause.Expr{SQL: strings.Join(clauses, ", "), Vars: vars}
+}
+
+// afterCondition selects the rows that sort after the cursor: for some key, every earlier key
+// equals the cursor value and that key lies past it in the key's direction
+func (r *ContentRepository) afterCondition(keys []sortKey, after *domain.SearchCursor) (clause.Expr, error) {
+	values := make([]interface{}, 0, len(keys))
+	field := 0
+	for _, key := range keys {
+		switch key.field {
+		case exactRankField:
+			values = append(values, after.Exact)
+		case "id":
+			values = append(values, after.ID)
+		default:
+			value, err := after.Value(field, key.field)
+			if err != nil {
+				return clause.Expr{}, err
+			}
+			values = append(values, value)
+			field++
+		}
+	}
+
+	alternatives := make([]string, 0, len(keys))
+	var vars []interface{}
+	for i, key := range keys {
+		terms := make([]string, 0, i+1)
+		for j := 0; j < i; j++ {
+			terms = append(terms, keys[j].expr+" = ?")
+			vars = append(append(vars, keys[j].vars...), values[j])
+		}
+		operator := ">"
+		if key.desc {
+			operator = "<"
+		}
+		terms = append(terms, key.expr+" "+operator+" ?")
+		vars = append(append(vars, key.vars...), values[i])
+		alternatives = append(alternatives, "("+strings.Join(terms, " AND ")+")")
+	}
+	return clause.Expr{SQL: "(" + strings.Join(alternatives, " OR ") + ")", Vars: vars}, nil
+}
+
+// collapsedHit is the top row of one collapse group together with the size of that group
+type collapsedHit struct {
+	ID         int64
+	GroupTotal int
+}
+
+// searchCollapsed keeps only the best hit per provider or type, ranked with the same order as
+// the flat search. ROW_NUMBER and COUNT window functions are supported by both PostgreSQL
+// and SQLite (3.25+), so the grouping runs in the database on either backend.
+func (r *ContentRepository) searchCollapsed(ctx context.Context, query *gorm.DB, req *domain.SearchRequest, keys []sortKey) ([]*domain.Content, int, error) {
+	db := r.db.WithContext(ctx)
+	groups := r.collapsedGroups(ctx, query, req, keys)
+
+	var total int64
+	if err := groups.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (req.Page - 1) * req.PageSize
+	if req.After != nil {
+		after, err := r.afterCondition(keys, req.After)
+		if err != nil {
+			return nil, 0, err
+		}
+		groups = groups.Where(after)
+		offset = 0
+	}
+
+	var hits []collapsedHit
+	if err := groups.Select("id, group_total").
+		Order(clause.OrderBy{Expression: r.searchOrder(keys)}).
+		Offset(offset).
+		Limit(req.PageSize).
+		Scan(&hits).Error; err != nil {
+		return nil, 0, err
+	}
+	if len(hits) == 0 {
+		return []*domain.Content{}, int(total), nil
+	}
+
+	ids := make([]int64, 0, len(hits))
+	for _, hit := range hits {
+		ids = append(ids, hit.ID)
+	}
+
+	var found []*domain.Content
+	if err := db.Preload("Tags").Where("id IN ?", ids).Find(&found).Error; err != nil {
+		return nil, 0, err
+	}
+	byID := make(map[int64]*domain.Content, len(found))
+	for _, content := range found {
+		byID[content.ID] = content
+	}
+
+	contents := make([]*domain.Content, 0, len(hits))
+	for _, hit := range hits {
+		if content, ok := byID[hit.ID]; ok {
+			content.InnerHits = &domain.InnerHits{Total: hit.GroupTotal}
+			contents = append(contents, content)
+		}
+	}
+
+	if err := r.attachSources(db, contents); err != nil {
+		return nil, 0, err
+	}
+
+	if err := r.attachHighlights(ctx, contents, req); err != nil {
+		return nil, 0, err
+	}
+
+	if err := r.attachRelevance(ctx, contents, keys); err != nil {
+		return nil, 0, err
+	}
+
+	return contents, int(total), nil
+}
+
+// collapsedGroups returns the top-ranked row of every collapse group
+func (r *ContentRepository) collapsedGroups(ctx context.Context, query *gorm.DB, req *domain.SearchRequest, keys []sortKey) *gorm.DB {
+	groupBy := r.collapseColumn(req.Collapse)
+	orderBy := r.searchOrder(keys)
+
+	ranked := query.Select(fmt.Sprintf(
+		"contents.*, ROW_NUMBER() OVER (PARTITION BY %s ORDER BY %s) AS group_rank, COUNT(*) OVER (PARTITION BY %s) AS group_total",
+		groupBy, orderBy.SQL, groupBy,
+	), orderBy.Vars...)
+	return r.db.WithContext(ctx).Table("(?) AS ranked", ranked).Where("group_rank = 1")
+}
+
+func (r *ContentRepository) collapseColumn(field string) string {
+	if field == domain.CollapseType {
+		return "type"
+	}
+	return "provider"
+}
+
+// relevanceHit is one match of a sort_by=relevance search ranked in-process
+type relevanceHit struct {
+	ID         int64
+	Title      string
+	Score      float64
+	GroupKey   string
+	ExactRank  int
+	Relevance  float64 `gorm:"-"`
+	GroupTotal int     `gorm:"-"`
+}
+
+// ranksInProcess reports whether a search is ranked by relevance outside the database. SQLite
+// has no text ranking function, so BM25 is computed in-process there, and a search backend
+// ranks titles itself.
+func (r *ContentRepository) ranksInProcess(req *domain.SearchRequest) bool {
+	return (!r.isPostgreSQL() || r.backend != nil) && len(req.Sort) == 0 && req.SortBy == domain.SortByRelevance
+}
+
+// rankByRelevance scores every match with BM25 over the canonical titles blended with the
+// stored score, and orders the matches like sortKeys would. Collapsed searches keep the
+// best hit of each group.
+func (r *ContentRepository) rankByRelevance(ctx context.Context, req *domain.SearchRequest) ([]*relevanceHit, error) {
+	query, err := r.searchQuery(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	exact, err := r.exactRankKey(req)
+	if err != nil {
+		return nil, err
+	}
+	exactExpr, exactVars := "0", []interface{}(nil)
+	if exact != nil {
+		exactExpr, exactVars = exact.expr, exact.vars
+	}
+
+	var hits []*relevanceHit
+	if err := query.Select(
+		fmt.Sprintf("id, title, score, %s AS group_key, %s AS exact_rank", r.collapseColumn(req.Collapse), exactExpr),
+		exactVars...,
+	).Scan(&hits).Error; err != nil {
+		return nil, err
+	}
+
+	node, err := domain.SearchQuery(req)
+	if err != nil {
+		return nil, err
+	}
+	ranks, err := r.textRanks(ctx, domain.SearchBoosts(req), []domain.QueryNode{node, req.Filter}, hits)
+	if err != nil {
+		return nil, err
+	}
+	for _, hit := range hits {
+		hit.Relevance = r.relevance.Blend(r.relevance.NormalizeRank(ranks[hit.ID]), hit.Score)
+	}
+
+	desc := req.SortOrder != "asc"
+	sort.Slice(hits, func(i, j int) bool {
+		return r.relevanceHitBefore(hits[i], hits[j].ExactRank, hits[j].Relevance, hits[j].ID, desc)
+	})
+
+	if req.Collapse == "" {
+		return hits, nil
+	}
+	groups := make(map[string]*relevanceHit)
+	collapsed := hits[:0]
+	for _, hit := range hits {
+		if best, ok := groups[hit.GroupKey]; ok {
+			best.GroupTotal++
+			continue
+		}
+		hit.GroupTotal = 1
+		groups[hit.GroupKey] = hit
+		collapsed = append(collapsed, hit)
+	}
+	return collapsed, nil
+}
+
+// textRanks returns the rank of each hit for the title terms of the query nodes: the BM25 ranks
+// of its title, tag names and provider weighted by the field boosts, like ts_rank_cd weights the
+// fields of the search vector. Titles are ranked by the search backend when there is one.
+func (r *ContentRepository) textRanks(ctx context.Context, boosts domain.FieldBoosts, nodes []domain.QueryNode, hits []*relevanceHit) (map[int64]float64, error) {
+	if err := r.corpus.load(ctx, r.db); err != nil {
+		return nil, err
+	}
+	tokens := domain.RelevanceTerms(nodes...)
+
+	var ranks map[int64]float64
+	if r.backend != nil {
+		ids := make([]int64, 0, len(hits))
+		for _, hit := range hits {
+			ids = append(ids, hit.ID)
+		}
+		ranks = r.backend.RankTitles(domain.HighlightTerms(nodes...), ids)
+	} else {
+		ranks = r.corpus.titleRanks(tokens, hits)
+	}
+
+	boosts = boosts.Normalized()
+	for id := range ranks {
+		ranks[id] *= boosts.Title
+	}
+	if boosts.Tags == 0 && boosts.Provider == 0 {
+		return ranks, nil
+	}
+	r.corpus.fieldRanks(tokens, boosts, hits, ranks)
+	return ranks, nil
+}
+
+// relevanceHitBefore reports whether hit sorts before the position given by an exact rank,
+// relevance and ID
+func (r *ContentRepository) relevanceHitBefore(hit *relevanceHit, exactRank int, relevance float64, id int64, desc bool) bool {
+	if hit.ExactRank != exactRank {
+		return hit.ExactRank < exactRank
+	}
+	if hit.Relevance != relevance {
+		if desc {
+			return hit.Relevance > relevance
+		}
+		return hit.Relevance < relevance
+	}
+	return hit.ID < id
+}
+
+// relevanceHitsAfter drops the hits up to and including the cursor position
+func (r *ContentRepository) relevanceHitsAfter(hits []*relevanceHit, req *domain.SearchRequest, after *domain.SearchCursor) ([]*relevanceHit, error) {
+	relevance, err := after.Value(0, domain.SortByRelevance)
+	if err != nil {
+		return nil, err
+	}
+	desc := req.SortOrder != "asc"
+	for i, hit := range hits {
+		if !r.relevanceHitBefore(hit, after.Exact, relevance.(float64), after.ID, desc) && hit.ID != after.ID {
+			return hits[i:], nil
+		}
+	}
+	return nil, nil
+}
+
+// searchByRelevance pages through the in-process relevance ranking
+func (r *ContentRepository) searchByRelevance(ctx context.Context, req *domain.SearchRequest) ([]*domain.Content, int, error) {
+	hits, err := r.rankByRelevance(ctx, req)
+	if err != nil {
+		return nil, 0, err
+	}
+	total := len(hits)
+
+	page := hits
+	if req.After != nil {
+		if page, err = r.relevanceHitsAfter(hits, req, req.After); err != nil {
+			return nil, 0, err
+		}
+	} else if offset := (req.Page - 1) * req.PageSize; offset < len(page) {
+		page = page[offset:]
+	} else {
+		page = nil
+	}
+	if len(page) > req.PageSize {
+		page = page[:req.PageSize]
+	}
+	if len(page) == 0 {
+		return []*domain.Content{}, total, nil
+	}
+
+	ids := make([]int64, 0, len(page))
+	for _, hit := range page {
+		ids = append(ids, hit.ID)
+	}
+	db := r.db.WithContext(ctx)
+	var found []*domain.Content
+	if err := db.Preload("Tags").Where("id IN ?", ids).Find(&found).Error; err != nil {
+		return nil, 0, err
+	}
+	byID := make(map[int64]*domain.Content, len(found))
+	for _, content := range found {
+		byID[content.ID] = content
+	}
+
+	contents := make([]*domain.Content, 0, len(page))
+	for _, hit := range page {
+		content, ok := byID[hit.ID]
+		if !ok {
+			continue
+		}
+		relevance := hit.Relevance
+		content.Relevance = &relevance
+		if req.Collapse != "" {
+			content.InnerHits = &domain.InnerHits{Total: hit.GroupTotal}
+		}
+		contents = append(contents, content)
+	}
+
+	if err := r.attachSources(db, contents); err != nil {
+		return nil, 0, err
+	}
+
+	if err := r.attachHighlights(ctx, contents, req); err != nil {
+		return nil, 0, err
+	}
+
+	return contents, total, nil
 }
 
-func (r *ContentRepository) isRecordNotFound(err error) bool {
-	return errors.Is(err, gorm.ErrRecordNotFound)
+// nextRelevanceCursor is NextCursor for searches ranked in-process
+func (r *ContentRepository) nextRelevanceCursor(ctx context.Context, req *domain.SearchRequest, last *domain.Content) (string, error) {
+	hits, err := r.rankByRelevance(ctx, req)
+	if err != nil {
+		return "", err
+	}
+	exact := 0
+	for _, hit := range hits {
+		if hit.ID == last.ID {
+			exact = hit.ExactRank
+			break
+		}
+	}
+
+	cursorSpec := domain.NewCursorSpecification()
+	cursor, err := cursorSpec.CursorAfter(req, last, exact)
+	if err != nil {
+		return "", err
+	}
+	following, err := r.relevanceHitsAfter(hits, req, cursor)
+	if err != nil || len(following) == 0 {
+		return "", err
+	}
+	return cursor.Encode()
 }
 
-func (r *ContentRepository) hasError(err error) bool {
-	return err != nil
+// attachRelevance sets the blended relevance of each item when sorting by relevance
+func (r *ContentRepository) attachRelevance(ctx context.Context, contents []*domain.Content, keys []sortKey) error {
+	if len(contents) == 0 {
+		return nil
+	}
+	for _, key := range keys {
+		if key.field != domain.SortByRelevance {
+			continue
+		}
+
+		ids := make([]int64, 0, len(contents))
+		for _, content := range contents {
+			ids = append(ids, content.ID)
+		}
+		var rows []struct {
+			ID        int64
+			Relevance float64
+		}
+		if err := r.db.WithContext(ctx).Raw(
+			fmt.Sprintf("SELECT id, %s AS relevance FROM contents WHERE id IN ?", key.expr),
+			append(append([]interface{}{}, key.vars...), ids)...,
+		).Scan(&rows).Error; err != nil {
+			return err
+		}
+
+		byID := make(map[int64]float64, len(rows))
+		for _, row := range rows {
+			byID[row.ID] = row.Relevance
+		}
+		for _, content := range contents {
+			if relevance, ok := byID[content.ID]; ok {
+				content.Relevance = &relevance
+			}
+		}
+	}
+	return nil
 }
 
-func (r *ContentRepository) Search(ctx context.Context, req *domain.SearchRequest) ([]*domain.Content, int, error) {
-	offset := (req.Page - 1) * req.PageSize
-	query := r.db.WithContext(ctx).Model(&domain.Content{})
+// facetRow is one aggregation bucket as returned by a GROUP BY query
+type facetRow struct {
+	BucketKey   string
+	BucketCount int
+}
 
-	if req.Query != "" {
-		if r.isPostgreSQL() {
-			query = query.Where("to_tsvector('english', title) @@ plainto_tsquery('english', ?)", req.Query)
-		} else {
-			query = query.Where("title LIKE ?", "%"+req.Query+"%")
+// Aggregate computes the requested facets over every item matching the search filters, ignoring pagination and collapse
+func (r *ContentRepository) Aggregate(ctx context.Context, req *domain.SearchRequest) (domain.Aggregations, error) {
+	var aggregations domain.Aggregations
+	err := r.withTrigramThreshold(ctx, req, func(repo *ContentRepository) error {
+		var err error
+		aggregations, err = repo.aggregate(ctx, req)
+		return err
+	})
+	return aggregations, err
+}
+
+func (r *ContentRepository) aggregate(ctx context.Context, req *domain.SearchRequest) (domain.Aggregations, error) {
+	aggregations := make(domain.Aggregations, len(req.Facets))
+	for _, facet := range req.Facets {
+		var (
+			buckets []domain.FacetBucket
+			err     error
+		)
+		switch facet {
+		case domain.FacetType:
+			buckets, err = r.termsFacet(ctx, req, "type")
+		case domain.FacetProvider:
+			buckets, err = r.termsFacet(ctx, req, "provider")
+		case domain.FacetTag:
+			buckets, err = r.tagFacet(ctx, req)
+		case domain.FacetPublished:
+			buckets, err = r.publishedFacet(ctx, req)
+		case domain.FacetScore:
+			buckets, err = r.scoreFacet(ctx, req)
+		default:
+			continue
+		}
+		if domainErr, ok := err.(*domain.DomainError); ok {
+			return nil, domainErr
+		}
+		if err != nil {
+			return nil, domain.NewDatabaseError("aggregate_"+facet, err)
 		}
+		aggregations[facet] = buckets
 	}
+	return aggregations, nil
+}
 
-	if req.ContentType != nil {
-		query = query.Where("type = ?", *req.ContentType)
+func (r *ContentRepository) termsFacet(ctx context.Context, req *domain.SearchRequest, column string) ([]domain.FacetBucket, error) {
+	query, err := r.searchQuery(ctx, req)
+	if err != nil {
+		return nil, err
 	}
 
-	var total int64
-	if err := query.Count(&total).Error; err != nil {
-		return nil, 0, err
+	var rows []facetRow
+	if err := query.
+		Select(fmt.Sprintf("%s AS bucket_key, COUNT(*) AS bucket_count", column)).
+		Group(column).
+		Order("bucket_count DESC, bucket_key ASC").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	return r.toFacetBuckets(rows), nil
+}
+
+func (r *ContentRepository) tagFacet(ctx context.Context, req *domain.SearchRequest) ([]domain.FacetBucket, error) {
+	query, err := r.searchQuery(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []facetRow
+	if err := r.db.WithContext(ctx).Table("content_tags").
+		Select("tags.name AS bucket_key, COUNT(*) AS bucket_count").
+		Joins("JOIN tags ON tags.id = content_tags.tag_id").
+		Where("content_tags.content_id IN (?)", query.Select("id")).
+		Group("tags.name").
+		Order("bucket_count DESC, bucket_key ASC").
+		Limit(domain.MaxTagFacetBuckets).
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	return r.toFacetBuckets(rows), nil
+}
+
+// publishedFacet is a monthly histogram of created_at, keyed "YYYY-MM" and ordered oldest first
+func (r *ContentRepository) publishedFacet(ctx context.Context, req *domain.SearchRequest) ([]domain.FacetBucket, error) {
+	month := "strftime('%Y-%m', created_at)"
+	if r.isPostgreSQL() {
+		month = "to_char(date_trunc('month', created_at), 'YYYY-MM')"
+	}
+
+	query, err := r.searchQuery(ctx, req)
+	if err != nil {
+		return nil, err
 	}
 
-	sortOrder := "DESC"
-	if req.SortOrder == "asc" {
-		sortOrder = "ASC"
+	var rows []facetRow
+	if err := query.
+		Select(fmt.Sprintf("%s AS bucket_key, COUNT(*) AS bucket_count", month)).
+		Group(month).
+		Order("bucket_key ASC").
+		Scan(&rows).Error; err != nil {
+		return nil, err
 	}
+	return r.toFacetBuckets(rows), nil
+}
 
-	switch req.SortBy {
-	case "created_at":
-		query = query.Order(fmt.Sprintf("created_at %s", sortOrder))
-	case "popularity":
-		if sortOrder == "ASC" {
-			query = query.Order("views ASC, likes ASC")
+// scoreFacet counts items in each of the fixed score ranges, reporting empty ranges too
+func (r *ContentRepository) scoreFacet(ctx context.Context, req *domain.SearchRequest) ([]domain.FacetBucket, error) {
+	var bucketExpr strings.Builder
+	bucketExpr.WriteString("CASE")
+	for _, scoreRange := range domain.ScoreFacetRanges {
+		if scoreRange.To == nil {
+			fmt.Fprintf(&bucketExpr, " WHEN score >= %g THEN '%s'", scoreRange.From, scoreRange.Key)
 		} else {
-			query = query.Order("views DESC, likes DESC")
+			fmt.Fprintf(&bucketExpr, " WHEN score >= %g AND score < %g THEN '%s'", scoreRange.From, *scoreRange.To, scoreRange.Key)
 		}
-	default:
-		query = query.Order(fmt.Sprintf("score %s", sortOrder))
 	}
+	bucketExpr.WriteString(" END")
 
-	var contents []*domain.Content
-	if err := query.Offset(offset).Limit(req.PageSize).Find(&contents).Error; err != nil {
-		return nil, 0, err
+	query, err := r.searchQuery(ctx, req)
+	if err != nil {
+		return nil, err
 	}
 
-	return contents, int(total), nil
+	var rows []facetRow
+	if err := query.
+		Select(fmt.Sprintf("%s AS bucket_key, COUNT(*) AS bucket_count", bucketExpr.String())).
+		Group(bucketExpr.String()).
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int, len(rows))
+	for _, row := range rows {
+		counts[row.BucketKey] = row.BucketCount
+	}
+
+	buckets := make([]domain.FacetBucket, 0, len(domain.ScoreFacetRanges))
+	for _, scoreRange := range domain.ScoreFacetRanges {
+		from := scoreRange.From
+		buckets = append(buckets, domain.FacetBucket{
+			Key:   scoreRange.Key,
+			Count: counts[scoreRange.Key],
+			From:  &from,
+			To:    scoreRange.To,
+		})
+	}
+	return buckets, nil
+}
+
+func (r *ContentRepository) toFacetBuckets(rows []facetRow) []domain.FacetBucket {
+	buckets := make([]domain.FacetBucket, 0, len(rows))
+	for _, row := range rows {
+		buckets = append(buckets, domain.FacetBucket{Key: row.BucketKey, Count: row.BucketCount})
+	}
+	return buckets
 }
 
 func (r *ContentRepository) GetByID(ctx context.Context, id int64) (*domain.Content, error) {
 	var content domain.Content
-	if err := r.db.WithContext(ctx).First(&content, id).Error; err != nil {
+	if err := r.db.WithContext(ctx).Preload("Tags").First(&content, id).Error; err != nil {
 		if r.isRecordNotFound(err) {
 			return nil, domain.NewNotFoundError("content", id)
 		}
 		return nil, domain.NewDatabaseError("get_by_id", err)
 	}
+	if err := r.attachSources(r.db.WithContext(ctx), []*domain.Content{&content}); err != nil {
+		return nil, domain.NewDatabaseError("get_by_id", err)
+	}
 	return &content, nil
 }
 
 func (r *ContentRepository) BatchCreateOrUpdate(ctx context.Context, contents []*domain.Content) error {
-	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+	// the canonical titles replaced and added, and the tag names and providers stored, applied
+	// to the vocabulary once committed
+	var oldTitles, newTitles, names []string
+	var canonical []*domain.Content
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		for _, content := range contents {
+			tags, err := r.resolveTags(tx, content.Tags)
+			if err != nil {
+				return err
+			}
+
 			var existing domain.Content
 			result := tx.Where("provider_id = ? AND provider = ?", content.ProviderID, content.Provider).
 				First(&existing)
 
 			if r.isRecordFound(result.Error) {
+				now := time.Now().UTC()
+				since := now.Add(-domain.DefaultTrendingWindow)
+				baselines, err := r.trendingBaselines(tx, []int64{existing.ID}, since)
+				if err != nil {
+					return err
+				}
+				content.TrendingScore = domain.NewTrendingScoreSpecification(baselines[existing.ID], now).Calculate(content)
+
+				content.UpdatedAt = now
 				updateData := map[string]interface{}{
-					"title":        content.Title,
-					"type":         content.Type,
-					"views":        content.Views,
-					"likes":        content.Likes,
-					"reading_time": content.ReadingTime,
-					"reactions":    content.Reactions,
-					"score":        content.Score,
+					"updated_at":       content.UpdatedAt,
+					"title":            content.Title,
+					"type":             content.Type,
+					"language":         content.Language,
+					"views":            content.Views,
+					"likes":            content.Likes,
+					"reading_time":     content.ReadingTime,
+					"reactions":        content.Reactions,
+					"duration":         content.Duration,
+					"comments":         content.Comments,
+					"listens":          content.Listens,
+					"image_count":      content.ImageCount,
+					"score":            content.Score,
+					"trending_score":   content.TrendingScore,
+					"normalized_title": content.NormalizedTitle,
+					"sim_hash":         content.SimHash,
+					"sim_hash_band0":   content.SimHashBand0,
+					"sim_hash_band1":   content.SimHashBand1,
+					"sim_hash_band2":   content.SimHashBand2,
+					"sim_hash_band3":   content.SimHashBand3,
+				}
+				metricsChanged := domain.HasMetricsChanged(&existing, content)
+				if existing.CanonicalID == nil {
+					oldTitles = append(oldTitles, existing.Title)
+					newTitles = append(newTitles, content.Title)
 				}
 				if err := tx.Model(&existing).Updates(updateData).Error; err != nil {
 					return fmt.Errorf("failed to update content: %w", err)
 				}
 				content.ID = existing.ID
+				content.CanonicalID = existing.CanonicalID
+				if metricsChanged {
+					if err := r.recordMetricsSnapshot(tx, content); err != nil {
+						return err
+					}
+				}
 			} else if r.isRecordNotFound(result.Error) {
-				if err := tx.Create(content).Error; err != nil {
+				if err := tx.Omit("Tags").Create(content).Error; err != nil {
 					return fmt.Errorf("failed to create content: %w", err)
 				}
+				oldTitles = append(oldTitles, "")
+				newTitles = append(newTitles, content.Title)
+				if err := r.recordMetricsSnapshot(tx, content); err != nil {
+					return err
+				}
 			} else {
 				return fmt.Errorf("failed to check existing content: %w", result.Error)
 			}
+
+			content.Tags = tags
+			if content.CanonicalID == nil {
+				canonical = append(canonical, content)
+			}
+			names = append(names, content.Provider)
+			names = append(names, domain.TagNames(tags)...)
+			if err := tx.Model(&domain.Content{ID: content.ID}).Association("Tags").Replace(tags); err != nil {
+				return fmt.Errorf("failed to update content tags: %w", err)
+			}
+		}
+		return r.refreshSearchVectors(tx, contents)
+	})
+	if err != nil {
+		return err
+	}
+
+	return r.onCommit(func() error {
+		for i := range oldTitles {
+			r.vocabulary.replace(oldTitles[i], newTitles[i])
+		}
+		r.vocabulary.addNames(names)
+		r.corpus.put(canonical)
+		if r.backend == nil {
+			return nil
+		}
+		if err := r.backend.Index(contents); err != nil {
+			return fmt.Errorf("failed to update search index: %w", err)
+		}
+		return nil
+	})
+}
+
+// refreshSearchVectors recomputes the stored search vectors of contents once their tags are
+// saved. SQLite has no search vector and matches the fields with LIKE instead.
+func (r *ContentRepository) refreshSearchVectors(tx *gorm.DB, contents []*domain.Content) error {
+	if !r.isPostgreSQL() || len(contents) == 0 {
+		return nil
+	}
+	ids := make([]int64, 0, len(contents))
+	for _, content := range contents {
+		ids = append(ids, content.ID)
+	}
+	if err := tx.Exec("UPDATE contents SET search_vector = "+searchVectorSQL+" WHERE id IN ?", ids).Error; err != nil {
+		return fmt.Errorf("failed to update search vectors: %w", err)
+	}
+	return nil
+}
+
+func (r *ContentRepository) recordMetricsSnapshot(tx *gorm.DB, content *domain.Content) error {
+	if err := tx.Create(domain.NewContentMetricsSnapshot(content)).Error; err != nil {
+		return fmt.Errorf("failed to record metrics snapshot: %w", err)
+	}
+	return nil
+}
+
+func (r *ContentRepository) GetMetricsHistory(ctx context.Context, contentID int64, from, to time.Time) ([]*domain.ContentMetricsSnapshot, error) {
+	var snapshots []*domain.ContentMetricsSnapshot
+	if err := r.db.WithContext(ctx).
+		Where("content_id = ? AND recorded_at BETWEEN ? AND ?", contentID, from, to).
+		Order("recorded_at ASC").
+		Find(&snapshots).Error; err != nil {
+		return nil, domain.NewDatabaseError("get_metrics_history", err)
+	}
+	return snapshots, nil
+}
+
+// ListSuggestTitles returns the title, type and score of every canonical content item
+func (r *ContentRepository) ListSuggestTitles(ctx context.Context) ([]*domain.Content, error) {
+	var contents []*domain.Content
+	if err := r.db.WithContext(ctx).
+		Select("id, title, type, score").
+		Where("canonical_id IS NULL").
+		Find(&contents).Error; err != nil {
+		return nil, domain.NewDatabaseError("list_suggest_titles", err)
+	}
+	return contents, nil
+}
+
+// ListSpellingDocuments returns the title, canonical link and tags of every content item, the
+// text spelling corrections are drawn from
+func (r *ContentRepository) ListSpellingDocuments(ctx context.Context) ([]*domain.Content, error) {
+	var contents []*domain.Content
+	var batch []*domain.Content
+	if err := r.db.WithContext(ctx).
+		Select("id, title, canonical_id").
+		Preload("Tags").
+		FindInBatches(&batch, 500, func(tx *gorm.DB, _ int) error {
+			contents = append(contents, batch...)
+			return nil
+		}).Error; err != nil {
+		return nil, domain.NewDatabaseError("list_spelling_documents", err)
+	}
+	return contents, nil
+}
+
+// RecordQueries adds to the search counts of normalized queries
+func (r *ContentRepository) RecordQueries(ctx context.Context, counts map[string]int, searchedAt time.Time) error {
+	if len(counts) == 0 {
+		return nil
+	}
+	entries := make([]*domain.SearchQueryLog, 0, len(counts))
+	for query, count := range counts {
+		entries = append(entries, &domain.SearchQueryLog{Query: query, Count: count, LastSearchedAt: searchedAt})
+	}
+	if err := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "query"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"count":            gorm.Expr("search_queries.count + excluded.count"),
+			"last_searched_at": searchedAt,
+		}),
+	}).CreateInBatches(entries, 500).Error; err != nil {
+		return domain.NewDatabaseError("record_queries", err)
+	}
+	return nil
+}
+
+// PruneQueries deletes every logged query but the keep most searched ones
+func (r *ContentRepository) PruneQueries(ctx context.Context, keep int) error {
+	kept := r.db.Model(&domain.SearchQueryLog{}).
+		Select("query").
+		Order("count DESC, query ASC").
+		Limit(keep)
+	if err := r.db.WithContext(ctx).
+		Where("query NOT IN (?)", kept).
+		Delete(&domain.SearchQueryLog{}).Error; err != nil {
+		return domain.NewDatabaseError("prune_queries", err)
+	}
+	return nil
+}
+
+// ListPopularQueries returns the most searched queries, most frequent first
+func (r *ContentRepository) ListPopularQueries(ctx context.Context, limit int) ([]*domain.SearchQueryLog, error) {
+	var queries []*domain.SearchQueryLog
+	if err := r.db.WithContext(ctx).
+		Order("count DESC, query ASC").
+		Limit(limit).
+		Find(&queries).Error; err != nil {
+		return nil, domain.NewDatabaseError("list_popular_queries", err)
+	}
+	return queries, nil
+}
+
+// FindTrending ranks content whose metrics changed within the window by engagement velocity
+func (r *ContentRepository) FindTrending(ctx context.Context, req *domain.TrendingRequest, now time.Time) ([]*domain.Content, int, error) {
+	since := now.Add(-req.Duration)
+	db := r.db.WithContext(ctx)
+
+	query := db.Model(&domain.Content{}).
+		Where("canonical_id IS NULL").
+		Where("id IN (?)", r.changedSince(db, since))
+
+	if req.ContentType != nil {
+		query = query.Where("type = ?", *req.ContentType)
+	}
+
+	var candidates []*domain.Content
+	if err := query.Preload("Tags").Find(&candidates).Error; err != nil {
+		return nil, 0, domain.NewDatabaseError("find_trending", err)
+	}
+	baselines, err := r.trendingBaselines(db, r.changedSince(db, since), since)
+	if err != nil {
+		return nil, 0, domain.NewDatabaseError("find_trending", err)
+	}
+
+	trending := make([]*domain.Content, 0, len(candidates))
+	for _, content := range candidates {
+		content.TrendingScore = domain.NewTrendingScoreSpecification(baselines[content.ID], now).Calculate(content)
+		if content.TrendingScore > 0 {
+			trending = append(trending, content)
+		}
+	}
+
+	sort.SliceStable(trending, func(i, j int) bool {
+		if trending[i].TrendingScore == trending[j].TrendingScore {
+			return trending[i].ID < trending[j].ID
+		}
+		return trending[i].TrendingScore > trending[j].TrendingScore
+	})
+
+	total := len(trending)
+	start := (req.Page - 1) * req.PageSize
+	if start >= total {
+		return []*domain.Content{}, total, nil
+	}
+	end := start + req.PageSize
+	if end > total {
+		end = total
+	}
+	return trending[start:end], total, nil
+}
+
+// trendingRefreshBatchSize is how many stored trending scores RefreshTrendingScores updates per statement
+const trendingRefreshBatchSize = 500
+
+// RefreshTrendingScores recomputes the stored trending score that sort_by=trending orders by,
+// which ingest only updates for the items it sees again. Items whose metrics did not change
+// within the default window have not grown and score zero.
+func (r *ContentRepository) RefreshTrendingScores(ctx context.Context, now time.Time) error {
+	since := now.Add(-domain.DefaultTrendingWindow)
+	db := r.db.WithContext(ctx)
+
+	if err := db.Model(&domain.Content{}).
+		Where("trending_score <> 0 AND id NOT IN (?)", r.changedSince(db, since)).
+		UpdateColumn("trending_score", 0).Error; err != nil {
+		return domain.NewDatabaseError("refresh_trending_scores", err)
+	}
+
+	var batch []*domain.Content
+	err := db.Model(&domain.Content{}).
+		Select("id", "views", "likes", "reactions", "comments", "listens").
+		Where("id IN (?)", r.changedSince(db, since)).
+		FindInBatches(&batch, trendingRefreshBatchSize, func(tx *gorm.DB, _ int) error {
+			ids := make([]int64, 0, len(batch))
+			for _, content := range batch {
+				ids = append(ids, content.ID)
+			}
+			baselines, err := r.trendingBaselines(db, ids, since)
+			if err != nil {
+				return err
+			}
+
+			var scores strings.Builder
+			vars := make([]interface{}, 0, 2*len(batch))
+			scores.WriteString("CASE id")
+			for _, content := range batch {
+				scores.WriteString(" WHEN ? THEN ?")
+				vars = append(vars, content.ID, domain.NewTrendingScoreSpecification(baselines[content.ID], now).Calculate(content))
+			}
+			scores.WriteString(" ELSE trending_score END")
+			return db.Model(&domain.Content{}).
+				Where("id IN ?", ids).
+				UpdateColumn("trending_score", gorm.Expr(scores.String(), vars...)).Error
+		}).Error
+	if err != nil {
+		return domain.NewDatabaseError("refresh_trending_scores", err)
+	}
+	return nil
+}
+
+// changedSince selects the IDs of content whose metrics changed at or after since
+func (r *ContentRepository) changedSince(db *gorm.DB, since time.Time) *gorm.DB {
+	return db.Model(&domain.ContentMetricsSnapshot{}).
+		Select("content_id").
+		Where("recorded_at >= ?", since)
+}
+
+// maxRelatedCandidates caps how many candidates FindRelated scores: those sharing the most title
+// words and tags with the source, so that a common tag does not load most of the table
+const maxRelatedCandidates = 1000
+
+// FindRelated returns the canonical content most similar to source, ranked like
+// sort_by=relevance with the similarity in place of the text rank. Source and the content
+// it duplicates are left out, and only the maxRelatedCandidates candidates sharing the most
+// with source are ranked.
+func (r *ContentRepository) FindRelated(ctx context.Context, source *domain.Content, req *domain.RelatedRequest) ([]*domain.Content, int, error) {
+	relatedSpec := domain.NewRelatedSpecification()
+	node := relatedSpec.CandidateQuery(source)
+	if node == nil {
+		return []*domain.Content{}, 0, nil
+	}
+
+	query, err := r.searchQuery(ctx, &domain.SearchRequest{Filter: node})
+	if err != nil {
+		return nil, 0, domain.NewDatabaseError("find_related", err)
+	}
+	query = query.Where("id <> ?", source.ID)
+	if source.CanonicalID != nil {
+		query = query.Where("id <> ?", *source.CanonicalID)
+	}
+
+	var candidates []*domain.Content
+	if err := query.Preload("Tags").
+		Order(clause.OrderBy{Expression: r.relatedOrder(node)}).
+		Limit(maxRelatedCandidates).
+		Find(&candidates).Error; err != nil {
+		return nil, 0, domain.NewDatabaseError("find_related", err)
+	}
+
+	if err := r.corpus.load(ctx, r.db); err != nil {
+		return nil, 0, domain.NewDatabaseError("find_related", err)
+	}
+	similarities := r.corpus.similarities(source, candidates)
+
+	related := make([]*domain.Content, 0, len(candidates))
+	for i, candidate := range candidates {
+		similarity := similarities[i]
+		if similarity == 0 {
+			continue
+		}
+		relevance := r.relevance.Blend(r.relevance.NormalizeRank(similarity), candidate.Score)
+		candidate.Relevance = &relevance
+		related = append(related, candidate)
+	}
+
+	sort.SliceStable(related, func(i, j int) bool {
+		if *related[i].Relevance == *related[j].Relevance {
+			return related[i].ID < related[j].ID
+		}
+		return *related[i].Relevance > *related[j].Relevance
+	})
+
+	total := len(related)
+	start := (req.Page - 1) * req.PageSize
+	if start >= total {
+		return []*domain.Content{}, total, nil
+	}
+	end := start + req.PageSize
+	if end > total {
+		end = total
+	}
+	page := related[start:end]
+	if err := r.attachSources(r.db.WithContext(ctx), page); err != nil {
+		return nil, 0, domain.NewDatabaseError("find_related", err)
+	}
+	return page, total, nil
+}
+
+// relatedOrder orders related candidates by how many of the title words and tags of the
+// candidate query they share, then by score
+func (r *ContentRepository) relatedOrder(node domain.QueryNode) clause.Expr {
+	var shared []string
+	var vars []interface{}
+	var tags []string
+	for _, child := range node.(*domain.OrNode).Children {
+		term := child.(*domain.TermNode)
+		if term.Field == domain.QueryFieldTag {
+			tags = append(tags, term.Value)
+			continue
 		}
+		shared = append(shared, "CASE WHEN LOWER(title) LIKE ? THEN 1 ELSE 0 END")
+		vars = append(vars, "%"+term.Value+"%")
+	}
+	if len(tags) > 0 {
+		shared = append(shared, `(SELECT COUNT(*) FROM content_tags JOIN tags ON tags.id = content_tags.tag_id
+			WHERE content_tags.content_id = contents.id AND tags.name IN ?)`)
+		vars = append(vars, tags)
+	}
+	return clause.Expr{SQL: "(" + strings.Join(shared, " + ") + ") DESC, score DESC, id ASC", Vars: vars}
+}
+
+// FindDuplicateCandidates returns older canonical records of the same type from other providers
+// that the given content could be a duplicate of: those with the same normalized title or a
+// simhash band in common, which every simhash near enough to be a duplicate has
+func (r *ContentRepository) FindDuplicateCandidates(ctx context.Context, content *domain.Content) ([]*domain.Content, error) {
+	var candidates []*domain.Content
+	if err := r.db.WithContext(ctx).
+		Where("canonical_id IS NULL AND type = ? AND provider <> ? AND id < ?", content.Type, content.Provider, content.ID).
+		Where("normalized_title = ? OR sim_hash_band0 = ? OR sim_hash_band1 = ? OR sim_hash_band2 = ? OR sim_hash_band3 = ?",
+			content.NormalizedTitle, content.SimHashBand0, content.SimHashBand1, content.SimHashBand2, content.SimHashBand3).
+		Order("id ASC").
+		Find(&candidates).Error; err != nil {
+		return nil, domain.NewDatabaseError("find_duplicate_candidates", err)
+	}
+	return candidates, nil
+}
+
+// LinkDuplicate points a content item, and anything already linked to it, at a canonical record
+func (r *ContentRepository) LinkDuplicate(ctx context.Context, duplicateID, canonicalID int64) error {
+	var linked []*domain.Content
+	if err := r.db.WithContext(ctx).Model(&domain.Content{}).
+		Select("id", "title").
+		Where("id = ? AND canonical_id IS NULL", duplicateID).
+		Find(&linked).Error; err != nil {
+		return domain.NewDatabaseError("link_duplicate", err)
+	}
+	if err := r.db.WithContext(ctx).Model(&domain.Content{}).
+		Where("id = ? OR canonical_id = ?", duplicateID, duplicateID).
+		Update("canonical_id", canonicalID).Error; err != nil {
+		return domain.NewDatabaseError("link_duplicate", err)
+	}
+	return r.onCommit(func() error {
+		for _, content := range linked {
+			r.vocabulary.replace(content.Title, "")
+			r.corpus.remove([]int64{content.ID})
+		}
+		return nil
+	})
+}
+
+// UnlinkDuplicate makes a content item that no longer matches its canonical record canonical
+// again. The content is expected with its tags, which relevance is ranked on.
+func (r *ContentRepository) UnlinkDuplicate(ctx context.Context, content *domain.Content) error {
+	if err := r.db.WithContext(ctx).Model(&domain.Content{}).
+		Where("id = ?", content.ID).
+		Update("canonical_id", nil).Error; err != nil {
+		return domain.NewDatabaseError("unlink_duplicate", err)
+	}
+	content.CanonicalID = nil
+	title := content.Title
+	return r.onCommit(func() error {
+		r.vocabulary.replace("", title)
+		r.corpus.put([]*domain.Content{content})
 		return nil
 	})
 }
 
+// GetCluster returns a canonical record followed by every duplicate linked to it
+func (r *ContentRepository) GetCluster(ctx context.Context, canonicalID int64) ([]*domain.Content, error) {
+	var cluster []*domain.Content
+	if err := r.db.WithContext(ctx).
+		Where("id = ? OR canonical_id = ?", canonicalID, canonicalID).
+		Order("canonical_id IS NOT NULL, id ASC").
+		Find(&cluster).Error; err != nil {
+		return nil, domain.NewDatabaseError("get_cluster", err)
+	}
+	return cluster, nil
+}
+
+func (r *ContentRepository) UpdateScore(ctx context.Context, id int64, score float64) error {
+	if err := r.db.WithContext(ctx).Model(&domain.Content{ID: id}).
+		Update("score", score).Error; err != nil {
+		return domain.NewDatabaseError("update_score", err)
+	}
+	return nil
+}
+
+// attachSources fills in the provider copies of every content item that has duplicates
+func (r *ContentRepository) attachSources(db *gorm.DB, contents []*domain.Content) error {
+	if len(contents) == 0 {
+		return nil
+	}
+
+	clusterIDs := make([]int64, 0, len(contents))
+	for _, content := range contents {
+		clusterIDs = append(clusterIDs, content.ClusterID())
+	}
+
+	var members []*domain.Content
+	if err := db.Where("id IN ? OR canonical_id IN ?", clusterIDs, clusterIDs).
+		Order("canonical_id IS NOT NULL, id ASC").
+		Find(&members).Error; err != nil {
+		return fmt.Errorf("failed to load content sources: %w", err)
+	}
+
+	sources := make(map[int64][]domain.ContentSource, len(clusterIDs))
+	for _, member := range members {
+		clusterID := member.ClusterID()
+		sources[clusterID] = append(sources[clusterID], domain.NewContentSource(member))
+	}
+
+	for _, content := range contents {
+		if clusterSources := sources[content.ClusterID()]; len(clusterSources) > 1 {
+			content.Sources = clusterSources
+		}
+	}
+	return nil
+}
+
+// highlightRow is a title marked up by ts_headline
+type highlightRow struct {
+	ID    int64
+	Title string
+}
+
+// attachHighlights marks the title terms of the query in each title, using ts_headline on
+// PostgreSQL so that stemmed matches are highlighted too, and a tokenizer on SQLite
+func (r *ContentRepository) attachHighlights(ctx context.Context, contents []*domain.Content, req *domain.SearchRequest) error {
+	if len(contents) == 0 {
+		return nil
+	}
+	node, err := domain.SearchQuery(req)
+	if err != nil {
+		return err
+	}
+	terms := domain.HighlightTerms(node, req.Filter)
+	if len(terms) == 0 {
+		return nil
+	}
+
+	highlightSpec := domain.NewHighlightSpecification()
+	preTag, postTag := highlightSpec.Tags(req)
+
+	if !r.isPostgreSQL() {
+		for _, content := range contents {
+			content.Highlights = highlightSpec.Highlight(content.Title, terms, req.Fuzzy, preTag, postTag)
+		}
+		return nil
+	}
+
+	tsQuery, args := r.titleTSQuery(terms)
+	options := fmt.Sprintf(`StartSel="%s", StopSel="%s", HighlightAll=true`, preTag, postTag)
+	ids := make([]int64, 0, len(contents))
+	for _, content := range contents {
+		ids = append(ids, content.ID)
+	}
+	args = append(args, options, ids)
+
+	var rows []highlightRow
+	if err := r.db.WithContext(ctx).Raw(
+		fmt.Sprintf("SELECT id, ts_headline(content_search_config(language), title, %s, ?) AS title FROM contents WHERE id IN ?", tsQuery),
+		args...,
+	).Scan(&rows).Error; err != nil {
+		return err
+	}
+
+	byID := make(map[int64]string, len(rows))
+	for _, row := range rows {
+		byID[row.ID] = row.Title
+	}
+	for _, content := range contents {
+		highlighted, ok := byID[content.ID]
+		if !ok {
+			continue
+		}
+		if matched := highlightSpec.MatchedTerms(highlighted, preTag, postTag); len(matched) > 0 {
+			content.Highlights = &domain.Highlights{Title: highlighted, MatchedTerms: matched}
+		}
+	}
+	return nil
+}
+
+// trendingBaselines returns the metrics at since of the given content, keyed by content ID and
+// interpolated between the snapshots around since (see domain.TrendingBaseline). contentIDs is
+// an ID slice or a subquery. The snapshots on either side of since are each read with one
+// query, ranking the snapshots of every item with ROW_NUMBER, which PostgreSQL and SQLite
+// (3.25+) both support.
+func (r *ContentRepository) trendingBaselines(db *gorm.DB, contentIDs interface{}, since time.Time) (map[int64]*domain.ContentMetricsSnapshot, error) {
+	nearest := func(condition, direction string) (map[int64]*domain.ContentMetricsSnapshot, error) {
+		ranked := db.Model(&domain.ContentMetricsSnapshot{}).
+			Select(fmt.Sprintf(
+				"content_metrics_history.*, ROW_NUMBER() OVER (PARTITION BY content_id ORDER BY recorded_at %s, id %s) AS snapshot_rank",
+				direction, direction,
+			)).
+			Where("content_id IN (?)", contentIDs).
+			Where(condition, since)
+
+		var snapshots []*domain.ContentMetricsSnapshot
+		if err := db.Table("(?) AS ranked", ranked).
+			Select("id, content_id, views, likes, reactions, comments, listens, score, recorded_at").
+			Where("snapshot_rank = 1").
+			Scan(&snapshots).Error; err != nil {
+			return nil, fmt.Errorf("failed to load trending baselines: %w", err)
+		}
+		byContent := make(map[int64]*domain.ContentMetricsSnapshot, len(snapshots))
+		for _, snapshot := range snapshots {
+			byContent[snapshot.ContentID] = snapshot
+		}
+		return byContent, nil
+	}
+
+	before, err := nearest("recorded_at <= ?", "DESC")
+	if err != nil {
+		return nil, err
+	}
+	after, err := nearest("recorded_at > ?", "ASC")
+	if err != nil {
+		return nil, err
+	}
+
+	baselines := make(map[int64]*domain.ContentMetricsSnapshot, len(after))
+	for contentID, snapshot := range before {
+		baselines[contentID] = domain.TrendingBaseline(snapshot, after[contentID], since)
+	}
+	for contentID, snapshot := range after {
+		if _, ok := before[contentID]; !ok {
+			baselines[contentID] = domain.TrendingBaseline(nil, snapshot, since)
+		}
+	}
+	return baselines, nil
+}
+
+// resolveTags looks up each tag by name, creating the ones that do not exist yet
+func (r *ContentRepository) resolveTags(tx *gorm.DB, tags []domain.Tag) ([]domain.Tag, error) {
+	resolved := make([]domain.Tag, 0, len(tags))
+	for _, tag := range tags {
+		var existing domain.Tag
+		if err := tx.Where(domain.Tag{Name: tag.Name}).FirstOrCreate(&existing).Error; err != nil {
+			return nil, fmt.Errorf("failed to resolve tag %q: %w", tag.Name, err)
+		}
+		resolved = append(resolved, existing)
+	}
+	return resolved, nil
+}
+
+// applyTagFilter restricts results to content carrying any (or, in "all" mode, every) of the given tags
+func (r *ContentRepository) applyTagFilter(query *gorm.DB, tags []string, mode string) *gorm.DB {
+	sub := r.db.Table("content_tags").
+		Select("content_tags.content_id").
+		Joins("JOIN tags ON tags.id = content_tags.tag_id").
+		Where("tags.name IN ?", tags)
+
+	if mode == domain.TagModeAll {
+		sub = sub.Group("content_tags.content_id").
+			Having("COUNT(DISTINCT tags.id) = ?", len(tags))
+	}
+
+	return query.Where("id IN (?)", sub)
+}
+
 func (r *ContentRepository) isRecordFound(err error) bool {
 	return err == nil
 }
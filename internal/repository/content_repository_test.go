@@ -2,10 +2,15 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
 	"testing"
 	"time"
 
 	"search-engine-go/internal/domain"
+	"search-engine-go/internal/infrastructure/searchindex"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -17,7 +22,7 @@ func setupTestDB(t *testing.T) *gorm.DB {
 	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
 	require.NoError(t, err)
 
-	err = db.AutoMigrate(&domain.Content{})
+	err = db.AutoMigrate(&domain.Content{}, &domain.Tag{}, &domain.ContentMetricsSnapshot{}, &domain.SearchQueryLog{}, &domain.SynonymSet{})
 	require.NoError(t, err)
 
 	return db
@@ -343,3 +348,1673 @@ func TestContentRepository_BatchCreateOrUpdate(t *testing.T) {
 		assert.NoError(t, err)
 	})
 }
+
+func TestContentRepository_Tags(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewContentRepository(db)
+	ctx := context.Background()
+
+	contents := []*domain.Content{
+		{
+			ProviderID: "provider2_v1",
+			Provider:   "provider2",
+			Title:      "Introduction to Docker",
+			Type:       domain.ContentTypeVideo,
+			Score:      12.0,
+			Tags:       domain.NewTags("devops", "containers"),
+		},
+		{
+			ProviderID: "provider2_v2",
+			Provider:   "provider2",
+			Title:      "Kubernetes for Beginners",
+			Type:       domain.ContentTypeVideo,
+			Score:      10.0,
+			Tags:       domain.NewTags("DevOps", "kubernetes"),
+		},
+		{
+			ProviderID: "provider1_v1",
+			Provider:   "provider1",
+			Title:      "Go Programming Tutorial",
+			Type:       domain.ContentTypeVideo,
+			Score:      8.0,
+			Tags:       domain.NewTags("programming"),
+		},
+	}
+	require.NoError(t, repo.BatchCreateOrUpdate(ctx, contents))
+
+	t.Run("Tags are persisted and shared", func(t *testing.T) {
+		var count int64
+		db.Model(&domain.Tag{}).Count(&count)
+		assert.Equal(t, int64(4), count)
+
+		result, err := repo.GetByID(ctx, contents[0].ID)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"devops", "containers"}, domain.TagNames(result.Tags))
+	})
+
+	t.Run("Filter with any tag", func(t *testing.T) {
+		req := &domain.SearchRequest{
+			Tags:     []string{"containers", "kubernetes"},
+			TagMode:  domain.TagModeAny,
+			Page:     1,
+			PageSize: 10,
+		}
+
+		results, total, err := repo.Search(ctx, req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 2, total)
+		assert.Equal(t, "Introduction to Docker", results[0].Title)
+		assert.NotEmpty(t, results[0].Tags)
+	})
+
+	t.Run("Filter with all tags", func(t *testing.T) {
+		req := &domain.SearchRequest{
+			Tags:     []string{"devops", "containers"},
+			TagMode:  domain.TagModeAll,
+			Page:     1,
+			PageSize: 10,
+		}
+
+		results, total, err := repo.Search(ctx, req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, total)
+		assert.Equal(t, "Introduction to Docker", results[0].Title)
+	})
+
+	t.Run("Re-ingest replaces tags", func(t *testing.T) {
+		updated := []*domain.Content{
+			{
+				ProviderID: "provider1_v1",
+				Provider:   "provider1",
+				Title:      "Go Programming Tutorial",
+				Type:       domain.ContentTypeVideo,
+				Score:      8.0,
+				Tags:       domain.NewTags("golang"),
+			},
+		}
+		require.NoError(t, repo.BatchCreateOrUpdate(ctx, updated))
+
+		result, err := repo.GetByID(ctx, contents[2].ID)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"golang"}, domain.TagNames(result.Tags))
+	})
+}
+
+func TestContentRepository_SearchDuration(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewContentRepository(db)
+	ctx := context.Background()
+
+	contents := []*domain.Content{
+		{ProviderID: "p_short", Provider: "p", Title: "Short Video", Type: domain.ContentTypeVideo, Duration: 300, Score: 5},
+		{ProviderID: "p_medium", Provider: "p", Title: "Medium Video", Type: domain.ContentTypeVideo, Duration: 930, Score: 7},
+		{ProviderID: "p_long", Provider: "p", Title: "Long Video", Type: domain.ContentTypeVideo, Duration: 3765, Score: 6},
+	}
+	for _, content := range contents {
+		require.NoError(t, db.Create(content).Error)
+	}
+	intPtr := func(v int) *int { return &v }
+
+	t.Run("Filter by max duration", func(t *testing.T) {
+		req := &domain.SearchRequest{MaxDuration: intPtr(600), Page: 1, PageSize: 10}
+
+		results, total, err := repo.Search(ctx, req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, total)
+		assert.Equal(t, "Short Video", results[0].Title)
+	})
+
+	t.Run("Filter by duration range", func(t *testing.T) {
+		req := &domain.SearchRequest{MinDuration: intPtr(600), MaxDuration: intPtr(3600), Page: 1, PageSize: 10}
+
+		results, total, err := repo.Search(ctx, req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, total)
+		assert.Equal(t, "Medium Video", results[0].Title)
+	})
+
+	t.Run("Sort by duration ascending", func(t *testing.T) {
+		req := &domain.SearchRequest{SortBy: "duration", SortOrder: "asc", Page: 1, PageSize: 10}
+
+		results, _, err := repo.Search(ctx, req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"Short Video", "Medium Video", "Long Video"},
+			[]string{results[0].Title, results[1].Title, results[2].Title})
+	})
+
+	t.Run("Duration bounds skip unknown durations", func(t *testing.T) {
+		require.NoError(t, db.Create(&domain.Content{ProviderID: "p_text", Provider: "p", Title: "Article", Type: domain.ContentTypeText, Score: 4}).Error)
+		require.NoError(t, db.Create(&domain.Content{ProviderID: "p_broken", Provider: "p", Title: "Broken Video", Type: domain.ContentTypeVideo, Score: 4}).Error)
+
+		for _, req := range []*domain.SearchRequest{
+			{MaxDuration: intPtr(600), Page: 1, PageSize: 10},
+			{MinDuration: intPtr(0), MaxDuration: intPtr(600), Page: 1, PageSize: 10},
+		} {
+			results, total, err := repo.Search(ctx, req)
+
+			assert.NoError(t, err)
+			assert.Equal(t, 1, total)
+			assert.Equal(t, "Short Video", results[0].Title)
+		}
+	})
+}
+
+func TestContentRepository_SearchRanges(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewContentRepository(db)
+	ctx := context.Background()
+
+	contents := []*domain.Content{
+		{ProviderID: "p_old", Provider: "p", Title: "Old Video", Type: domain.ContentTypeVideo, Views: 5000, Likes: 300, Score: 8, CreatedAt: time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)},
+		{ProviderID: "p_january", Provider: "p", Title: "January Video", Type: domain.ContentTypeVideo, Views: 200, Likes: 10, Score: 3.5, CreatedAt: time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)},
+		{ProviderID: "p_february", Provider: "p", Title: "February Video", Type: domain.ContentTypeVideo, Views: 1200, Likes: 80, Score: 6, CreatedAt: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, content := range contents {
+		require.NoError(t, db.Create(content).Error)
+	}
+	intPtr := func(v int) *int { return &v }
+	floatPtr := func(v float64) *float64 { return &v }
+	timePtr := func(t time.Time) *time.Time { return &t }
+
+	search := func(t *testing.T, req *domain.SearchRequest) []string {
+		req.Page, req.PageSize, req.SortBy, req.SortOrder = 1, 10, "created_at", "asc"
+		results, total, err := repo.Search(ctx, req)
+		require.NoError(t, err)
+		assert.Equal(t, len(results), total)
+		titles := make([]string, 0, len(results))
+		for _, result := range results {
+			titles = append(titles, result.Title)
+		}
+		return titles
+	}
+
+	t.Run("Published after is inclusive", func(t *testing.T) {
+		titles := search(t, &domain.SearchRequest{PublishedAfterTime: timePtr(time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC))})
+
+		assert.Equal(t, []string{"January Video", "February Video"}, titles)
+	})
+
+	t.Run("Published before is exclusive", func(t *testing.T) {
+		titles := search(t, &domain.SearchRequest{
+			PublishedAfterTime:  timePtr(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)),
+			PublishedBeforeTime: timePtr(time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)),
+		})
+
+		assert.Equal(t, []string{"January Video"}, titles)
+	})
+
+	t.Run("Engagement bounds", func(t *testing.T) {
+		assert.Equal(t, []string{"Old Video", "February Video"}, search(t, &domain.SearchRequest{MinViews: intPtr(1000)}))
+		assert.Equal(t, []string{"January Video", "February Video"}, search(t, &domain.SearchRequest{MaxViews: intPtr(1200)}))
+		assert.Equal(t, []string{"February Video"}, search(t, &domain.SearchRequest{MinLikes: intPtr(50), MaxLikes: intPtr(100)}))
+		assert.Equal(t, []string{"January Video", "February Video"}, search(t, &domain.SearchRequest{MinScore: floatPtr(3.5), MaxScore: floatPtr(7)}))
+	})
+}
+
+func TestContentRepository_MetricsHistory(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewContentRepository(db)
+	ctx := context.Background()
+
+	ingest := func(views, likes int) *domain.Content {
+		content := &domain.Content{
+			ProviderID: "provider1_v1",
+			Provider:   "provider1",
+			Title:      "Go Programming Tutorial",
+			Type:       domain.ContentTypeVideo,
+			Views:      views,
+			Likes:      likes,
+		}
+		require.NoError(t, repo.BatchCreateOrUpdate(ctx, []*domain.Content{content}))
+		return content
+	}
+
+	content := ingest(100, 10)
+	ingest(100, 10)
+	ingest(250, 18)
+
+	t.Run("Snapshots are written on create and on metric changes only", func(t *testing.T) {
+		history, err := repo.GetMetricsHistory(ctx, content.ID, time.Now().Add(-time.Hour), time.Now().Add(time.Hour))
+
+		require.NoError(t, err)
+		require.Len(t, history, 2)
+		assert.Equal(t, 100, history[0].Views)
+		assert.Equal(t, 250, history[1].Views)
+		assert.Equal(t, 18, history[1].Likes)
+	})
+
+	t.Run("Window excludes snapshots outside the range", func(t *testing.T) {
+		history, err := repo.GetMetricsHistory(ctx, content.ID, time.Now().Add(-48*time.Hour), time.Now().Add(-24*time.Hour))
+
+		require.NoError(t, err)
+		assert.Empty(t, history)
+	})
+}
+
+func TestContentRepository_FindTrending(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewContentRepository(db)
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	oldHit := &domain.Content{ProviderID: "p_old", Provider: "p", Title: "Old Hit", Type: domain.ContentTypeVideo, Views: 500000}
+	riser := &domain.Content{ProviderID: "p_riser", Provider: "p", Title: "Rising Star", Type: domain.ContentTypeVideo, Views: 130000}
+	article := &domain.Content{ProviderID: "p_article", Provider: "p", Title: "Rising Article", Type: domain.ContentTypeText, Reactions: 500}
+	for _, content := range []*domain.Content{oldHit, riser, article} {
+		require.NoError(t, db.Create(content).Error)
+	}
+
+	snapshots := []*domain.ContentMetricsSnapshot{
+		{ContentID: oldHit.ID, Views: 499000, RecordedAt: now.Add(-48 * time.Hour)},
+		{ContentID: oldHit.ID, Views: 500000, RecordedAt: now.Add(-2 * time.Hour)},
+		{ContentID: riser.ID, Views: 1000, RecordedAt: now.Add(-30 * time.Hour)},
+		{ContentID: riser.ID, Views: 130000, RecordedAt: now.Add(-1 * time.Hour)},
+		{ContentID: article.ID, Reactions: 100, RecordedAt: now.Add(-4 * time.Hour)},
+	}
+	for _, snapshot := range snapshots {
+		require.NoError(t, db.Create(snapshot).Error)
+	}
+
+	t.Run("Ranks by velocity rather than absolute views", func(t *testing.T) {
+		req := &domain.TrendingRequest{Duration: 24 * time.Hour, Page: 1, PageSize: 10}
+
+		results, total, err := repo.FindTrending(ctx, req, now)
+
+		require.NoError(t, err)
+		assert.Equal(t, 3, total)
+		assert.Equal(t, "Rising Star", results[0].Title)
+		assert.Greater(t, results[0].TrendingScore, results[2].TrendingScore)
+	})
+
+	t.Run("Velocity covers only the window", func(t *testing.T) {
+		req := &domain.TrendingRequest{Duration: 24 * time.Hour, Page: 1, PageSize: 10}
+
+		results, _, err := repo.FindTrending(ctx, req, now)
+
+		require.NoError(t, err)
+		// 27690 views interpolated 24h ago, between the snapshots 30h and 1h ago
+		assert.InDelta(t, (130000-27690)/1000.0/24.0, results[0].TrendingScore, 1e-9)
+	})
+
+	t.Run("Filters by type", func(t *testing.T) {
+		contentType := domain.ContentTypeText
+		req := &domain.TrendingRequest{ContentType: &contentType, Duration: 24 * time.Hour, Page: 1, PageSize: 10}
+
+		results, total, err := repo.FindTrending(ctx, req, now)
+
+		require.NoError(t, err)
+		assert.Equal(t, 1, total)
+		assert.Equal(t, "Rising Article", results[0].Title)
+	})
+
+	t.Run("Refresh recomputes stored trending scores", func(t *testing.T) {
+		require.NoError(t, db.Model(&domain.Content{}).Where("id = ?", oldHit.ID).UpdateColumn("trending_score", 99).Error)
+
+		require.NoError(t, repo.RefreshTrendingScores(ctx, now))
+
+		var stored []*domain.Content
+		require.NoError(t, db.Order("id").Find(&stored).Error)
+		require.Len(t, stored, 3)
+		assert.Less(t, stored[0].TrendingScore, 1.0)
+		assert.InDelta(t, (130000-27690)/1000.0/24.0, stored[1].TrendingScore, 1e-3)
+		assert.Greater(t, stored[2].TrendingScore, 0.0)
+
+		require.NoError(t, repo.RefreshTrendingScores(ctx, now.Add(48*time.Hour)))
+
+		require.NoError(t, db.Order("id").Find(&stored).Error)
+		for _, content := range stored {
+			assert.Equal(t, 0.0, content.TrendingScore, content.Title)
+		}
+	})
+
+	t.Run("Ingest refreshes stored trending score", func(t *testing.T) {
+		updated := &domain.Content{ProviderID: "p_riser", Provider: "p", Title: "Rising Star", Type: domain.ContentTypeVideo, Views: 200000}
+		require.NoError(t, repo.BatchCreateOrUpdate(ctx, []*domain.Content{updated}))
+
+		req := &domain.SearchRequest{SortBy: "trending", Page: 1, PageSize: 10}
+		results, _, err := repo.Search(ctx, req)
+
+		require.NoError(t, err)
+		assert.Equal(t, "Rising Star", results[0].Title)
+		assert.Greater(t, results[0].TrendingScore, 0.0)
+	})
+}
+
+func TestContentRepository_Duplicates(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewContentRepository(db)
+	ctx := context.Background()
+
+	canonical := &domain.Content{ProviderID: "p1_1", Provider: "provider1", Title: "Go Concurrency", Type: domain.ContentTypeVideo, Views: 1000, Score: 5}
+	duplicate := &domain.Content{ProviderID: "p2_1", Provider: "provider2", Title: "Go Concurrency!", Type: domain.ContentTypeVideo, Views: 400, Score: 3}
+	other := &domain.Content{ProviderID: "p2_2", Provider: "provider2", Title: "Go Generics", Type: domain.ContentTypeVideo, Views: 50, Score: 1}
+	for _, content := range []*domain.Content{canonical, duplicate, other} {
+		domain.ApplyFingerprint(content)
+	}
+	require.NoError(t, repo.BatchCreateOrUpdate(ctx, []*domain.Content{canonical, duplicate, other}))
+
+	t.Run("Finds older canonical candidates from other providers", func(t *testing.T) {
+		candidates, err := repo.FindDuplicateCandidates(ctx, duplicate)
+
+		require.NoError(t, err)
+		require.Len(t, candidates, 1)
+		assert.Equal(t, canonical.ID, candidates[0].ID)
+	})
+
+	t.Run("Candidates share a simhash band or the normalized title", func(t *testing.T) {
+		unrelated := &domain.Content{ID: other.ID + 1, Provider: "provider3", Type: domain.ContentTypeVideo, NormalizedTitle: "go concurrency patterns",
+			SimHashBand0: canonical.SimHashBand0 ^ 1, SimHashBand1: canonical.SimHashBand1 ^ 1, SimHashBand2: canonical.SimHashBand2 ^ 1, SimHashBand3: canonical.SimHashBand3 ^ 1}
+		candidates, err := repo.FindDuplicateCandidates(ctx, unrelated)
+		require.NoError(t, err)
+		assert.Empty(t, candidates)
+
+		unrelated.SimHashBand2 = canonical.SimHashBand2
+		candidates, err = repo.FindDuplicateCandidates(ctx, unrelated)
+		require.NoError(t, err)
+		require.Len(t, candidates, 2)
+		assert.Equal(t, []int64{canonical.ID, duplicate.ID}, []int64{candidates[0].ID, candidates[1].ID})
+	})
+
+	require.NoError(t, repo.LinkDuplicate(ctx, duplicate.ID, canonical.ID))
+
+	t.Run("Search returns only canonical records with sources", func(t *testing.T) {
+		req := &domain.SearchRequest{Query: "Concurrency", Page: 1, PageSize: 10}
+
+		results, total, err := repo.Search(ctx, req)
+
+		require.NoError(t, err)
+		assert.Equal(t, 1, total)
+		require.Len(t, results, 1)
+		assert.Equal(t, canonical.ID, results[0].ID)
+		require.Len(t, results[0].Sources, 2)
+		assert.Equal(t, "provider1", results[0].Sources[0].Provider)
+		assert.Equal(t, "provider2", results[0].Sources[1].Provider)
+		assert.Equal(t, 400, results[0].Sources[1].Views)
+	})
+
+	t.Run("Content without duplicates has no sources", func(t *testing.T) {
+		result, err := repo.GetByID(ctx, other.ID)
+
+		require.NoError(t, err)
+		assert.Empty(t, result.Sources)
+	})
+
+	t.Run("Duplicate lookup by ID points at canonical", func(t *testing.T) {
+		result, err := repo.GetByID(ctx, duplicate.ID)
+
+		require.NoError(t, err)
+		require.NotNil(t, result.CanonicalID)
+		assert.Equal(t, canonical.ID, *result.CanonicalID)
+		assert.Len(t, result.Sources, 2)
+	})
+
+	t.Run("Re-ingest keeps the canonical link", func(t *testing.T) {
+		updated := &domain.Content{ProviderID: "p2_1", Provider: "provider2", Title: "Go Concurrency!", Type: domain.ContentTypeVideo, Views: 800}
+		require.NoError(t, repo.BatchCreateOrUpdate(ctx, []*domain.Content{updated}))
+
+		require.NotNil(t, updated.CanonicalID)
+		assert.Equal(t, canonical.ID, *updated.CanonicalID)
+	})
+
+	t.Run("Cluster lists canonical first", func(t *testing.T) {
+		cluster, err := repo.GetCluster(ctx, canonical.ID)
+
+		require.NoError(t, err)
+		require.Len(t, cluster, 2)
+		assert.Equal(t, canonical.ID, cluster[0].ID)
+		assert.Equal(t, duplicate.ID, cluster[1].ID)
+	})
+
+	t.Run("Linking a canonical re-points its duplicates", func(t *testing.T) {
+		require.NoError(t, repo.LinkDuplicate(ctx, canonical.ID, other.ID))
+
+		cluster, err := repo.GetCluster(ctx, other.ID)
+
+		require.NoError(t, err)
+		assert.Len(t, cluster, 3)
+	})
+}
+
+func TestContentRepository_SearchCollapse(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewContentRepository(db)
+	ctx := context.Background()
+
+	contents := []*domain.Content{
+		{ProviderID: "p1_1", Provider: "provider1", Title: "Video A", Type: domain.ContentTypeVideo, Score: 9},
+		{ProviderID: "p1_2", Provider: "provider1", Title: "Video B", Type: domain.ContentTypeVideo, Score: 4},
+		{ProviderID: "p1_3", Provider: "provider1", Title: "Article A", Type: domain.ContentTypeText, Score: 6},
+		{ProviderID: "p2_1", Provider: "provider2", Title: "Article B", Type: domain.ContentTypeText, Score: 8, Tags: domain.NewTags("go")},
+		{ProviderID: "p2_2", Provider: "provider2", Title: "Video C", Type: domain.ContentTypeVideo, Score: 2},
+	}
+	require.NoError(t, repo.BatchCreateOrUpdate(ctx, contents))
+
+	t.Run("Collapses by provider keeping the top hit per group", func(t *testing.T) {
+		req := &domain.SearchRequest{Collapse: domain.CollapseProvider, Page: 1, PageSize: 10, SortBy: "score"}
+
+		results, total, err := repo.Search(ctx, req)
+
+		require.NoError(t, err)
+		assert.Equal(t, 2, total)
+		require.Len(t, results, 2)
+		assert.Equal(t, "Video A", results[0].Title)
+		assert.Equal(t, 3, results[0].InnerHits.Total)
+		assert.Equal(t, "Article B", results[1].Title)
+		assert.Equal(t, 2, results[1].InnerHits.Total)
+		assert.Equal(t, []string{"go"}, domain.TagNames(results[1].Tags))
+	})
+
+	t.Run("Collapses by type honouring filters and sort order", func(t *testing.T) {
+		req := &domain.SearchRequest{Query: "A", Collapse: domain.CollapseType, Page: 1, PageSize: 10, SortBy: "score", SortOrder: "asc"}
+
+		results, total, err := repo.Search(ctx, req)
+
+		require.NoError(t, err)
+		assert.Equal(t, 2, total)
+		require.Len(t, results, 2)
+		assert.Equal(t, "Article A", results[0].Title)
+		assert.Equal(t, 2, results[0].InnerHits.Total)
+		assert.Equal(t, "Video A", results[1].Title)
+		assert.Equal(t, 1, results[1].InnerHits.Total)
+	})
+
+	t.Run("Paginates over groups", func(t *testing.T) {
+		req := &domain.SearchRequest{Collapse: domain.CollapseProvider, Page: 2, PageSize: 1, SortBy: "score"}
+
+		results, total, err := repo.Search(ctx, req)
+
+		require.NoError(t, err)
+		assert.Equal(t, 2, total)
+		require.Len(t, results, 1)
+		assert.Equal(t, "Article B", results[0].Title)
+	})
+
+	t.Run("Flat search has no inner hits", func(t *testing.T) {
+		req := &domain.SearchRequest{Page: 1, PageSize: 10}
+
+		results, total, err := repo.Search(ctx, req)
+
+		require.NoError(t, err)
+		assert.Equal(t, 5, total)
+		assert.Nil(t, results[0].InnerHits)
+	})
+}
+
+func TestContentRepository_Aggregate(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewContentRepository(db)
+	ctx := context.Background()
+
+	march := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	april := time.Date(2024, 4, 2, 12, 0, 0, 0, time.UTC)
+	contents := []*domain.Content{
+		{ProviderID: "p1_1", Provider: "provider1", Title: "Go Video", Type: domain.ContentTypeVideo, Score: 3, CreatedAt: march, Tags: domain.NewTags("go", "backend")},
+		{ProviderID: "p1_2", Provider: "provider1", Title: "Go Article", Type: domain.ContentTypeText, Score: 12, CreatedAt: march, Tags: domain.NewTags("go")},
+		{ProviderID: "p2_1", Provider: "provider2", Title: "Go Podcast", Type: domain.ContentTypeVideo, Score: 75, CreatedAt: april, Tags: domain.NewTags("go", "audio")},
+		{ProviderID: "p2_2", Provider: "provider2", Title: "Rust Video", Type: domain.ContentTypeVideo, Score: 8, CreatedAt: april},
+	}
+	require.NoError(t, repo.BatchCreateOrUpdate(ctx, contents))
+
+	req := &domain.SearchRequest{
+		Query:  "Go",
+		Facets: []string{domain.FacetType, domain.FacetProvider, domain.FacetTag, domain.FacetPublished, domain.FacetScore},
+	}
+
+	aggregations, err := repo.Aggregate(ctx, req)
+	require.NoError(t, err)
+
+	t.Run("Type counts", func(t *testing.T) {
+		assert.Equal(t, []domain.FacetBucket{{Key: "video", Count: 2}, {Key: "text", Count: 1}}, aggregations[domain.FacetType])
+	})
+
+	t.Run("Provider counts", func(t *testing.T) {
+		assert.Equal(t, []domain.FacetBucket{{Key: "provider1", Count: 2}, {Key: "provider2", Count: 1}}, aggregations[domain.FacetProvider])
+	})
+
+	t.Run("Tag counts ordered by frequency", func(t *testing.T) {
+		buckets := aggregations[domain.FacetTag]
+		require.Len(t, buckets, 3)
+		assert.Equal(t, domain.FacetBucket{Key: "go", Count: 3}, buckets[0])
+		assert.Equal(t, "audio", buckets[1].Key)
+		assert.Equal(t, "backend", buckets[2].Key)
+	})
+
+	t.Run("Monthly published histogram", func(t *testing.T) {
+		assert.Equal(t, []domain.FacetBucket{{Key: "2024-03", Count: 2}, {Key: "2024-04", Count: 1}}, aggregations[domain.FacetPublished])
+	})
+
+	t.Run("Score ranges include empty buckets", func(t *testing.T) {
+		buckets := aggregations[domain.FacetScore]
+		require.Len(t, buckets, len(domain.ScoreFacetRanges))
+		counts := make(map[string]int)
+		for _, bucket := range buckets {
+			counts[bucket.Key] = bucket.Count
+		}
+		assert.Equal(t, map[string]int{"0-5": 1, "5-10": 0, "10-20": 1, "20-50": 0, "50+": 1}, counts)
+		assert.Nil(t, buckets[len(buckets)-1].To)
+	})
+
+	t.Run("Only requested facets are computed", func(t *testing.T) {
+		aggregations, err := repo.Aggregate(ctx, &domain.SearchRequest{Facets: []string{domain.FacetType}})
+
+		require.NoError(t, err)
+		assert.Len(t, aggregations, 1)
+		assert.Equal(t, []domain.FacetBucket{{Key: "video", Count: 3}, {Key: "text", Count: 1}}, aggregations[domain.FacetType])
+	})
+}
+
+func TestContentRepository_SearchQuerySyntax(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewContentRepository(db)
+	ctx := context.Background()
+
+	contents := []*domain.Content{
+		{ProviderID: "p1_1", Provider: "provider1", Title: "Go Concurrency Patterns", Type: domain.ContentTypeVideo, Score: 9},
+		{ProviderID: "p2_1", Provider: "provider2", Title: "Go Concurrency for Beginners", Type: domain.ContentTypeVideo, Score: 8},
+		{ProviderID: "p2_2", Provider: "provider2", Title: "Advanced Go Concurrency", Type: domain.ContentTypeVideo, Score: 7, Tags: domain.NewTags("advanced")},
+		{ProviderID: "p2_3", Provider: "provider2", Title: "Go Concurrency Explained", Type: domain.ContentTypeText, Score: 6},
+		{ProviderID: "p1_2", Provider: "provider1", Title: "Rust Ownership", Type: domain.ContentTypeText, Score: 5},
+	}
+	require.NoError(t, repo.BatchCreateOrUpdate(ctx, contents))
+
+	search := func(t *testing.T, query string) []string {
+		results, _, err := repo.Search(ctx, &domain.SearchRequest{Query: query, Page: 1, PageSize: 10})
+		require.NoError(t, err)
+		titles := make([]string, 0, len(results))
+		for _, result := range results {
+			titles = append(titles, result.Title)
+		}
+		return titles
+	}
+
+	t.Run("Field qualifiers and negation", func(t *testing.T) {
+		titles := search(t, `title:"go concurrency" -beginner type:video provider:provider2`)
+
+		assert.Equal(t, []string{"Advanced Go Concurrency"}, titles)
+	})
+
+	t.Run("OR with grouping", func(t *testing.T) {
+		titles := search(t, "(rust OR patterns) type:text")
+
+		assert.Equal(t, []string{"Rust Ownership"}, titles)
+	})
+
+	t.Run("Tag qualifier", func(t *testing.T) {
+		titles := search(t, "tag:advanced")
+
+		assert.Equal(t, []string{"Advanced Go Concurrency"}, titles)
+	})
+
+	t.Run("Plain words match in any order", func(t *testing.T) {
+		titles := search(t, "concurrency go")
+
+		assert.Len(t, titles, 4)
+	})
+
+	t.Run("Syntax errors are invalid input", func(t *testing.T) {
+		_, _, err := repo.Search(ctx, &domain.SearchRequest{Query: "(go", Page: 1, PageSize: 10})
+
+		require.Error(t, err)
+		domainErr, ok := err.(*domain.DomainError)
+		require.True(t, ok)
+		assert.Equal(t, domain.ErrorCodeInvalidInput, domainErr.Code)
+	})
+}
+
+func TestContentRepository_SearchFilter(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewContentRepository(db)
+	ctx := context.Background()
+
+	contents := []*domain.Content{
+		{ProviderID: "p1_1", Provider: "provider1", Title: "Go Basics", Type: domain.ContentTypeVideo, Views: 500, Likes: 50, Score: 9, Tags: domain.NewTags("go")},
+		{ProviderID: "p1_2", Provider: "provider1", Title: "Go Channels", Type: domain.ContentTypeVideo, Views: 2000, Likes: 10, Score: 8, Tags: domain.NewTags("go", "concurrency")},
+		{ProviderID: "p2_1", Provider: "provider2", Title: "Go Generics", Type: domain.ContentTypeText, Views: 800, Likes: 80, Score: 7, Tags: domain.NewTags("go")},
+		{ProviderID: "p2_2", Provider: "provider2", Title: "Rust Traits", Type: domain.ContentTypeText, Views: 1200, Likes: 5, Score: 6, Tags: domain.NewTags("rust")},
+	}
+	require.NoError(t, repo.BatchCreateOrUpdate(ctx, contents))
+
+	search := func(t *testing.T, body string) []string {
+		var doc domain.SearchDocument
+		require.NoError(t, json.Unmarshal([]byte(body), &doc))
+		req, err := domain.NewSearchDocumentSpecification().ToSearchRequest(&doc)
+		require.NoError(t, err)
+		req.Page, req.PageSize = 1, 10
+
+		results, _, err := repo.Search(ctx, req)
+		require.NoError(t, err)
+		titles := make([]string, 0, len(results))
+		for _, result := range results {
+			titles = append(titles, result.Title)
+		}
+		return titles
+	}
+
+	t.Run("Must, range and must_not", func(t *testing.T) {
+		titles := search(t, `{"query": {"bool": {
+			"must": [{"match": {"title": "go"}}],
+			"filter": [{"range": {"views": {"gte": 600}}}],
+			"must_not": [{"term": {"type": "text"}}]
+		}}}`)
+
+		assert.Equal(t, []string{"Go Channels"}, titles)
+	})
+
+	t.Run("Terms with custom sort", func(t *testing.T) {
+		titles := search(t, `{"query": {"terms": {"tags": ["rust", "concurrency"]}}, "sort": [{"views": "asc"}]}`)
+
+		assert.Equal(t, []string{"Rust Traits", "Go Channels"}, titles)
+	})
+
+	t.Run("Minimum should match", func(t *testing.T) {
+		titles := search(t, `{"query": {"bool": {"minimum_should_match": 2, "should": [
+			{"term": {"provider": "provider2"}},
+			{"range": {"likes": {"gt": 20}}},
+			{"term": {"tags": "go"}}
+		]}}, "sort": [{"likes": "desc"}]}`)
+
+		assert.Equal(t, []string{"Go Generics", "Go Basics"}, titles)
+	})
+}
+
+func TestContentRepository_SearchFuzzy(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewContentRepository(db)
+	ctx := context.Background()
+
+	contents := []*domain.Content{
+		{ProviderID: "p1_1", Provider: "provider1", Title: "Kubernetes in Production", Type: domain.ContentTypeVideo, Score: 9},
+		{ProviderID: "p1_2", Provider: "provider1", Title: "Kubernets Typo Hunting", Type: domain.ContentTypeText, Score: 2},
+		{ProviderID: "p2_1", Provider: "provider2", Title: "Kubernetes Operators", Type: domain.ContentTypeVideo, Score: 5},
+		{ProviderID: "p2_2", Provider: "provider2", Title: "Rust Ownership", Type: domain.ContentTypeText, Score: 7},
+	}
+	require.NoError(t, repo.BatchCreateOrUpdate(ctx, contents))
+
+	search := func(t *testing.T, req *domain.SearchRequest) []string {
+		req.Page, req.PageSize = 1, 10
+		results, _, err := repo.Search(ctx, req)
+		require.NoError(t, err)
+		titles := make([]string, 0, len(results))
+		for _, result := range results {
+			titles = append(titles, result.Title)
+		}
+		return titles
+	}
+
+	t.Run("Exact matching ignores misspellings", func(t *testing.T) {
+		titles := search(t, &domain.SearchRequest{Query: "kubernets"})
+
+		assert.Equal(t, []string{"Kubernets Typo Hunting"}, titles)
+	})
+
+	t.Run("Fuzzy matches rank after exact hits", func(t *testing.T) {
+		titles := search(t, &domain.SearchRequest{Query: "kubernets", Fuzzy: domain.FuzzyAuto, SortBy: "score"})
+
+		assert.Equal(t, []string{"Kubernets Typo Hunting", "Kubernetes in Production", "Kubernetes Operators"}, titles)
+	})
+
+	t.Run("Explicit distance limits matches", func(t *testing.T) {
+		titles := search(t, &domain.SearchRequest{Query: "rsut", Fuzzy: "1"})
+		assert.Empty(t, titles)
+
+		titles = search(t, &domain.SearchRequest{Query: "rsut", Fuzzy: "2"})
+		assert.Equal(t, []string{"Rust Ownership"}, titles)
+	})
+
+	t.Run("Combines with other qualifiers", func(t *testing.T) {
+		titles := search(t, &domain.SearchRequest{Query: "kubernets type:video", Fuzzy: domain.FuzzyAuto, SortOrder: "asc"})
+
+		assert.Equal(t, []string{"Kubernetes Operators", "Kubernetes in Production"}, titles)
+	})
+
+	t.Run("Collapsed search ranks exact hits first", func(t *testing.T) {
+		titles := search(t, &domain.SearchRequest{Query: "kubernets", Fuzzy: domain.FuzzyAuto, Collapse: domain.CollapseProvider})
+
+		assert.Equal(t, []string{"Kubernets Typo Hunting", "Kubernetes Operators"}, titles)
+	})
+}
+
+func TestContentRepository_SearchFuzzyVocabulary(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewContentRepository(db)
+	ctx := context.Background()
+
+	require.NoError(t, repo.BatchCreateOrUpdate(ctx, []*domain.Content{
+		{ProviderID: "p1_1", Provider: "provider1", Title: "Rust Ownership", Type: domain.ContentTypeText, Score: 7},
+		{ProviderID: "p1_2", Provider: "provider1", Title: "Go Concurrency", Type: domain.ContentTypeText, Score: 5},
+	}))
+
+	search := func(t *testing.T, query string) []string {
+		results, _, err := repo.Search(ctx, &domain.SearchRequest{Query: query, Fuzzy: "2", Page: 1, PageSize: 10})
+		require.NoError(t, err)
+		titles := make([]string, 0, len(results))
+		for _, result := range results {
+			titles = append(titles, result.Title)
+		}
+		return titles
+	}
+
+	assert.Equal(t, []string{"Rust Ownership"}, search(t, "rsut"))
+
+	t.Run("Follows changed titles", func(t *testing.T) {
+		require.NoError(t, repo.BatchCreateOrUpdate(ctx, []*domain.Content{
+			{ProviderID: "p1_1", Provider: "provider1", Title: "Zig Ownership", Type: domain.ContentTypeText, Score: 7},
+			{ProviderID: "p1_3", Provider: "provider1", Title: "Rust Lifetimes", Type: domain.ContentTypeText, Score: 6},
+		}))
+
+		assert.Equal(t, []string{"Rust Lifetimes"}, search(t, "rsut"))
+	})
+
+	t.Run("Drops linked duplicates", func(t *testing.T) {
+		var duplicate domain.Content
+		require.NoError(t, db.Where("provider_id = ?", "p1_3").First(&duplicate).Error)
+		var canonical domain.Content
+		require.NoError(t, db.Where("provider_id = ?", "p1_2").First(&canonical).Error)
+		require.NoError(t, repo.LinkDuplicate(ctx, duplicate.ID, canonical.ID))
+
+		assert.Empty(t, repo.vocabulary.expand("rsut", 2))
+	})
+
+	t.Run("Matches tags and providers like exact terms", func(t *testing.T) {
+		require.NoError(t, repo.BatchCreateOrUpdate(ctx, []*domain.Content{
+			{ProviderID: "p2_1", Provider: "gopherconf", Title: "Keynote", Type: domain.ContentTypeVideo, Tags: domain.NewTags("kubernetes")},
+		}))
+
+		assert.Equal(t, []string{"Keynote"}, search(t, "kubernets"))
+		assert.Equal(t, []string{"Keynote"}, search(t, "gophercnf"))
+	})
+}
+
+func TestFuzzyThreshold(t *testing.T) {
+	node, err := domain.ParseQuery(`kubernets OR (rsut -"go lang") type:video`)
+	require.NoError(t, err)
+	fuzzySpec := domain.NewFuzzySpecification()
+
+	threshold, ok := fuzzyThreshold(domain.FuzzyAuto, node)
+
+	require.True(t, ok)
+	assert.Equal(t, fuzzySpec.SimilarityThreshold("rsut", 1), threshold)
+
+	_, ok = fuzzyThreshold("", node)
+	assert.False(t, ok)
+	_, ok = fuzzyThreshold(domain.FuzzyAuto, &domain.TermNode{Field: domain.QueryFieldTitle, Value: "go lang", Phrase: true})
+	assert.False(t, ok)
+}
+
+func TestContentRepository_Transaction(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewContentRepository(db)
+	ctx := context.Background()
+	require.NoError(t, repo.vocabulary.load(ctx, db))
+
+	store := func(title string, fail bool) error {
+		return repo.Transaction(ctx, func(repo *ContentRepository) error {
+			if err := repo.BatchCreateOrUpdate(ctx, []*domain.Content{
+				{ProviderID: title, Provider: "provider1", Title: title, Type: domain.ContentTypeText},
+			}); err != nil {
+				return err
+			}
+			assert.NotContains(t, repo.vocabulary.words, strings.ToLower(title), "applied before commit")
+			if fail {
+				return errors.New("failed")
+			}
+			return nil
+		})
+	}
+
+	require.Error(t, store("Rollback", true))
+	require.NoError(t, store("Commit", false))
+
+	var titles []string
+	require.NoError(t, db.Model(&domain.Content{}).Pluck("title", &titles).Error)
+	assert.Equal(t, []string{"Commit"}, titles)
+	assert.Contains(t, repo.vocabulary.words, "commit")
+	assert.NotContains(t, repo.vocabulary.words, "rollback")
+}
+
+func TestSearchVocabulary_ExpandCapsMatches(t *testing.T) {
+	vocabulary := newSearchVocabulary()
+	vocabulary.loaded = true
+	for i := 0; i < 2*maxFuzzyExpansions; i++ {
+		vocabulary.replace("", fmt.Sprintf("ab%c%c", 'a'+i/26, 'a'+i%26))
+	}
+	vocabulary.replace("", "abcd")
+	vocabulary.replace("", "abce abce")
+
+	words := vocabulary.expand("abcd", 2)
+
+	assert.Len(t, words, maxFuzzyExpansions)
+	assert.Equal(t, "abce", words[0], "closest and most frequent words come first")
+	assert.NotContains(t, words, "abcd")
+}
+
+func TestContentRepository_RelevanceCorpus(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewContentRepository(db)
+	ctx := context.Background()
+
+	require.NoError(t, repo.BatchCreateOrUpdate(ctx, []*domain.Content{
+		{ProviderID: "p1_1", Provider: "provider1", Title: "Rust Ownership", Type: domain.ContentTypeText, Tags: domain.NewTags("rust")},
+		{ProviderID: "p1_2", Provider: "provider1", Title: "Go Concurrency", Type: domain.ContentTypeText, Tags: domain.NewTags("go")},
+	}))
+	_, _, err := repo.Search(ctx, &domain.SearchRequest{Query: "rust", SortBy: domain.SortByRelevance, Page: 1, PageSize: 10})
+	require.NoError(t, err)
+	require.True(t, repo.corpus.loaded)
+
+	assertReloaded := func(t *testing.T) {
+		reloaded := newRelevanceCorpus()
+		require.NoError(t, reloaded.load(ctx, db))
+		assert.Equal(t, reloaded.documents, repo.corpus.documents)
+		assert.Equal(t, reloaded.titles, repo.corpus.titles)
+		assert.Equal(t, reloaded.tags, repo.corpus.tags)
+		assert.Equal(t, reloaded.providers, repo.corpus.providers)
+		assert.Equal(t, reloaded.analyzed, repo.corpus.analyzed)
+	}
+
+	t.Run("Follows stored content", func(t *testing.T) {
+		require.NoError(t, repo.BatchCreateOrUpdate(ctx, []*domain.Content{
+			{ProviderID: "p1_1", Provider: "provider1", Title: "Rust Lifetimes", Type: domain.ContentTypeText, Tags: domain.NewTags("rust", "memory")},
+			{ProviderID: "p2_1", Provider: "provider2", Title: "Rust Lifetimes Explained", Type: domain.ContentTypeVideo},
+		}))
+
+		assertReloaded(t)
+	})
+
+	t.Run("Follows linked and unlinked duplicates", func(t *testing.T) {
+		var original, duplicate domain.Content
+		require.NoError(t, db.Where("provider_id = ?", "p1_1").First(&original).Error)
+		require.NoError(t, db.Preload("Tags").Where("provider_id = ?", "p2_1").First(&duplicate).Error)
+
+		require.NoError(t, repo.LinkDuplicate(ctx, duplicate.ID, original.ID))
+		assertReloaded(t)
+
+		require.NoError(t, repo.UnlinkDuplicate(ctx, &duplicate))
+		assertReloaded(t)
+	})
+}
+
+func TestContentRepository_SearchHighlights(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewContentRepository(db)
+	ctx := context.Background()
+
+	contents := []*domain.Content{
+		{ProviderID: "p1_1", Provider: "provider1", Title: "Go Concurrency Patterns", Type: domain.ContentTypeVideo, Score: 9},
+		{ProviderID: "p1_2", Provider: "provider1", Title: "Learning Golang", Type: domain.ContentTypeText, Score: 5},
+		{ProviderID: "p2_1", Provider: "provider2", Title: "Rust Ownership", Type: domain.ContentTypeText, Score: 7},
+	}
+	require.NoError(t, repo.BatchCreateOrUpdate(ctx, contents))
+
+	search := func(t *testing.T, req *domain.SearchRequest) map[string]*domain.Highlights {
+		req.Page, req.PageSize = 1, 10
+		results, _, err := repo.Search(ctx, req)
+		require.NoError(t, err)
+		highlights := make(map[string]*domain.Highlights, len(results))
+		for _, result := range results {
+			highlights[result.Title] = result.Highlights
+		}
+		return highlights
+	}
+
+	t.Run("Matched terms are wrapped in em tags", func(t *testing.T) {
+		highlights := search(t, &domain.SearchRequest{Query: "go"})
+
+		require.Len(t, highlights, 2)
+		assert.Equal(t, "<em>Go</em> Concurrency Patterns", highlights["Go Concurrency Patterns"].Title)
+		assert.Equal(t, []string{"go"}, highlights["Go Concurrency Patterns"].MatchedTerms)
+		assert.Equal(t, "Learning <em>Golang</em>", highlights["Learning Golang"].Title)
+		assert.Equal(t, []string{"golang"}, highlights["Learning Golang"].MatchedTerms)
+	})
+
+	t.Run("Custom tags", func(t *testing.T) {
+		highlights := search(t, &domain.SearchRequest{Query: "rust", HighlightPreTag: "[", HighlightPostTag: "]"})
+
+		require.Len(t, highlights, 1)
+		assert.Equal(t, "[Rust] Ownership", highlights["Rust Ownership"].Title)
+	})
+
+	t.Run("Negated terms are not highlighted", func(t *testing.T) {
+		highlights := search(t, &domain.SearchRequest{Query: "go -concurrency"})
+
+		require.Len(t, highlights, 1)
+		assert.Equal(t, "Learning <em>Golang</em>", highlights["Learning Golang"].Title)
+	})
+
+	t.Run("No title terms means no highlights", func(t *testing.T) {
+		highlights := search(t, &domain.SearchRequest{Query: "type:text"})
+
+		require.Len(t, highlights, 2)
+		assert.Nil(t, highlights["Rust Ownership"])
+		assert.Nil(t, highlights["Learning Golang"])
+	})
+
+	t.Run("Collapsed search", func(t *testing.T) {
+		highlights := search(t, &domain.SearchRequest{Query: "go", Collapse: domain.CollapseProvider})
+
+		require.Len(t, highlights, 1)
+		assert.Equal(t, "<em>Go</em> Concurrency Patterns", highlights["Go Concurrency Patterns"].Title)
+	})
+}
+
+func TestContentRepository_SearchCursor(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewContentRepository(db)
+	ctx := context.Background()
+
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	var contents []*domain.Content
+	for i := 0; i < 7; i++ {
+		contents = append(contents, &domain.Content{
+			ProviderID: fmt.Sprintf("p%d_%d", i%2+1, i),
+			Provider:   fmt.Sprintf("provider%d", i%2+1),
+			Title:      fmt.Sprintf("Kubernetes Guide %d", i),
+			Type:       domain.ContentTypeVideo,
+			Views:      100 * (i % 3),
+			Likes:      i,
+			Duration:   60 * (i % 2),
+			Score:      float64(i % 3),
+			CreatedAt:  base.Add(time.Duration(i%4) * time.Hour),
+		})
+	}
+	contents = append(contents, &domain.Content{ProviderID: "p1_7", Provider: "provider1", Title: "Kubernets Typo", Type: domain.ContentTypeText, Score: 5, CreatedAt: base})
+	require.NoError(t, repo.BatchCreateOrUpdate(ctx, contents))
+
+	ids := func(results []*domain.Content) []int64 {
+		out := make([]int64, 0, len(results))
+		for _, result := range results {
+			out = append(out, result.ID)
+		}
+		return out
+	}
+
+	// walk pages through every result with the cursor of the previous page
+	walk := func(t *testing.T, req domain.SearchRequest) []int64 {
+		var walked []int64
+		cursorSpec := domain.NewCursorSpecification()
+		for pages := 0; pages < 10; pages++ {
+			page := req
+			page.Page, page.PageSize = 1, 3
+			require.NoError(t, cursorSpec.NormalizeCursor(&page))
+
+			results, _, err := repo.Search(ctx, &page)
+			require.NoError(t, err)
+			walked = append(walked, ids(results)...)
+
+			next, err := repo.NextCursor(ctx, &page, results)
+			require.NoError(t, err)
+			if next == "" {
+				return walked
+			}
+			req.Cursor = next
+		}
+		t.Fatal("pagination did not terminate")
+		return nil
+	}
+
+	tests := []struct {
+		name string
+		req  domain.SearchRequest
+	}{
+		{"Score", domain.SearchRequest{}},
+		{"Score ascending", domain.SearchRequest{SortOrder: "asc"}},
+		{"Created at", domain.SearchRequest{SortBy: "created_at"}},
+		{"Trending", domain.SearchRequest{SortBy: "trending"}},
+		{"Duration", domain.SearchRequest{SortBy: "duration", SortOrder: "asc"}},
+		{"Popularity", domain.SearchRequest{SortBy: "popularity"}},
+		{"Mixed document sort", domain.SearchRequest{Sort: []domain.SortField{{Field: "views", Order: "asc"}, {Field: "created_at", Order: "desc"}}}},
+		{"Fuzzy", domain.SearchRequest{Query: "kubernets", Fuzzy: domain.FuzzyAuto}},
+		{"Collapsed", domain.SearchRequest{Collapse: domain.CollapseType}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			all := tt.req
+			all.Page, all.PageSize = 1, 100
+			results, total, err := repo.Search(ctx, &all)
+			require.NoError(t, err)
+
+			assert.Equal(t, ids(results), walk(t, tt.req))
+			assert.Len(t, results, total)
+		})
+	}
+
+	t.Run("Offset pages hand over to cursors", func(t *testing.T) {
+		req := &domain.SearchRequest{Page: 2, PageSize: 3}
+		results, _, err := repo.Search(ctx, req)
+		require.NoError(t, err)
+		next, err := repo.NextCursor(ctx, req, results)
+		require.NoError(t, err)
+
+		following := &domain.SearchRequest{Page: 1, PageSize: 3, Cursor: next}
+		require.NoError(t, domain.NewCursorSpecification().NormalizeCursor(following))
+		cursorPage, _, err := repo.Search(ctx, following)
+		require.NoError(t, err)
+		offsetPage, _, err := repo.Search(ctx, &domain.SearchRequest{Page: 3, PageSize: 3})
+		require.NoError(t, err)
+
+		assert.Equal(t, ids(offsetPage), ids(cursorPage))
+	})
+
+	t.Run("Pages stay stable while scores change", func(t *testing.T) {
+		req := &domain.SearchRequest{Page: 1, PageSize: 3}
+		first, _, err := repo.Search(ctx, req)
+		require.NoError(t, err)
+		next, err := repo.NextCursor(ctx, req, first)
+		require.NoError(t, err)
+
+		// an item from the second page jumps to the top of the ranking
+		all, _, err := repo.Search(ctx, &domain.SearchRequest{Page: 1, PageSize: 100})
+		require.NoError(t, err)
+		require.NoError(t, repo.UpdateScore(ctx, all[4].ID, 100))
+
+		following := &domain.SearchRequest{Page: 1, PageSize: 3, Cursor: next}
+		require.NoError(t, domain.NewCursorSpecification().NormalizeCursor(following))
+		second, _, err := repo.Search(ctx, following)
+		require.NoError(t, err)
+
+		assert.Equal(t, ids([]*domain.Content{all[3], all[5], all[6]}), ids(second))
+		for _, content := range first {
+			assert.NotContains(t, ids(second), content.ID)
+		}
+	})
+}
+
+func TestContentRepository_SearchRelevance(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewContentRepository(db)
+	ctx := context.Background()
+
+	contents := []*domain.Content{
+		{ProviderID: "p1_1", Provider: "provider1", Title: "Docker", Type: domain.ContentTypeVideo, Score: 6},
+		{ProviderID: "p1_2", Provider: "provider1", Title: "Weekly Roundup: Docker, Rust, Go, Python and More News", Type: domain.ContentTypeVideo, Score: 20},
+		{ProviderID: "p2_1", Provider: "provider2", Title: "Docker Compose Basics", Type: domain.ContentTypeText, Score: 8},
+		{ProviderID: "p2_2", Provider: "provider2", Title: "Rust Ownership", Type: domain.ContentTypeText, Score: 9},
+		{ProviderID: "p2_3", Provider: "provider2", Title: "Python Decorators Explained", Type: domain.ContentTypeText, Score: 4},
+		{ProviderID: "p2_4", Provider: "provider2", Title: "Kubernetes Networking Deep Dive", Type: domain.ContentTypeText, Score: 5},
+		{ProviderID: "p2_5", Provider: "provider2", Title: "Postgres Index Tuning", Type: domain.ContentTypeText, Score: 7},
+	}
+	require.NoError(t, repo.BatchCreateOrUpdate(ctx, contents))
+
+	search := func(t *testing.T, req *domain.SearchRequest) []*domain.Content {
+		req.Page, req.PageSize = 1, 10
+		results, total, err := repo.Search(ctx, req)
+		require.NoError(t, err)
+		assert.Len(t, results, total)
+		return results
+	}
+	titles := func(results []*domain.Content) []string {
+		out := make([]string, 0, len(results))
+		for _, result := range results {
+			out = append(out, result.Title)
+		}
+		return out
+	}
+
+	t.Run("Score order puts the popular partial match first", func(t *testing.T) {
+		results := search(t, &domain.SearchRequest{Query: "docker"})
+
+		assert.Equal(t, "Weekly Roundup: Docker, Rust, Go, Python and More News", results[0].Title)
+		assert.Nil(t, results[0].Relevance)
+	})
+
+	t.Run("Relevance puts the exact title match first", func(t *testing.T) {
+		results := search(t, &domain.SearchRequest{Query: "docker", SortBy: domain.SortByRelevance})
+
+		assert.Equal(t, []string{"Docker", "Docker Compose Basics", "Weekly Roundup: Docker, Rust, Go, Python and More News"}, titles(results))
+		for i, result := range results {
+			require.NotNil(t, result.Relevance)
+			if i > 0 {
+				assert.GreaterOrEqual(t, *results[i-1].Relevance, *result.Relevance)
+			}
+		}
+	})
+
+	t.Run("Weights shift the balance towards the score", func(t *testing.T) {
+		weighted := NewContentRepository(db)
+		weighted.SetRelevanceWeights(domain.RelevanceWeights{Text: 0.1, Score: 0.9})
+
+		results, _, err := weighted.Search(ctx, &domain.SearchRequest{Query: "docker", SortBy: domain.SortByRelevance, Page: 1, PageSize: 10})
+
+		require.NoError(t, err)
+		assert.Equal(t, "Weekly Roundup: Docker, Rust, Go, Python and More News", results[0].Title)
+	})
+
+	t.Run("Without a query relevance follows the score", func(t *testing.T) {
+		results := search(t, &domain.SearchRequest{SortBy: domain.SortByRelevance, SortOrder: "asc"})
+
+		assert.Equal(t, []string{"Python Decorators Explained", "Kubernetes Networking Deep Dive", "Docker", "Postgres Index Tuning", "Docker Compose Basics", "Rust Ownership", "Weekly Roundup: Docker, Rust, Go, Python and More News"}, titles(results))
+	})
+
+	t.Run("Collapsed", func(t *testing.T) {
+		results := search(t, &domain.SearchRequest{Query: "docker", SortBy: domain.SortByRelevance, Collapse: domain.CollapseProvider})
+
+		assert.Equal(t, []string{"Docker", "Docker Compose Basics"}, titles(results))
+		assert.Equal(t, 2, results[0].InnerHits.Total)
+		assert.Equal(t, 1, results[1].InnerHits.Total)
+	})
+
+	t.Run("Cursor pages", func(t *testing.T) {
+		req := &domain.SearchRequest{Query: "docker OR rust", SortBy: domain.SortByRelevance, Page: 1, PageSize: 2}
+		first, _, err := repo.Search(ctx, req)
+		require.NoError(t, err)
+		next, err := repo.NextCursor(ctx, req, first)
+		require.NoError(t, err)
+		require.NotEmpty(t, next)
+
+		following := &domain.SearchRequest{Query: "docker OR rust", SortBy: domain.SortByRelevance, Page: 1, PageSize: 2, Cursor: next}
+		require.NoError(t, domain.NewCursorSpecification().NormalizeCursor(following))
+		second, _, err := repo.Search(ctx, following)
+		require.NoError(t, err)
+		last, err := repo.NextCursor(ctx, following, second)
+		require.NoError(t, err)
+
+		all := search(t, &domain.SearchRequest{Query: "docker OR rust", SortBy: domain.SortByRelevance})
+		assert.Equal(t, titles(all), append(titles(first), titles(second)...))
+		assert.Empty(t, last)
+	})
+}
+
+func TestContentRepository_SearchBackend(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewContentRepository(db)
+	index := searchindex.New()
+	repo.SetSearchBackend(index)
+	ctx := context.Background()
+
+	contents := []*domain.Content{
+		{ProviderID: "p1_1", Provider: "provider1", Title: "Go Tutorial", Type: domain.ContentTypeVideo, Score: 5},
+		{ProviderID: "p1_2", Provider: "provider1", Title: "Programming Rust Tutorials", Type: domain.ContentTypeVideo, Score: 8},
+		{ProviderID: "p2_1", Provider: "provider2", Title: "Go Programming", Type: domain.ContentTypeText, Score: 3},
+	}
+	require.NoError(t, repo.BatchCreateOrUpdate(ctx, contents))
+
+	search := func(t *testing.T, req *domain.SearchRequest) []string {
+		req.Page, req.PageSize = 1, 10
+		results, total, err := repo.Search(ctx, req)
+		require.NoError(t, err)
+		assert.Len(t, results, total)
+		titles := make([]string, 0, len(results))
+		for _, result := range results {
+			titles = append(titles, result.Title)
+		}
+		return titles
+	}
+
+	t.Run("Stored content is indexed", func(t *testing.T) {
+		assert.Equal(t, 3, index.Len())
+	})
+
+	t.Run("Words match their stem", func(t *testing.T) {
+		assert.Equal(t, []string{"Programming Rust Tutorials", "Go Tutorial"}, search(t, &domain.SearchRequest{Query: "tutorials"}))
+	})
+
+	t.Run("Boolean queries and filters still apply", func(t *testing.T) {
+		text := domain.ContentTypeText
+		assert.Equal(t, []string{"Go Tutorial"}, search(t, &domain.SearchRequest{Query: "tutorial -rust"}))
+		assert.Equal(t, []string{"Go Programming"}, search(t, &domain.SearchRequest{Query: "programs", ContentType: &text}))
+	})
+
+	t.Run("Relevance is ranked by the index", func(t *testing.T) {
+		results, _, err := repo.Search(ctx, &domain.SearchRequest{Query: "go", SortBy: domain.SortByRelevance, Page: 1, PageSize: 10})
+
+		require.NoError(t, err)
+		require.Len(t, results, 2)
+		for _, result := range results {
+			assert.NotNil(t, result.Relevance)
+		}
+	})
+
+	t.Run("Updates replace the indexed title", func(t *testing.T) {
+		require.NoError(t, repo.BatchCreateOrUpdate(ctx, []*domain.Content{
+			{ProviderID: "p2_1", Provider: "provider2", Title: "Python Programming", Type: domain.ContentTypeText, Score: 3},
+		}))
+
+		assert.Equal(t, []string{"Go Tutorial"}, search(t, &domain.SearchRequest{Query: "go"}))
+		assert.Equal(t, []string{"Python Programming"}, search(t, &domain.SearchRequest{Query: "python"}))
+	})
+
+	t.Run("Reindex fills an empty index", func(t *testing.T) {
+		rebuilt := searchindex.New()
+		repo.SetSearchBackend(rebuilt)
+
+		require.NoError(t, repo.ReindexSearchBackend(ctx))
+
+		assert.Equal(t, 3, rebuilt.Len())
+		assert.Equal(t, []string{"Python Programming"}, search(t, &domain.SearchRequest{Query: "python"}))
+	})
+
+	t.Run("Reindex only catches up with content updated after the index", func(t *testing.T) {
+		behind := searchindex.New()
+		require.NoError(t, behind.Index([]*domain.Content{{ID: contents[0].ID, Title: "Go Tutorial Snapshot", UpdatedAt: contents[0].UpdatedAt}}))
+		repo.SetSearchBackend(behind)
+		require.NoError(t, db.Model(&domain.Content{}).Where("provider_id = ?", "p1_1").
+			UpdateColumn("updated_at", contents[0].UpdatedAt.Add(-time.Hour)).Error)
+
+		require.NoError(t, repo.ReindexSearchBackend(ctx))
+
+		assert.Equal(t, 3, behind.Len())
+		assert.Equal(t, []string{"Python Programming"}, search(t, &domain.SearchRequest{Query: "python"}))
+		// the title indexed before the snapshot was not read again
+		assert.Equal(t, []string{"Go Tutorial"}, search(t, &domain.SearchRequest{Query: "snapshot"}))
+	})
+}
+
+func TestContentRepository_SearchBackendFields(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewContentRepository(db)
+	ctx := context.Background()
+	require.NoError(t, repo.BatchCreateOrUpdate(ctx, []*domain.Content{
+		{ProviderID: "p1_1", Provider: "provider1", Title: "Weekly Roundup", Type: domain.ContentTypeText, Score: 5, Tags: []domain.Tag{{Name: "golang"}}},
+		{ProviderID: "p2_1", Provider: "provider2", Title: "Golang Basics", Type: domain.ContentTypeText, Score: 3},
+		{ProviderID: "p2_2", Provider: "provider2", Title: "Rust Basics", Type: domain.ContentTypeText, Score: 4},
+	}))
+
+	search := func(t *testing.T, query string) []string {
+		results, _, err := repo.Search(ctx, &domain.SearchRequest{Query: query, Page: 1, PageSize: 10})
+		require.NoError(t, err)
+		titles := make([]string, 0, len(results))
+		for _, result := range results {
+			titles = append(titles, result.Title)
+		}
+		return titles
+	}
+
+	for _, query := range []string{"golang", "provider2", "rust"} {
+		repo.SetSearchBackend(nil)
+		want := search(t, query)
+		require.NotEmpty(t, want)
+		repo.SetSearchBackend(searchindex.New())
+		require.NoError(t, repo.ReindexSearchBackend(ctx))
+
+		assert.Equal(t, want, search(t, query), query)
+	}
+}
+
+// manyMatchesBackend matches every title term with more content IDs than SQLite accepts as
+// bind parameters
+type manyMatchesBackend struct{}
+
+func (manyMatchesBackend) MatchTitle(*domain.TermNode, string, string) []int64 {
+	ids := make([]int64, 100000)
+	for i := range ids {
+		ids[i] = int64(i + 1)
+	}
+	return ids
+}
+
+func (manyMatchesBackend) RankTitles([]*domain.TermNode, []int64) map[int64]float64 { return nil }
+func (manyMatchesBackend) Index([]*domain.Content) error                          { return nil }
+func (manyMatchesBackend) IndexedUntil() time.Time                                { return time.Time{} }
+
+func TestContentRepository_SearchBackendManyMatches(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewContentRepository(db)
+	repo.SetSearchBackend(manyMatchesBackend{})
+	ctx := context.Background()
+	require.NoError(t, repo.BatchCreateOrUpdate(ctx, []*domain.Content{
+		{ProviderID: "p1_1", Provider: "provider1", Title: "Go Tutorial", Type: domain.ContentTypeVideo, Score: 5},
+		{ProviderID: "p1_2", Provider: "provider1", Title: "Rust Tutorial", Type: domain.ContentTypeVideo, Score: 8},
+	}))
+
+	results, total, err := repo.Search(ctx, &domain.SearchRequest{Query: "tutorial", Page: 1, PageSize: 10})
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, total)
+	assert.Len(t, results, 2)
+}
+
+func TestContentRepository_SearchLanguage(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewContentRepository(db)
+	ctx := context.Background()
+
+	contents := []*domain.Content{
+		{ProviderID: "p1_1", Provider: "provider1", Title: "Haus Music Classics", Type: domain.ContentTypeVideo, Score: 5, Language: domain.LanguageEnglish},
+		{ProviderID: "p1_2", Provider: "provider1", Title: "Das Haus am See", Type: domain.ContentTypeText, Score: 8, Language: domain.LanguageGerman},
+		{ProviderID: "p2_1", Provider: "provider2", Title: "Häuser in Berlin", Type: domain.ContentTypeText, Score: 3, Language: domain.LanguageGerman},
+	}
+	require.NoError(t, repo.BatchCreateOrUpdate(ctx, contents))
+
+	search := func(t *testing.T, req *domain.SearchRequest) []string {
+		req.Page, req.PageSize = 1, 10
+		results, _, err := repo.Search(ctx, req)
+		require.NoError(t, err)
+		titles := make([]string, 0, len(results))
+		for _, result := range results {
+			titles = append(titles, result.Title)
+		}
+		return titles
+	}
+
+	t.Run("Language is stored", func(t *testing.T) {
+		var stored domain.Content
+		require.NoError(t, db.Where("provider_id = ?", "p1_2").First(&stored).Error)
+
+		assert.Equal(t, domain.LanguageGerman, stored.Language)
+	})
+
+	t.Run("Language restricts results", func(t *testing.T) {
+		assert.Equal(t, []string{"Das Haus am See", "Haus Music Classics"}, search(t, &domain.SearchRequest{Query: "haus"}))
+		assert.Equal(t, []string{"Das Haus am See"}, search(t, &domain.SearchRequest{Query: "haus", Language: domain.LanguageGerman}))
+	})
+
+	t.Run("Index backend stems titles in their language", func(t *testing.T) {
+		repo.SetSearchBackend(searchindex.New())
+		require.NoError(t, repo.ReindexSearchBackend(ctx))
+
+		assert.Equal(t, []string{"Das Haus am See", "Häuser in Berlin"}, search(t, &domain.SearchRequest{Query: "haus", Language: domain.LanguageGerman}))
+	})
+}
+
+func TestContentRepository_SearchBoost(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewContentRepository(db)
+	ctx := context.Background()
+
+	contents := []*domain.Content{
+		{ProviderID: "p1_1", Provider: "provider1", Title: "Docker Basics", Type: domain.ContentTypeVideo, Score: 5},
+		{ProviderID: "p1_2", Provider: "provider1", Title: "Shipping Containers", Type: domain.ContentTypeVideo, Score: 5, Tags: domain.NewTags("docker")},
+		{ProviderID: "p2_1", Provider: "provider2", Title: "Rust Ownership", Type: domain.ContentTypeText, Score: 5},
+	}
+	require.NoError(t, repo.BatchCreateOrUpdate(ctx, contents))
+
+	search := func(t *testing.T, req *domain.SearchRequest) []string {
+		req.Page, req.PageSize, req.SortBy = 1, 10, domain.SortByRelevance
+		require.NoError(t, domain.NewBoostSpecification().NormalizeBoost(req))
+		results, _, err := repo.Search(ctx, req)
+		require.NoError(t, err)
+		titles := make([]string, 0, len(results))
+		for _, result := range results {
+			titles = append(titles, result.Title)
+		}
+		return titles
+	}
+
+	t.Run("Tags and provider match", func(t *testing.T) {
+		assert.ElementsMatch(t, []string{"Docker Basics", "Shipping Containers"}, search(t, &domain.SearchRequest{Query: "docker"}))
+		assert.Equal(t, []string{"Rust Ownership"}, search(t, &domain.SearchRequest{Query: "provider2"}))
+	})
+
+	t.Run("Title matches outrank tag-only matches", func(t *testing.T) {
+		assert.Equal(t, []string{"Docker Basics", "Shipping Containers"}, search(t, &domain.SearchRequest{Query: "docker"}))
+	})
+
+	t.Run("Boosts reorder fields", func(t *testing.T) {
+		assert.Equal(t, []string{"Shipping Containers", "Docker Basics"}, search(t, &domain.SearchRequest{Query: "docker", Boost: "title:1,tags:3"}))
+	})
+}
+
+func TestContentRepository_FindRelated(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewContentRepository(db)
+	ctx := context.Background()
+
+	contents := []*domain.Content{
+		{ProviderID: "p1_1", Provider: "provider1", Title: "Go Concurrency Patterns", Type: domain.ContentTypeVideo, Score: 5, Tags: []domain.Tag{{Name: "golang"}}},
+		{ProviderID: "p1_2", Provider: "provider1", Title: "Advanced Go Concurrency", Type: domain.ContentTypeVideo, Score: 4},
+		{ProviderID: "p2_1", Provider: "provider2", Title: "Channels in Practice", Type: domain.ContentTypeText, Score: 9, Tags: []domain.Tag{{Name: "golang"}}},
+		{ProviderID: "p2_2", Provider: "provider2", Title: "Go Generics", Type: domain.ContentTypeText, Score: 2},
+		{ProviderID: "p2_3", Provider: "provider2", Title: "Python Decorators", Type: domain.ContentTypeText, Score: 30},
+		{ProviderID: "p2_4", Provider: "provider2", Title: "Go Concurrency Patterns", Type: domain.ContentTypeVideo, Score: 5},
+	}
+	require.NoError(t, repo.BatchCreateOrUpdate(ctx, contents))
+	require.NoError(t, repo.LinkDuplicate(ctx, contents[5].ID, contents[0].ID))
+
+	source, err := repo.GetByID(ctx, contents[0].ID)
+	require.NoError(t, err)
+
+	t.Run("Ranks by similarity and leaves out the item and its duplicates", func(t *testing.T) {
+		related, total, err := repo.FindRelated(ctx, source, &domain.RelatedRequest{Page: 1, PageSize: 10})
+
+		require.NoError(t, err)
+		assert.Equal(t, 3, total)
+		titles := make([]string, 0, len(related))
+		for _, content := range related {
+			titles = append(titles, content.Title)
+			assert.NotNil(t, content.Relevance)
+		}
+		assert.Equal(t, []string{"Advanced Go Concurrency", "Channels in Practice", "Go Generics"}, titles)
+	})
+
+	t.Run("From a duplicate", func(t *testing.T) {
+		duplicate, err := repo.GetByID(ctx, contents[5].ID)
+		require.NoError(t, err)
+
+		related, total, err := repo.FindRelated(ctx, duplicate, &domain.RelatedRequest{Page: 1, PageSize: 10})
+
+		require.NoError(t, err)
+		assert.Equal(t, 2, total)
+		for _, content := range related {
+			assert.NotEqual(t, contents[0].ID, content.ID)
+		}
+	})
+
+	t.Run("Pages", func(t *testing.T) {
+		related, total, err := repo.FindRelated(ctx, source, &domain.RelatedRequest{Page: 2, PageSize: 2})
+
+		require.NoError(t, err)
+		assert.Equal(t, 3, total)
+		require.Len(t, related, 1)
+		assert.Equal(t, "Go Generics", related[0].Title)
+	})
+
+	t.Run("Nothing in common", func(t *testing.T) {
+		related, total, err := repo.FindRelated(ctx, &domain.Content{ID: 100, Title: "Knitting"}, &domain.RelatedRequest{Page: 1, PageSize: 10})
+
+		require.NoError(t, err)
+		assert.Equal(t, 0, total)
+		assert.Empty(t, related)
+	})
+}
+
+func TestContentRepository_FindRelatedCapsCandidates(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewContentRepository(db)
+	ctx := context.Background()
+
+	contents := []*domain.Content{
+		{ProviderID: "p1_1", Provider: "provider1", Title: "Go Concurrency Patterns", Type: domain.ContentTypeVideo, Tags: domain.NewTags("golang")},
+	}
+	for i := 0; i < maxRelatedCandidates; i++ {
+		contents = append(contents, &domain.Content{
+			ProviderID: fmt.Sprintf("p2_%d", i), Provider: "provider2", Title: fmt.Sprintf("Episode %d", i),
+			Type: domain.ContentTypeText, Score: 50, Tags: domain.NewTags("golang"),
+		})
+	}
+	// stored last, so only sharing the most with the source keeps it among the candidates
+	contents = append(contents, &domain.Content{
+		ProviderID: "p1_2", Provider: "provider1", Title: "Go Concurrency Patterns Revisited",
+		Type: domain.ContentTypeVideo, Score: 50, Tags: domain.NewTags("golang"),
+	})
+	require.NoError(t, repo.BatchCreateOrUpdate(ctx, contents))
+
+	related, total, err := repo.FindRelated(ctx, contents[0], &domain.RelatedRequest{Page: 1, PageSize: 1})
+
+	require.NoError(t, err)
+	assert.Equal(t, maxRelatedCandidates, total)
+	require.Len(t, related, 1)
+	assert.Equal(t, "Go Concurrency Patterns Revisited", related[0].Title, "the candidate sharing the most is kept")
+}
+
+func TestContentRepository_QueryLog(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewContentRepository(db)
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	require.NoError(t, repo.RecordQueries(ctx, map[string]int{"kubernetes": 1, "golang": 1}, now))
+	require.NoError(t, repo.RecordQueries(ctx, map[string]int{"kubernetes": 1}, now.Add(time.Minute)))
+
+	queries, err := repo.ListPopularQueries(ctx, 10)
+
+	require.NoError(t, err)
+	require.Len(t, queries, 2)
+	assert.Equal(t, "kubernetes", queries[0].Query)
+	assert.Equal(t, 2, queries[0].Count)
+	assert.WithinDuration(t, now.Add(time.Minute), queries[0].LastSearchedAt, time.Second)
+	assert.Equal(t, "golang", queries[1].Query)
+	assert.Equal(t, 1, queries[1].Count)
+
+	t.Run("Limit is honoured", func(t *testing.T) {
+		queries, err := repo.ListPopularQueries(ctx, 1)
+
+		require.NoError(t, err)
+		assert.Len(t, queries, 1)
+	})
+
+	t.Run("Pruning keeps the most searched queries", func(t *testing.T) {
+		require.NoError(t, repo.RecordQueries(ctx, map[string]int{"rust": 5, "zig": 1}, now))
+
+		require.NoError(t, repo.PruneQueries(ctx, 2))
+
+		queries, err := repo.ListPopularQueries(ctx, 10)
+		require.NoError(t, err)
+		require.Len(t, queries, 2)
+		assert.Equal(t, "rust", queries[0].Query)
+		assert.Equal(t, "kubernetes", queries[1].Query)
+	})
+}
+
+func TestContentRepository_ListSuggestTitles(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewContentRepository(db)
+	ctx := context.Background()
+
+	contents := []*domain.Content{
+		{ProviderID: "p1_1", Provider: "provider1", Title: "Intro to Kubernetes", Type: domain.ContentTypeVideo, Score: 9},
+		{ProviderID: "p2_1", Provider: "provider2", Title: "Intro to Kubernetes", Type: domain.ContentTypeVideo, Score: 4},
+	}
+	require.NoError(t, repo.BatchCreateOrUpdate(ctx, contents))
+	require.NoError(t, repo.LinkDuplicate(ctx, contents[1].ID, contents[0].ID))
+
+	titles, err := repo.ListSuggestTitles(ctx)
+
+	require.NoError(t, err)
+	require.Len(t, titles, 1)
+	assert.Equal(t, "Intro to Kubernetes", titles[0].Title)
+	assert.Equal(t, domain.ContentTypeVideo, titles[0].Type)
+	assert.Equal(t, 9.0, titles[0].Score)
+}
+
+func TestContentRepository_ListSpellingDocuments(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewContentRepository(db)
+	ctx := context.Background()
+
+	contents := []*domain.Content{
+		{ProviderID: "p1_1", Provider: "provider1", Title: "Kubernetes Operators", Type: domain.ContentTypeVideo, Tags: domain.NewTags("kubernetes", "devops")},
+		{ProviderID: "p1_2", Provider: "provider1", Title: "The Docker Handbook", Type: domain.ContentTypeText, Tags: domain.NewTags("devops")},
+		{ProviderID: "p2_1", Provider: "provider2", Title: "Docker Handbook", Type: domain.ContentTypeText},
+	}
+	require.NoError(t, repo.BatchCreateOrUpdate(ctx, contents))
+	require.NoError(t, repo.LinkDuplicate(ctx, contents[2].ID, contents[1].ID))
+
+	documents, err := repo.ListSpellingDocuments(ctx)
+
+	require.NoError(t, err)
+	require.Len(t, documents, 3)
+	assert.Equal(t, "Kubernetes Operators", documents[0].Title)
+	assert.ElementsMatch(t, []string{"devops", "kubernetes"}, domain.TagNames(documents[0].Tags))
+	assert.True(t, documents[1].IsCanonical())
+	require.NotNil(t, documents[2].CanonicalID)
+	assert.Equal(t, contents[1].ID, *documents[2].CanonicalID)
+	assert.Empty(t, documents[2].Tags)
+}
+
+func TestContentRepository_ExportSearch(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewContentRepository(db)
+	ctx := context.Background()
+
+	var contents []*domain.Content
+	for i := 1; i <= 7; i++ {
+		contents = append(contents, &domain.Content{ProviderID: fmt.Sprintf("v%d", i), Provider: "provider1", Title: fmt.Sprintf("Go Video %d", i), Type: domain.ContentTypeVideo, Score: float64(i)})
+	}
+	contents = append(contents, &domain.Content{ProviderID: "t1", Provider: "provider2", Title: "Go Article", Type: domain.ContentTypeText, Score: 100})
+	require.NoError(t, repo.BatchCreateOrUpdate(ctx, contents))
+
+	export := func(t *testing.T, req *domain.SearchRequest) ([]float64, []int) {
+		var scores []float64
+		var sizes []int
+		err := repo.ExportSearch(ctx, req, 3, func(batch []*domain.Content) error {
+			sizes = append(sizes, len(batch))
+			for _, content := range batch {
+				scores = append(scores, content.Score)
+			}
+			return nil
+		})
+		require.NoError(t, err)
+		return scores, sizes
+	}
+
+	t.Run("Reads every match in batches", func(t *testing.T) {
+		videoType := domain.ContentTypeVideo
+		scores, sizes := export(t, &domain.SearchRequest{ContentType: &videoType, SortBy: "score", SortOrder: "desc", Page: 3, PageSize: 1})
+
+		assert.Equal(t, []float64{7, 6, 5, 4, 3, 2, 1}, scores)
+		assert.Equal(t, []int{3, 3, 1}, sizes)
+	})
+
+	t.Run("Honors filters", func(t *testing.T) {
+		minScore := 5.0
+		scores, _ := export(t, &domain.SearchRequest{Query: "go", MinScore: &minScore, SortBy: "score", SortOrder: "asc"})
+
+		assert.Equal(t, []float64{5, 6, 7, 100}, scores)
+	})
+
+	t.Run("Rejects relevance without PostgreSQL text ranking", func(t *testing.T) {
+		calls := 0
+		err := repo.ExportSearch(ctx, &domain.SearchRequest{Query: "go", SortBy: domain.SortByRelevance, SortOrder: "desc"}, 3, func([]*domain.Content) error {
+			calls++
+			return nil
+		})
+
+		var domainErr *domain.DomainError
+		require.ErrorAs(t, err, &domainErr)
+		assert.Equal(t, domain.ErrorCodeInvalidInput, domainErr.Code)
+		assert.Zero(t, calls)
+	})
+
+	t.Run("No matches", func(t *testing.T) {
+		scores, sizes := export(t, &domain.SearchRequest{Query: "python", SortBy: "score", SortOrder: "desc"})
+
+		assert.Empty(t, scores)
+		assert.Empty(t, sizes)
+	})
+
+	t.Run("Collapsed", func(t *testing.T) {
+		scores, _ := export(t, &domain.SearchRequest{Collapse: domain.CollapseProvider, SortBy: "score", SortOrder: "desc"})
+
+		assert.Equal(t, []float64{100, 7}, scores)
+	})
+
+	t.Run("Never counts the matches", func(t *testing.T) {
+		counts := 0
+		require.NoError(t, db.Callback().Query().After("gorm:query").Register("test:count_queries", func(tx *gorm.DB) {
+			if strings.Contains(strings.ToLower(tx.Statement.SQL.String()), "count(") {
+				counts++
+			}
+		}))
+		defer db.Callback().Query().Remove("test:count_queries")
+
+		export(t, &domain.SearchRequest{Query: "go", SortBy: "score", SortOrder: "desc"})
+
+		assert.Zero(t, counts)
+	})
+
+	t.Run("Stops at the first callback error", func(t *testing.T) {
+		calls := 0
+		err := repo.ExportSearch(ctx, &domain.SearchRequest{SortBy: "score", SortOrder: "desc"}, 3, func([]*domain.Content) error {
+			calls++
+			return assert.AnError
+		})
+
+		assert.ErrorIs(t, err, assert.AnError)
+		assert.Equal(t, 1, calls)
+	})
+}
@@ -0,0 +1,270 @@
+package repository
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"search-engine-go/internal/domain"
+)
+
+// searchVectorSQL computes the stored search vector of a content row: its title with weight A,
+// its tag names with weight B and its provider with weight C, analyzed with the configuration
+// of the content's language
+const searchVectorSQL = `setweight(to_tsvector(content_search_config(language), title), 'A') ||
+	setweight(to_tsvector(content_search_config(language), COALESCE((SELECT string_agg(tags.name, ' ')
+		FROM content_tags JOIN tags ON tags.id = content_tags.tag_id
+		WHERE content_tags.content_id = contents.id), '')), 'B') ||
+	setweight(to_tsvector(content_search_config(language), provider), 'C')`
+
+// queryCompiler turns a parsed search query into a SQL condition for the contents table.
+// Title terms match the title, tags and provider: with the stored search vector on PostgreSQL
+// and LIKE on SQLite.
+type queryCompiler struct {
+	postgres bool
+	// language restricts title matching to content in one language when set
+	language string
+	// fuzzy is the request's fuzzy setting. Fuzzy title terms also match titles by pg_trgm
+	// word similarity on PostgreSQL; on SQLite they are expanded against vocabulary, the words
+	// occurring in titles, tags and providers.
+	fuzzy      string
+	vocabulary *searchVocabulary
+	// backend matches title terms in place of the database when set
+	backend SearchBackend
+}
+
+func (c *queryCompiler) compile(node domain.QueryNode) (string, []interface{}, error) {
+	switch n := node.(type) {
+	case *domain.TermNode:
+		return c.compileTerm(n)
+	case *domain.AndNode:
+		return c.compileGroup(n.Children, " AND ")
+	case *domain.OrNode:
+		return c.compileGroup(n.Children, " OR ")
+	case *domain.RangeNode:
+		return c.compileRange(n)
+	case *domain.MinimumMatchNode:
+		return c.compileMinimumMatch(n)
+	case *domain.NotNode:
+		sql, args, err := c.compile(n.Child)
+		if err != nil {
+			return "", nil, err
+		}
+		return "NOT (" + sql + ")", args, nil
+	default:
+		return "", nil, fmt.Errorf("unsupported query node %T", node)
+	}
+}
+
+func (c *queryCompiler) compileGroup(children []domain.QueryNode, operator string) (string, []interface{}, error) {
+	parts := make([]string, 0, len(children))
+	var args []interface{}
+	for _, child := range children {
+		sql, childArgs, err := c.compile(child)
+		if err != nil {
+			return "", nil, err
+		}
+		parts = append(parts, sql)
+		args = append(args, childArgs...)
+	}
+	return "(" + strings.Join(parts, operator) + ")", args, nil
+}
+
+func (c *queryCompiler) compileTerm(term *domain.TermNode) (string, []interface{}, error) {
+	switch term.Field {
+	case domain.QueryFieldTitle:
+		return c.compileTitle(term)
+	case domain.QueryFieldType:
+		return "type = ?", []interface{}{term.Value}, nil
+	case domain.QueryFieldProvider:
+		return "LOWER(provider) = ?", []interface{}{term.Value}, nil
+	case domain.QueryFieldTag:
+		return `id IN (SELECT content_tags.content_id FROM content_tags
+			JOIN tags ON tags.id = content_tags.tag_id WHERE tags.name = ?)`, []interface{}{term.Value}, nil
+	default:
+		return "", nil, fmt.Errorf("unsupported query field %q", term.Field)
+	}
+}
+
+func (c *queryCompiler) compileTitle(term *domain.TermNode) (string, []interface{}, error) {
+	if c.backend != nil {
+		ids := c.backend.MatchTitle(term, c.fuzzy, c.language)
+		if len(ids) == 0 {
+			return "1 = 0", nil, nil
+		}
+		condition, args := c.idIn(ids)
+		return condition, args, nil
+	}
+
+	fuzzySpec := domain.NewFuzzySpecification()
+	edits := 0
+	if !term.Phrase {
+		edits = fuzzySpec.MaxEdits(c.fuzzy, term.Value)
+	}
+
+	if c.postgres {
+		if term.Phrase {
+			condition, args := c.titleMatch("phraseto_tsquery", term.Value)
+			return condition, args, nil
+		}
+		condition, args := c.titleMatch("plainto_tsquery", term.Value)
+		if edits > 0 {
+			// <% finds candidates with the trigram index at the threshold of the session, set by
+			// withTrigramThreshold to the lowest of the query; word_similarity applies this term's
+			return "(" + condition + " OR (? <% title AND word_similarity(?, title) >= ?))",
+				append(args, term.Value, term.Value, fuzzySpec.SimilarityThreshold(term.Value, edits)), nil
+		}
+		return condition, args, nil
+	}
+
+	condition, args := fieldsLike(term.Value)
+	if edits == 0 {
+		return condition, args, nil
+	}
+	conditions := []string{condition}
+	for _, word := range c.vocabulary.expand(term.Value, edits) {
+		expansion, expansionArgs := fieldsLike(word)
+		conditions = append(conditions, expansion)
+		args = append(args, expansionArgs...)
+	}
+	if len(conditions) == 1 {
+		return conditions[0], args, nil
+	}
+	return "(" + strings.Join(conditions, " OR ") + ")", args, nil
+}
+
+// fuzzyThreshold returns the lowest word similarity any fuzzy title term of nodes accepts, and
+// false when nodes have no fuzzy title terms
+func fuzzyThreshold(fuzzy string, nodes ...domain.QueryNode) (float64, bool) {
+	fuzzySpec := domain.NewFuzzySpecification()
+	lowest, found := 0.0, false
+	var visit func(domain.QueryNode)
+	visit = func(node domain.QueryNode) {
+		switch n := node.(type) {
+		case *domain.TermNode:
+			if n.Field != domain.QueryFieldTitle || n.Phrase {
+				return
+			}
+			if edits := fuzzySpec.MaxEdits(fuzzy, n.Value); edits > 0 {
+				threshold := fuzzySpec.SimilarityThreshold(n.Value, edits)
+				if !found || threshold < lowest {
+					lowest, found = threshold, true
+				}
+			}
+		case *domain.AndNode:
+			for _, child := range n.Children {
+				visit(child)
+			}
+		case *domain.OrNode:
+			for _, child := range n.Children {
+				visit(child)
+			}
+		case *domain.MinimumMatchNode:
+			for _, child := range n.Children {
+				visit(child)
+			}
+		case *domain.NotNode:
+			visit(n.Child)
+		}
+	}
+	for _, node := range nodes {
+		if node != nil {
+			visit(node)
+		}
+	}
+	return lowest, found
+}
+
+// idIn matches the content whose ID is in ids. The IDs a backend matched can be as many as
+// there are rows, more than either database accepts as bind parameters, so they are bound as
+// a single array: a bigint[] on PostgreSQL and a JSON array on SQLite.
+func (c *queryCompiler) idIn(ids []int64) (string, []interface{}) {
+	var list strings.Builder
+	for i, id := range ids {
+		if i > 0 {
+			list.WriteByte(',')
+		}
+		list.WriteString(strconv.FormatInt(id, 10))
+	}
+	if c.postgres {
+		return "id = ANY(?::bigint[])", []interface{}{"{" + list.String() + "}"}
+	}
+	return "id IN (SELECT value FROM json_each(?))", []interface{}{"[" + list.String() + "]"}
+}
+
+// fieldsLike matches a term anywhere in the title, a tag name or the provider, the fields the
+// search vector covers
+func fieldsLike(value string) (string, []interface{}) {
+	pattern := "%" + value + "%"
+	return `(title LIKE ? OR provider LIKE ? OR id IN (SELECT content_tags.content_id FROM content_tags
+			JOIN tags ON tags.id = content_tags.tag_id WHERE tags.name LIKE ?))`, []interface{}{pattern, pattern, pattern}
+}
+
+// titleMatch matches search vectors against the tsquery function builds from value. Each
+// language gets its own branch with a constant configuration, so that the query is stemmed like
+// the vectors it is matched against and the search vector index stays usable.
+func (c *queryCompiler) titleMatch(function, value string) (string, []interface{}) {
+	languages := domain.Languages
+	if c.language != "" {
+		languages = []string{c.language}
+	}
+
+	branches := make([]string, 0, len(languages))
+	args := make([]interface{}, 0, 3*len(languages))
+	for _, language := range languages {
+		branches = append(branches, fmt.Sprintf("(language = ? AND search_vector @@ %s(?::regconfig, ?))", function))
+		args = append(args, language, domain.TextSearchConfigs[language], value)
+	}
+	return "(" + strings.Join(branches, " OR ") + ")", args
+}
+
+// rangeColumns lists the columns a range clause may bound
+var rangeColumns = map[string]string{
+	"views":      "views",
+	"likes":      "likes",
+	"score":      "score",
+	"created_at": "created_at",
+}
+
+func (c *queryCompiler) compileRange(node *domain.RangeNode) (string, []interface{}, error) {
+	column, ok := rangeColumns[node.Field]
+	if !ok {
+		return "", nil, fmt.Errorf("unsupported range field %q", node.Field)
+	}
+
+	var conditions []string
+	var args []interface{}
+	if node.Lower != nil {
+		operator := ">"
+		if node.LowerInclusive {
+			operator = ">="
+		}
+		conditions = append(conditions, fmt.Sprintf("%s %s ?", column, operator))
+		args = append(args, node.Lower)
+	}
+	if node.Upper != nil {
+		operator := "<"
+		if node.UpperInclusive {
+			operator = "<="
+		}
+		conditions = append(conditions, fmt.Sprintf("%s %s ?", column, operator))
+		args = append(args, node.Upper)
+	}
+	return "(" + strings.Join(conditions, " AND ") + ")", args, nil
+}
+
+// compileMinimumMatch counts matching children with CASE expressions
+func (c *queryCompiler) compileMinimumMatch(node *domain.MinimumMatchNode) (string, []interface{}, error) {
+	parts := make([]string, 0, len(node.Children))
+	var args []interface{}
+	for _, child := range node.Children {
+		sql, childArgs, err := c.compile(child)
+		if err != nil {
+			return "", nil, err
+		}
+		parts = append(parts, "(CASE WHEN "+sql+" THEN 1 ELSE 0 END)")
+		args = append(args, childArgs...)
+	}
+	args = append(args, node.Minimum)
+	return "(" + strings.Join(parts, " + ") + ") >= ?", args, nil
+}
@@ -0,0 +1,166 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"search-engine-go/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+// relevanceDocument holds the fields of a canonical content item that relevance is ranked on
+type relevanceDocument struct {
+	title    string
+	language string
+	tags     string
+	provider string
+}
+
+// relevanceCorpus holds the BM25 statistics of canonical content that searches ranked
+// in-process and related content are scored against: those of the titles, tag names and
+// providers, and of the titles analyzed in their language. It is loaded from the database on
+// first use and kept up to date as content is stored, linked as a duplicate and unlinked, so
+// that a search only scores its own hits.
+type relevanceCorpus struct {
+	mu        sync.RWMutex
+	loaded    bool
+	documents map[int64]relevanceDocument
+	titles    *domain.BM25Corpus
+	tags      *domain.BM25Corpus
+	providers *domain.BM25Corpus
+	// analyzed counts the titles analyzed in their language, as related content compares them
+	analyzed *domain.BM25Corpus
+}
+
+func newRelevanceCorpus() *relevanceCorpus {
+	c := &relevanceCorpus{}
+	c.reset()
+	return c
+}
+
+func (c *relevanceCorpus) reset() {
+	c.documents = make(map[int64]relevanceDocument)
+	c.titles = domain.NewBM25Corpus()
+	c.tags = domain.NewBM25Corpus()
+	c.providers = domain.NewBM25Corpus()
+	c.analyzed = domain.NewBM25Corpus()
+}
+
+// load reads the canonical content once
+func (c *relevanceCorpus) load(ctx context.Context, db *gorm.DB) error {
+	c.mu.RLock()
+	loaded := c.loaded
+	c.mu.RUnlock()
+	if loaded {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.loaded {
+		return nil
+	}
+	var batch []*domain.Content
+	if err := db.WithContext(ctx).Model(&domain.Content{}).
+		Select("id", "title", "language", "provider").
+		Where("canonical_id IS NULL").
+		Preload("Tags").
+		FindInBatches(&batch, 500, func(tx *gorm.DB, _ int) error {
+			for _, content := range batch {
+				c.putLocked(content)
+			}
+			return nil
+		}).Error; err != nil {
+		// a partial load is read again in full next time
+		c.reset()
+		return err
+	}
+	c.loaded = true
+	return nil
+}
+
+// put counts canonical content, in place of what was counted for it before. Changes before
+// the corpus is loaded are skipped, as the load reads them.
+func (c *relevanceCorpus) put(contents []*domain.Content) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.loaded {
+		return
+	}
+	for _, content := range contents {
+		c.putLocked(content)
+	}
+}
+
+func (c *relevanceCorpus) putLocked(content *domain.Content) {
+	c.removeLocked(content.ID)
+	doc := relevanceDocument{
+		title:    content.Title,
+		language: domain.ContentLanguage(content),
+		tags:     strings.Join(domain.TagNames(content.Tags), " "),
+		provider: content.Provider,
+	}
+	c.documents[content.ID] = doc
+	c.titles.AddTitle(doc.title)
+	c.tags.AddTitle(doc.tags)
+	c.providers.AddTitle(doc.provider)
+	c.analyzed.Add(domain.AnalyzeTextIn(doc.title, doc.language))
+}
+
+// remove uncounts content that is no longer canonical
+func (c *relevanceCorpus) remove(ids []int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, id := range ids {
+		c.removeLocked(id)
+	}
+}
+
+func (c *relevanceCorpus) removeLocked(id int64) {
+	doc, ok := c.documents[id]
+	if !ok {
+		return
+	}
+	delete(c.documents, id)
+	c.titles.RemoveTitle(doc.title)
+	c.tags.RemoveTitle(doc.tags)
+	c.providers.RemoveTitle(doc.provider)
+	c.analyzed.Remove(domain.AnalyzeTextIn(doc.title, doc.language))
+}
+
+// titleRanks returns the BM25 rank of each hit's title for the query tokens
+func (c *relevanceCorpus) titleRanks(tokens []string, hits []*relevanceHit) map[int64]float64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	ranks := make(map[int64]float64, len(hits))
+	for _, hit := range hits {
+		ranks[hit.ID] = c.titles.ScoreTitle(tokens, hit.Title)
+	}
+	return ranks
+}
+
+// fieldRanks adds the BM25 ranks of each hit's tag names and provider for the query tokens,
+// weighted by the field boosts, to ranks
+func (c *relevanceCorpus) fieldRanks(tokens []string, boosts domain.FieldBoosts, hits []*relevanceHit, ranks map[int64]float64) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for _, hit := range hits {
+		doc := c.documents[hit.ID]
+		ranks[hit.ID] += boosts.Tags*c.tags.ScoreTitle(tokens, doc.tags) +
+			boosts.Provider*c.providers.ScoreTitle(tokens, doc.provider)
+	}
+}
+
+// similarities returns how related each candidate is to source
+func (c *relevanceCorpus) similarities(source *domain.Content, candidates []*domain.Content) []float64 {
+	relatedSpec := domain.NewRelatedSpecification()
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	similarities := make([]float64, len(candidates))
+	for i, candidate := range candidates {
+		similarities[i] = relatedSpec.Similarity(source, candidate, c.analyzed)
+	}
+	return similarities
+}
@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"time"
+
+	"search-engine-go/internal/domain"
+)
+
+const (
+	// SearchBackendSQL matches titles with the database: full-text search on PostgreSQL and
+	// LIKE on SQLite
+	SearchBackendSQL = "sql"
+	// SearchBackendIndex matches title terms against titles, tag names and providers, and
+	// ranks titles, with the in-process inverted index
+	SearchBackendIndex = "index"
+)
+
+// SearchBackend matches the title terms of a query against the fields the search vector
+// covers, and ranks titles for sort_by=relevance, in place of the database's text search. Filters, sorting, collapsing and paging still run in
+// SQL, restricted to the content the backend matched.
+type SearchBackend interface {
+	// MatchTitle returns the IDs of the content whose title, tag names and provider match a
+	// term, restricted to content in language unless it is empty
+	MatchTitle(term *domain.TermNode, fuzzy, language string) []int64
+	// RankTitles returns the BM25 rank of the titles of ids for the title terms of a query
+	RankTitles(terms []*domain.TermNode, ids []int64) map[int64]float64
+	// Index adds new content and replaces updated content, with their tags
+	Index(contents []*domain.Content) error
+	// IndexedUntil returns the latest update time of the indexed content, or the zero time
+	// when nothing is indexed yet
+	IndexedUntil() time.Time
+}
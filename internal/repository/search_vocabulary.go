@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+
+	"search-engine-go/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+// maxFuzzyExpansions caps how many vocabulary words a fuzzy title term is expanded into on
+// SQLite, each of which adds LIKE conditions to the query
+const maxFuzzyExpansions = 20
+
+// searchVocabulary counts the normalized words of canonical titles, tag names and providers,
+// the fields title terms match, which fuzzy title terms are expanded against on SQLite. It is
+// loaded from the database on first use and kept up to date as content is stored and linked
+// as a duplicate. Tags and providers are never deleted, so their words are only added.
+type searchVocabulary struct {
+	mu     sync.RWMutex
+	loaded bool
+	words  map[string]int
+	// names holds the tag names and providers whose words have been added
+	names map[string]bool
+}
+
+func newSearchVocabulary() *searchVocabulary {
+	return &searchVocabulary{words: make(map[string]int), names: make(map[string]bool)}
+}
+
+// load reads the titles of canonical content, the tag names and the providers once
+func (v *searchVocabulary) load(ctx context.Context, db *gorm.DB) error {
+	v.mu.RLock()
+	loaded := v.loaded
+	v.mu.RUnlock()
+	if loaded {
+		return nil
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.loaded {
+		return nil
+	}
+	var titles, names, providers []string
+	if err := db.WithContext(ctx).Model(&domain.Content{}).
+		Where("canonical_id IS NULL").
+		Pluck("title", &titles).Error; err != nil {
+		return err
+	}
+	if err := db.WithContext(ctx).Model(&domain.Tag{}).Pluck("name", &names).Error; err != nil {
+		return err
+	}
+	if err := db.WithContext(ctx).Model(&domain.Content{}).Distinct().Pluck("provider", &providers).Error; err != nil {
+		return err
+	}
+	for _, title := range titles {
+		v.add(title, 1)
+	}
+	v.addNamesLocked(append(names, providers...))
+	v.loaded = true
+	return nil
+}
+
+// replace swaps the words of a canonical title for those of its new title. An empty title
+// stands for none, so new content passes no old title and linked duplicates no new one.
+// Changes before the vocabulary is loaded are skipped, as the load reads them.
+func (v *searchVocabulary) replace(oldTitle, newTitle string) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if !v.loaded {
+		return
+	}
+	v.add(oldTitle, -1)
+	v.add(newTitle, 1)
+}
+
+// addNames adds the words of tag names and providers not added yet
+func (v *searchVocabulary) addNames(names []string) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.loaded {
+		v.addNamesLocked(names)
+	}
+}
+
+func (v *searchVocabulary) addNamesLocked(names []string) {
+	for _, name := range names {
+		if !v.names[name] {
+			v.names[name] = true
+			v.add(name, 1)
+		}
+	}
+}
+
+func (v *searchVocabulary) add(title string, delta int) {
+	for _, word := range strings.Fields(domain.NormalizeTitle(title)) {
+		if count := v.words[word] + delta; count > 0 {
+			v.words[word] = count
+		} else {
+			delete(v.words, word)
+		}
+	}
+}
+
+// expand returns the words within edits of term other than the term itself, closest first
+// and then the most frequent, up to maxFuzzyExpansions
+func (v *searchVocabulary) expand(term string, edits int) []string {
+	fuzzySpec := domain.NewFuzzySpecification()
+	term = strings.ToLower(term)
+
+	type expansion struct {
+		word     string
+		distance int
+		count    int
+	}
+	var expansions []expansion
+	v.mu.RLock()
+	for word, count := range v.words {
+		if word != term && fuzzySpec.IsMatch(term, word, edits) {
+			expansions = append(expansions, expansion{word: word, distance: domain.Levenshtein(term, word), count: count})
+		}
+	}
+	v.mu.RUnlock()
+
+	sort.Slice(expansions, func(i, j int) bool {
+		if expansions[i].distance != expansions[j].distance {
+			return expansions[i].distance < expansions[j].distance
+		}
+		if expansions[i].count != expansions[j].count {
+			return expansions[i].count > expansions[j].count
+		}
+		return expansions[i].word < expansions[j].word
+	})
+	if len(expansions) > maxFuzzyExpansions {
+		expansions = expansions[:maxFuzzyExpansions]
+	}
+
+	words := make([]string, len(expansions))
+	for i, e := range expansions {
+		words[i] = e.word
+	}
+	return words
+}
@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"search-engine-go/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+type SynonymRepository struct {
+	db *gorm.DB
+}
+
+func NewSynonymRepository(db *gorm.DB) *SynonymRepository {
+	return &SynonymRepository{db: db}
+}
+
+// List returns every synonym set ordered by name
+func (r *SynonymRepository) List(ctx context.Context) ([]*domain.SynonymSet, error) {
+	var sets []*domain.SynonymSet
+	if err := r.db.WithContext(ctx).Order("name ASC").Find(&sets).Error; err != nil {
+		return nil, domain.NewDatabaseError("list_synonym_sets", err)
+	}
+	return sets, nil
+}
+
+func (r *SynonymRepository) GetByID(ctx context.Context, id int64) (*domain.SynonymSet, error) {
+	var set domain.SynonymSet
+	if err := r.db.WithContext(ctx).First(&set, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.NewNotFoundError("synonym set", id)
+		}
+		return nil, domain.NewDatabaseError("get_synonym_set", err)
+	}
+	return &set, nil
+}
+
+func (r *SynonymRepository) Create(ctx context.Context, set *domain.SynonymSet) error {
+	if err := r.checkNameAvailable(ctx, set); err != nil {
+		return err
+	}
+	if err := r.db.WithContext(ctx).Create(set).Error; err != nil {
+		return domain.NewDatabaseError("create_synonym_set", err)
+	}
+	return nil
+}
+
+// Update replaces the name and rules of an existing set
+func (r *SynonymRepository) Update(ctx context.Context, set *domain.SynonymSet) error {
+	existing, err := r.GetByID(ctx, set.ID)
+	if err != nil {
+		return err
+	}
+	if err := r.checkNameAvailable(ctx, set); err != nil {
+		return err
+	}
+
+	set.CreatedAt = existing.CreatedAt
+	if err := r.db.WithContext(ctx).Select("name", "rules", "updated_at").Updates(set).Error; err != nil {
+		return domain.NewDatabaseError("update_synonym_set", err)
+	}
+	return nil
+}
+
+func (r *SynonymRepository) Delete(ctx context.Context, id int64) error {
+	result := r.db.WithContext(ctx).Delete(&domain.SynonymSet{}, id)
+	if result.Error != nil {
+		return domain.NewDatabaseError("delete_synonym_set", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return domain.NewNotFoundError("synonym set", id)
+	}
+	return nil
+}
+
+// checkNameAvailable rejects a set whose name is taken by another set
+func (r *SynonymRepository) checkNameAvailable(ctx context.Context, set *domain.SynonymSet) error {
+	var count int64
+	if err := r.db.WithContext(ctx).Model(&domain.SynonymSet{}).
+		Where("name = ? AND id <> ?", set.Name, set.ID).
+		Count(&count).Error; err != nil {
+		return domain.NewDatabaseError("check_synonym_set_name", err)
+	}
+	if count > 0 {
+		return domain.NewInvalidInputError("name", "is already used by another synonym set")
+	}
+	return nil
+}
@@ -3,6 +3,9 @@ package service
 import (
 	"context"
 	"fmt"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"search-engine-go/internal/domain"
@@ -15,14 +18,45 @@ import (
 type ContentServiceInterface interface {
 	Search(ctx context.Context, req *domain.SearchRequest) (*domain.SearchResponse, error)
 	GetByID(ctx context.Context, id int64) (*domain.Content, error)
+	GetMetricsHistory(ctx context.Context, id int64, req *domain.MetricsHistoryRequest) (*domain.MetricsHistoryResponse, error)
+	GetTrending(ctx context.Context, req *domain.TrendingRequest) (*domain.SearchResponse, error)
+	GetRelated(ctx context.Context, id int64, req *domain.RelatedRequest) (*domain.SearchResponse, error)
+	SearchDocument(ctx context.Context, doc *domain.SearchDocument) (*domain.SearchResponse, error)
+	MultiSearch(ctx context.Context, req *domain.MultiSearchRequest) ([]*domain.MultiSearchResult, error)
+	ExportSearch(ctx context.Context, req *domain.ExportRequest, fn func([]*domain.Content) error) error
+	Suggest(ctx context.Context, req *domain.SuggestRequest) (*domain.SuggestResponse, error)
 }
 
 type ContentService struct {
 	repo        *repository.ContentRepository
 	providerSvc *ProviderService
 	scoringSvc  *ScoringService
+	dedupSvc    *DedupService
+	suggestSvc  *SuggestService
+	synonymSvc  *SynonymService
+	analyzer    *domain.TextAnalyzer
 	cache       cache.Cache
 	log         *zap.Logger
+
+	// fetches holds the provider fetches in flight, keyed by query and content type, so that
+	// concurrent searches share them. storeMu serializes storing what any of them fetched:
+	// BatchCreateOrUpdate looks items up before inserting them, so two stores of overlapping
+	// results would both insert the same provider item and one would fail on its unique
+	// index, and duplicate reconciliation links items against candidates other stores are
+	// still writing. Stores only follow cache misses and are shared between searches, so
+	// serializing them costs little next to the provider round trips.
+	fetchMu sync.Mutex
+	fetches map[string]*providerFetch
+	storeMu sync.Mutex
+
+	stopCh   chan struct{}
+	stopOnce sync.Once
+}
+
+// providerFetch is a fetch and store of provider content that concurrent searches wait on
+type providerFetch struct {
+	done chan struct{}
+	err  error
 }
 
 func NewContentService(
@@ -32,65 +66,417 @@ func NewContentService(
 	cache cache.Cache,
 	log *zap.Logger,
 ) *ContentService {
+	analyzer, _ := domain.NewTextAnalyzer(nil)
 	return &ContentService{
 		repo:        repo,
 		providerSvc: providerSvc,
 		scoringSvc:  scoringSvc,
+		dedupSvc:    NewDedupService(scoringSvc, log),
+		suggestSvc:  NewSuggestService(repo, log),
+		analyzer:    analyzer,
 		cache:       cache,
 		log:         log,
+		fetches:     make(map[string]*providerFetch),
+		stopCh:      make(chan struct{}),
 	}
 }
 
+// SetSynonymService makes searches expand their queries with synonyms unless expand=false
+func (s *ContentService) SetSynonymService(synonymSvc *SynonymService) {
+	s.synonymSvc = synonymSvc
+}
+
+// SetTextAnalyzer replaces the analyzer whose stopwords are removed from search queries
+func (s *ContentService) SetTextAnalyzer(analyzer *domain.TextAnalyzer) {
+	s.analyzer = analyzer
+}
+
 func (s *ContentService) Search(ctx context.Context, req *domain.SearchRequest) (*domain.SearchResponse, error) {
-	paginationSpec := domain.NewPaginationSpecification()
-	paginationSpec.NormalizePagination(req)
+	resp, err := s.search(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return s.withSuggestion(ctx, req, resp)
+}
 
-	if req.SortOrder != "asc" && req.SortOrder != "desc" {
-		req.SortOrder = "desc"
+func (s *ContentService) search(ctx context.Context, req *domain.SearchRequest) (*domain.SearchResponse, error) {
+	queryNode, err := s.normalizeSearch(req)
+	if err != nil {
+		return nil, err
 	}
 
 	cacheKey := s.generateCacheKey(req)
 
-	if cached, found := s.cache.Get(ctx, cacheKey); found {
+	if cached, total, found := s.getCachedPage(ctx, cacheKey); found {
 		s.log.Debug("Cache hit", zap.String("key", cacheKey))
-		total := len(cached)
 		totalPages := (total + req.PageSize - 1) / req.PageSize
 
-		paginatedCached := s.paginateCachedResults(cached, req.Page, req.PageSize)
+		s.recordQuery(req, total)
 
-		return &domain.SearchResponse{
-			Items:      paginatedCached,
+		resp, err := s.withNextCursor(ctx, req, &domain.SearchResponse{
+			Items:      cached,
 			Total:      total,
 			Page:       req.Page,
 			PageSize:   req.PageSize,
 			TotalPages: totalPages,
-		}, nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		return s.withAggregations(ctx, req, resp)
+	}
+
+	providerQuery := strings.TrimSpace(domain.QueryFreeText(queryNode) + " " + domain.QueryFreeText(req.Filter))
+	if err := s.refreshContent(ctx, providerQuery, req.ContentType); err != nil {
+		return nil, err
 	}
 
-	allContents, err := s.providerSvc.FetchFromAllProviders(ctx, req.Query, req.ContentType)
+	contents, total, err := s.repo.Search(ctx, req)
+	if err != nil {
+		return nil, domain.NewDatabaseError("search", err)
+	}
+
+	if err := s.setCachedPage(ctx, cacheKey, contents, total); err != nil {
+		s.log.Warn("Failed to cache results", zap.Error(err))
+	}
+	s.recordQuery(req, total)
+
+	totalPages := (total + req.PageSize - 1) / req.PageSize
+
+	resp, err := s.withNextCursor(ctx, req, &domain.SearchResponse{
+		Items:      contents,
+		Total:      total,
+		Page:       req.Page,
+		PageSize:   req.PageSize,
+		TotalPages: totalPages,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.withAggregations(ctx, req, resp)
+}
+
+// normalizeSearch validates and normalizes every search setting and returns the parsed query
+func (s *ContentService) normalizeSearch(req *domain.SearchRequest) (domain.QueryNode, error) {
+	paginationSpec := domain.NewPaginationSpecification()
+	paginationSpec.NormalizePagination(req)
+
+	if req.SortOrder != "asc" && req.SortOrder != "desc" {
+		req.SortOrder = "desc"
+	}
+
+	languageSpec := domain.NewLanguageSpecification()
+	if err := languageSpec.NormalizeLanguage(req); err != nil {
+		return nil, err
+	}
+
+	queryNode, err := domain.ParseQuery(req.Query)
+	if err != nil {
+		return nil, err
+	}
+	language := req.Language
+	if language == "" {
+		language = domain.DefaultLanguage
+	}
+	analyzed := s.analyzer.RemoveStopwords(queryNode, language)
+	if s.synonymSvc != nil && (req.Expand == nil || *req.Expand) {
+		analyzed = s.synonymSvc.Expand(analyzed)
+	}
+	req.AnalyzedQuery = nil
+	if analyzed != nil && analyzed.String() != queryNode.String() {
+		req.AnalyzedQuery = analyzed
+	}
+
+	tagFilterSpec := domain.NewTagFilterSpecification()
+	if err := tagFilterSpec.NormalizeTagFilter(req); err != nil {
+		return nil, err
+	}
+
+	durationFilterSpec := domain.NewDurationFilterSpecification()
+	if err := durationFilterSpec.ValidateDurationFilter(req); err != nil {
+		return nil, err
+	}
+
+	rangeFilterSpec := domain.NewRangeFilterSpecification()
+	if err := rangeFilterSpec.NormalizeRangeFilter(req); err != nil {
+		return nil, err
+	}
+
+	collapseSpec := domain.NewCollapseSpecification()
+	if err := collapseSpec.NormalizeCollapse(req); err != nil {
+		return nil, err
+	}
+
+	facetSpec := domain.NewFacetSpecification()
+	if err := facetSpec.NormalizeFacets(req); err != nil {
+		return nil, err
+	}
+
+	fuzzySpec := domain.NewFuzzySpecification()
+	if err := fuzzySpec.NormalizeFuzzy(req); err != nil {
+		return nil, err
+	}
+
+	boostSpec := domain.NewBoostSpecification()
+	if err := boostSpec.NormalizeBoost(req); err != nil {
+		return nil, err
+	}
+
+	highlightSpec := domain.NewHighlightSpecification()
+	if err := highlightSpec.ValidateHighlight(req); err != nil {
+		return nil, err
+	}
+
+	cursorSpec := domain.NewCursorSpecification()
+	if err := cursorSpec.NormalizeCursor(req); err != nil {
+		return nil, err
+	}
+
+	return queryNode, nil
+}
+
+// providerFetchTimeout bounds a shared provider fetch and store, which no single search can cancel
+const providerFetchTimeout = 30 * time.Second
+
+// refreshContent fetches content matching a query from the providers and stores it. A search
+// arriving while the same fetch is in flight waits for it instead of fetching again. Each
+// search waits on its own context, so one giving up does not fail the others.
+func (s *ContentService) refreshContent(ctx context.Context, query string, contentType *domain.ContentType) error {
+	key := query + "|all"
+	if contentType != nil {
+		key = query + "|" + string(*contentType)
+	}
+
+	s.fetchMu.Lock()
+	fetch, ok := s.fetches[key]
+	if !ok {
+		fetch = &providerFetch{done: make(chan struct{})}
+		s.fetches[key] = fetch
+		go s.runFetch(context.WithoutCancel(ctx), key, fetch, query, contentType)
+	}
+	s.fetchMu.Unlock()
+
+	select {
+	case <-fetch.done:
+		return fetch.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// runFetch runs a shared fetch on a context detached from the search that started it, bounded
+// by providerFetchTimeout, and releases every search waiting on it
+func (s *ContentService) runFetch(ctx context.Context, key string, fetch *providerFetch, query string, contentType *domain.ContentType) {
+	ctx, cancel := context.WithTimeout(ctx, providerFetchTimeout)
+	defer cancel()
+
+	fetch.err = s.fetchAndStore(ctx, query, contentType)
+
+	s.fetchMu.Lock()
+	delete(s.fetches, key)
+	s.fetchMu.Unlock()
+	close(fetch.done)
+}
+
+// fetchAndStore fetches content from every provider, scores it and stores it
+func (s *ContentService) fetchAndStore(ctx context.Context, query string, contentType *domain.ContentType) error {
+	allContents, err := s.providerSvc.FetchFromAllProviders(ctx, query, contentType)
 	if err != nil {
 		s.log.Warn("Failed to fetch from some providers", zap.Error(err))
 		if len(allContents) == 0 {
-			return nil, domain.NewProviderError("all", "all providers failed", err)
+			return domain.NewProviderError("all", "all providers failed", err)
 		}
 	}
 
 	for _, content := range allContents {
 		content.Score = s.scoringSvc.CalculateScore(content)
+		domain.ApplyFingerprint(content)
+		domain.ApplyLanguage(content)
 	}
 
-	if err := s.repo.BatchCreateOrUpdate(ctx, allContents); err != nil {
-		s.log.Error("Failed to save content to database", zap.Error(err))
-		return nil, domain.NewDatabaseError("batch_create_or_update", err)
+	s.storeMu.Lock()
+	defer s.storeMu.Unlock()
+
+	// duplicates are linked in the same transaction, so that stored content is never left
+	// unreconciled
+	err = s.repo.Transaction(ctx, func(repo *repository.ContentRepository) error {
+		if err := repo.BatchCreateOrUpdate(ctx, allContents); err != nil {
+			s.log.Error("Failed to save content to database", zap.Error(err))
+			return domain.NewDatabaseError("batch_create_or_update", err)
+		}
+		if err := s.dedupSvc.Reconcile(ctx, repo, allContents); err != nil {
+			s.log.Error("Failed to reconcile duplicate content", zap.Error(err))
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return err
 	}
+	s.suggestSvc.IndexContents(allContents)
+	return nil
+}
 
-	contents, total, err := s.repo.Search(ctx, req)
+// MultiSearch runs the searches of a multi-search concurrently and returns their results in
+// order. A failing search does not fail the others; its result carries the error instead.
+func (s *ContentService) MultiSearch(ctx context.Context, req *domain.MultiSearchRequest) ([]*domain.MultiSearchResult, error) {
+	multiSearchSpec := domain.NewMultiSearchSpecification()
+	if err := multiSearchSpec.ValidateMultiSearch(req); err != nil {
+		return nil, err
+	}
+
+	results := make([]*domain.MultiSearchResult, len(req.Searches))
+	var wg sync.WaitGroup
+	for i, search := range req.Searches {
+		wg.Add(1)
+		go func(i int, search *domain.SearchRequest) {
+			defer wg.Done()
+			if err := multiSearchSpec.NormalizeSearch(search); err != nil {
+				results[i] = &domain.MultiSearchResult{Err: err}
+				return
+			}
+			resp, err := s.Search(ctx, search)
+			results[i] = &domain.MultiSearchResult{Response: resp, Err: err}
+		}(i, search)
+	}
+	wg.Wait()
+	return results, nil
+}
+
+// ExportSearch passes every stored match of a search to fn in batches, in search order. Unlike
+// a search it neither fetches from the providers nor goes through the cache, and it ignores
+// the page and cursor. Invalid settings are reported before fn is first called.
+func (s *ContentService) ExportSearch(ctx context.Context, req *domain.ExportRequest, fn func([]*domain.Content) error) error {
+	exportSpec := domain.NewExportSpecification()
+	if err := exportSpec.NormalizeExport(req); err != nil {
+		return err
+	}
+	req.Page, req.Cursor = domain.DefaultPage, ""
+	if _, err := s.normalizeSearch(&req.SearchRequest); err != nil {
+		return err
+	}
+
+	err := s.repo.ExportSearch(ctx, &req.SearchRequest, domain.ExportBatchSize, fn)
+	if _, ok := err.(*domain.DomainError); err != nil && !ok {
+		return domain.NewDatabaseError("export_search", err)
+	}
+	return err
+}
+
+// withNextCursor attaches the cursor of the following page when more results exist
+func (s *ContentService) withNextCursor(ctx context.Context, req *domain.SearchRequest, resp *domain.SearchResponse) (*domain.SearchResponse, error) {
+	nextCursor, err := s.repo.NextCursor(ctx, req, resp.Items)
 	if err != nil {
 		return nil, domain.NewDatabaseError("search", err)
 	}
+	resp.NextCursor = nextCursor
+	return resp, nil
+}
 
-	if err := s.cache.Set(ctx, cacheKey, contents, 5*time.Minute); err != nil {
-		s.log.Warn("Failed to cache results", zap.Error(err))
+// withAggregations attaches the requested facets to a search response. Facets are always
+// computed from the database so they cover every match, not just the cached page.
+func (s *ContentService) withAggregations(ctx context.Context, req *domain.SearchRequest, resp *domain.SearchResponse) (*domain.SearchResponse, error) {
+	if len(req.Facets) == 0 {
+		return resp, nil
+	}
+
+	aggregations, err := s.repo.Aggregate(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Aggregations = aggregations
+	return resp, nil
+}
+
+// withSuggestion offers a spelling-corrected query when a search finds nothing and, with
+// auto_correct, returns the results of the corrected query instead
+func (s *ContentService) withSuggestion(ctx context.Context, req *domain.SearchRequest, resp *domain.SearchResponse) (*domain.SearchResponse, error) {
+	if resp.Total > 0 || strings.TrimSpace(req.Query) == "" {
+		return resp, nil
+	}
+
+	suggestion := s.suggestSvc.CorrectQuery(req.Query)
+	if suggestion == "" {
+		return resp, nil
+	}
+	resp.Suggestion = suggestion
+	if !req.AutoCorrect {
+		return resp, nil
+	}
+
+	corrected := *req
+	corrected.Query = suggestion
+	correctedResp, err := s.search(ctx, &corrected)
+	if err != nil {
+		return nil, err
+	}
+	correctedResp.Suggestion = suggestion
+	correctedResp.AutoCorrected = true
+	return correctedResp, nil
+}
+
+// recordQuery adds the first-page search of a query that found something to the query log
+func (s *ContentService) recordQuery(req *domain.SearchRequest, total int) {
+	if req.Query != "" && req.Page == 1 && req.Cursor == "" && total > 0 {
+		s.suggestSvc.RecordQuery(req.Query)
+	}
+}
+
+func (s *ContentService) Suggest(ctx context.Context, req *domain.SuggestRequest) (*domain.SuggestResponse, error) {
+	return s.suggestSvc.Suggest(ctx, req)
+}
+
+// WarmSuggestions fills the autocomplete index from the database
+func (s *ContentService) WarmSuggestions(ctx context.Context) error {
+	return s.suggestSvc.Warm(ctx)
+}
+
+// SearchDocument runs a structured JSON search by translating the document into a search request
+func (s *ContentService) SearchDocument(ctx context.Context, doc *domain.SearchDocument) (*domain.SearchResponse, error) {
+	documentSpec := domain.NewSearchDocumentSpecification()
+	req, err := documentSpec.ToSearchRequest(doc)
+	if err != nil {
+		return nil, err
+	}
+	return s.Search(ctx, req)
+}
+
+func (s *ContentService) GetByID(ctx context.Context, id int64) (*domain.Content, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+func (s *ContentService) GetMetricsHistory(ctx context.Context, id int64, req *domain.MetricsHistoryRequest) (*domain.MetricsHistoryResponse, error) {
+	windowSpec := domain.NewMetricsWindowSpecification()
+	if err := windowSpec.NormalizeWindow(req, time.Now().UTC()); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.repo.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+
+	points, err := s.repo.GetMetricsHistory(ctx, id, req.From, req.To)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.MetricsHistoryResponse{
+		ContentID: id,
+		From:      req.From,
+		To:        req.To,
+		Points:    points,
+	}, nil
+}
+
+func (s *ContentService) GetTrending(ctx context.Context, req *domain.TrendingRequest) (*domain.SearchResponse, error) {
+	windowSpec := domain.NewTrendingWindowSpecification()
+	if err := windowSpec.NormalizeTrendingRequest(req); err != nil {
+		return nil, err
+	}
+
+	contents, total, err := s.repo.FindTrending(ctx, req, time.Now().UTC())
+	if err != nil {
+		return nil, err
 	}
 
 	totalPages := (total + req.PageSize - 1) / req.PageSize
@@ -104,30 +490,92 @@ func (s *ContentService) Search(ctx context.Context, req *domain.SearchRequest)
 	}, nil
 }
 
-func (s *ContentService) GetByID(ctx context.Context, id int64) (*domain.Content, error) {
-	return s.repo.GetByID(ctx, id)
+// RefreshTrendingScores recomputes the stored trending scores that sort_by=trending orders by
+func (s *ContentService) RefreshTrendingScores(ctx context.Context) error {
+	return s.repo.RefreshTrendingScores(ctx, time.Now().UTC())
+}
+
+// WatchTrending refreshes the stored trending scores every interval until Shutdown is called,
+// so that items no longer being ingested lose their score as their growth leaves the window
+func (s *ContentService) WatchTrending(interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				if err := s.RefreshTrendingScores(context.Background()); err != nil {
+					s.log.Warn("Failed to refresh trending scores", zap.Error(err))
+				}
+			case <-s.stopCh:
+				s.log.Info("Trending refresh goroutine stopped")
+				return
+			}
+		}
+	}()
+}
+
+// WatchQueryLog writes the counted search queries to the query log every interval until
+// Shutdown is called
+func (s *ContentService) WatchQueryLog(interval time.Duration) {
+	s.suggestSvc.Watch(interval)
+}
+
+// Shutdown stops the background jobs and writes the search queries counted since the last flush
+func (s *ContentService) Shutdown() {
+	s.stopOnce.Do(func() {
+		close(s.stopCh)
+	})
+	if err := s.suggestSvc.Shutdown(context.Background()); err != nil {
+		s.log.Warn("Failed to flush search queries", zap.Error(err))
+	}
 }
 
-func (s *ContentService) paginateCachedResults(cached []*domain.Content, page, pageSize int) []*domain.Content {
-	start := (page - 1) * pageSize
-	end := start + pageSize
-	total := len(cached)
+func (s *ContentService) GetRelated(ctx context.Context, id int64, req *domain.RelatedRequest) (*domain.SearchResponse, error) {
+	relatedSpec := domain.NewRelatedSpecification()
+	relatedSpec.NormalizeRelatedRequest(req)
 
-	if s.isStartBeyondBounds(start, total) {
-		return []*domain.Content{}
+	source, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
 	}
-	if s.isEndBeyondBounds(end, total) {
-		return cached[start:]
+
+	contents, total, err := s.repo.FindRelated(ctx, source, req)
+	if err != nil {
+		return nil, err
 	}
-	return cached[start:end]
+
+	totalPages := (total + req.PageSize - 1) / req.PageSize
+
+	return &domain.SearchResponse{
+		Items:      contents,
+		Total:      total,
+		Page:       req.Page,
+		PageSize:   req.PageSize,
+		TotalPages: totalPages,
+	}, nil
 }
 
-func (s *ContentService) isStartBeyondBounds(start, total int) bool {
-	return start > total
+// getCachedPage returns the cached page of results of a search and how many results it has in
+// all. The page and its total are cached under separate keys, so either being gone is a miss.
+func (s *ContentService) getCachedPage(ctx context.Context, key string) ([]*domain.Content, int, bool) {
+	total, found := s.cache.GetTotal(ctx, key+":total")
+	if !found {
+		return nil, 0, false
+	}
+	page, found := s.cache.Get(ctx, key)
+	return page, total, found
 }
 
-func (s *ContentService) isEndBeyondBounds(end, total int) bool {
-	return end > total
+func (s *ContentService) setCachedPage(ctx context.Context, key string, page []*domain.Content, total int) error {
+	if err := s.cache.Set(ctx, key, page, 5*time.Minute); err != nil {
+		return err
+	}
+	return s.cache.SetTotal(ctx, key+":total", total, 5*time.Minute)
 }
 
 func (s *ContentService) generateCacheKey(req *domain.SearchRequest) string {
@@ -139,5 +587,78 @@ func (s *ContentService) generateCacheKey(req *domain.SearchRequest) string {
 	if sortOrder == "" {
 		sortOrder = "desc"
 	}
-	return fmt.Sprintf("search:%s:%s:%s:%s", req.Query, contentType, req.SortBy, sortOrder)
+	tags := "all"
+	if len(req.Tags) > 0 {
+		tags = fmt.Sprintf("%s(%s)", req.TagMode, strings.Join(req.Tags, ","))
+	}
+	duration := fmt.Sprintf("%s-%s", formatOptionalInt(req.MinDuration), formatOptionalInt(req.MaxDuration))
+	ranges := fmt.Sprintf("%s-%s,%s-%s,%s-%s,%s-%s",
+		formatOptionalTime(req.PublishedAfterTime), formatOptionalTime(req.PublishedBeforeTime),
+		formatOptionalInt(req.MinViews), formatOptionalInt(req.MaxViews),
+		formatOptionalInt(req.MinLikes), formatOptionalInt(req.MaxLikes),
+		formatOptionalFloat(req.MinScore), formatOptionalFloat(req.MaxScore))
+	collapse := "none"
+	if req.Collapse != "" {
+		collapse = req.Collapse
+	}
+	fuzzy := "off"
+	if req.Fuzzy != "" {
+		fuzzy = req.Fuzzy
+	}
+	highlight := "default"
+	if req.HighlightPreTag != "" || req.HighlightPostTag != "" {
+		highlight = req.HighlightPreTag + "|" + req.HighlightPostTag
+	}
+	// only the requested page is cached, so every page has its own key
+	page := fmt.Sprintf("%d/%d", req.Page, req.PageSize)
+	cursor := "first"
+	if req.Cursor != "" {
+		cursor = req.Cursor
+	}
+	filter := "none"
+	if req.Filter != nil {
+		filter = req.Filter.String()
+	}
+	language := "all"
+	if req.Language != "" {
+		language = req.Language
+	}
+	boost := "default"
+	if req.Boosts != nil {
+		boost = req.Boosts.String()
+	}
+	analyzed := "none"
+	if req.AnalyzedQuery != nil {
+		analyzed = req.AnalyzedQuery.String()
+	}
+	sortBy := req.SortBy
+	if len(req.Sort) > 0 {
+		fields := make([]string, 0, len(req.Sort))
+		for _, field := range req.Sort {
+			fields = append(fields, field.Field+" "+field.Order)
+		}
+		sortBy = strings.Join(fields, ",")
+	}
+	return fmt.Sprintf("search:%s:%s:%s:%s:%s:%s:%s:%s:%s:%s:%s:%s:%s:%s:%s:%s", req.Query, contentType, sortBy, sortOrder, tags, duration, ranges, collapse, fuzzy, boost, highlight, page, cursor, filter, language, analyzed)
+}
+
+func formatOptionalInt(value *int) string {
+	if value == nil {
+		return "*"
+	}
+	return strconv.Itoa(*value)
+}
+
+func formatOptionalFloat(value *float64) string {
+	if value == nil {
+		return "*"
+	}
+	return strconv.FormatFloat(*value, 'f', -1, 64)
+}
+
+func formatOptionalTime(value *time.Time) string {
+	if value == nil {
+		return "*"
+	}
+	return value.UTC().Format(time.RFC3339Nano)
 }
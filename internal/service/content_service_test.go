@@ -2,6 +2,8 @@ package service
 
 import (
 	"context"
+	"fmt"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -42,7 +44,7 @@ func setupTestDB(t *testing.T) *gorm.DB {
 	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
 	require.NoError(t, err)
 
-	err = db.AutoMigrate(&domain.Content{})
+	err = db.AutoMigrate(&domain.Content{}, &domain.Tag{}, &domain.ContentMetricsSnapshot{}, &domain.SearchQueryLog{}, &domain.SynonymSet{})
 	require.NoError(t, err)
 
 	return db
@@ -73,7 +75,7 @@ func TestContentService_Search(t *testing.T) {
 			SortBy:   "score",
 		}
 		cacheKey := service.generateCacheKey(req)
-		err := cacheClient.Set(context.Background(), cacheKey, cachedContent, 5*time.Minute)
+		err := service.setCachedPage(context.Background(), cacheKey, cachedContent, len(cachedContent))
 		require.NoError(t, err)
 
 		response, err := service.Search(context.Background(), req)
@@ -318,7 +320,48 @@ func TestContentService_GetByID(t *testing.T) {
 	})
 }
 
-func TestContentService_paginateCachedResults(t *testing.T) {
+func TestContentService_GetRelated(t *testing.T) {
+	db := setupTestDB(t)
+	repo := repository.NewContentRepository(db)
+	cacheClient := cache.NewInMemory()
+	defer cacheClient.Close()
+
+	logger, _ := zap.NewDevelopment()
+	registry := adapter.NewAdapterRegistry()
+	providerSvc := NewProviderService(registry, logger)
+	scoringService := NewScoringService()
+	service := NewContentService(repo, providerSvc, scoringService, cacheClient, logger)
+
+	contents := []*domain.Content{
+		{ProviderID: "provider1_1", Provider: "provider1", Title: "Go Concurrency", Type: domain.ContentTypeVideo, Score: 5},
+		{ProviderID: "provider1_2", Provider: "provider1", Title: "Go Generics", Type: domain.ContentTypeVideo, Score: 3},
+		{ProviderID: "provider1_3", Provider: "provider1", Title: "Rust Ownership", Type: domain.ContentTypeVideo, Score: 8},
+	}
+	require.NoError(t, repo.BatchCreateOrUpdate(context.Background(), contents))
+
+	t.Run("Applies pagination defaults", func(t *testing.T) {
+		resp, err := service.GetRelated(context.Background(), contents[0].ID, &domain.RelatedRequest{})
+
+		require.NoError(t, err)
+		assert.Equal(t, domain.DefaultPage, resp.Page)
+		assert.Equal(t, domain.DefaultPageSize, resp.PageSize)
+		assert.Equal(t, 1, resp.Total)
+		assert.Equal(t, 1, resp.TotalPages)
+		require.Len(t, resp.Items, 1)
+		assert.Equal(t, "Go Generics", resp.Items[0].Title)
+	})
+
+	t.Run("Unknown content", func(t *testing.T) {
+		resp, err := service.GetRelated(context.Background(), 99999, &domain.RelatedRequest{})
+
+		assert.Nil(t, resp)
+		domainErr, ok := err.(*domain.DomainError)
+		require.True(t, ok)
+		assert.Equal(t, domain.ErrorCodeNotFound, domainErr.Code)
+	})
+}
+
+func TestContentService_SearchCachesEachPage(t *testing.T) {
 	cacheClient := cache.NewInMemory()
 	defer cacheClient.Close()
 
@@ -329,42 +372,378 @@ func TestContentService_paginateCachedResults(t *testing.T) {
 	db := setupTestDB(t)
 	repo := repository.NewContentRepository(db)
 	service := NewContentService(repo, providerSvc, scoringService, cacheClient, logger)
+	ctx := context.Background()
 
-	t.Run("Paginate within bounds", func(t *testing.T) {
-		cached := []*domain.Content{
-			{ID: 1, Title: "Item 1"},
-			{ID: 2, Title: "Item 2"},
-			{ID: 3, Title: "Item 3"},
-		}
+	contents := make([]*domain.Content, 0, 3)
+	for i := 1; i <= 3; i++ {
+		contents = append(contents, &domain.Content{ProviderID: fmt.Sprintf("p_%d", i), Provider: "p", Title: fmt.Sprintf("Item %d", i), Type: domain.ContentTypeText, Score: float64(10 - i)})
+	}
+	require.NoError(t, repo.BatchCreateOrUpdate(ctx, contents))
+
+	search := func(t *testing.T, page int) *domain.SearchResponse {
+		resp, err := service.Search(ctx, &domain.SearchRequest{Query: "item", SortBy: "score", Page: page, PageSize: 2})
+		require.NoError(t, err)
+		return resp
+	}
+
+	for _, round := range []string{"Cache miss", "Cache hit"} {
+		t.Run(round, func(t *testing.T) {
+			first := search(t, 1)
+			second := search(t, 2)
+
+			assert.Equal(t, 3, first.Total)
+			assert.Equal(t, 2, first.TotalPages)
+			assert.Equal(t, []int64{contents[0].ID, contents[1].ID}, []int64{first.Items[0].ID, first.Items[1].ID})
+			assert.Equal(t, 3, second.Total)
+			require.Len(t, second.Items, 1)
+			assert.Equal(t, contents[2].ID, second.Items[0].ID)
+		})
+	}
+}
+
+func TestContentService_SearchQuerySyntax(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	db := setupTestDB(t)
+	repo := repository.NewContentRepository(db)
+	cacheClient := cache.NewInMemory()
+	defer cacheClient.Close()
+
+	registry := adapter.NewAdapterRegistry()
+	mockAdapter := &MockAdapter{name: "syntax-provider"}
+	registry.Register("syntax-provider", mockAdapter)
+	providerSvc := NewProviderService(registry, logger)
+	service := NewContentService(repo, providerSvc, NewScoringService(), cacheClient, logger)
 
-		result := service.paginateCachedResults(cached, 1, 2)
+	response, err := service.Search(context.Background(), &domain.SearchRequest{Query: `title:"go concurrency`})
 
-		assert.Len(t, result, 2)
-		assert.Equal(t, "Item 1", result[0].Title)
-		assert.Equal(t, "Item 2", result[1].Title)
+	assert.Nil(t, response)
+	require.Error(t, err)
+	domainErr, ok := err.(*domain.DomainError)
+	require.True(t, ok)
+	assert.Equal(t, domain.ErrorCodeInvalidInput, domainErr.Code)
+	assert.Contains(t, domainErr.Message, "unterminated phrase")
+}
+
+func TestContentService_SearchDocument(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	db := setupTestDB(t)
+	repo := repository.NewContentRepository(db)
+	cacheClient := cache.NewInMemory()
+	defer cacheClient.Close()
+
+	registry := adapter.NewAdapterRegistry()
+	registry.Register("document-provider", &MockAdapter{name: "document-provider", contents: []*domain.Content{
+		{ProviderID: "doc_1", Provider: "document-provider", Title: "Go Basics", Type: domain.ContentTypeVideo, Views: 100},
+		{ProviderID: "doc_2", Provider: "document-provider", Title: "Go Channels", Type: domain.ContentTypeVideo, Views: 3000},
+		{ProviderID: "doc_3", Provider: "document-provider", Title: "Go Generics", Type: domain.ContentTypeText, Views: 2000},
+	}})
+	providerSvc := NewProviderService(registry, logger)
+	service := NewContentService(repo, providerSvc, NewScoringService(), cacheClient, logger)
+
+	t.Run("Applies the bool query and sort", func(t *testing.T) {
+		minimum := 1
+		response, err := service.SearchDocument(context.Background(), &domain.SearchDocument{
+			Query: &domain.QueryClause{Bool: &domain.BoolClause{
+				Must:               []domain.QueryClause{{Match: map[string]string{"title": "go"}}},
+				Should:             []domain.QueryClause{{Range: map[string]domain.RangeBounds{"views": {Gte: float64(1000)}}}},
+				MinimumShouldMatch: &minimum,
+			}},
+			Sort: []map[string]string{{"views": "asc"}},
+		})
+
+		require.NoError(t, err)
+		require.Len(t, response.Items, 2)
+		assert.Equal(t, "Go Generics", response.Items[0].Title)
+		assert.Equal(t, "Go Channels", response.Items[1].Title)
 	})
 
-	t.Run("Paginate beyond bounds", func(t *testing.T) {
-		cached := []*domain.Content{
-			{ID: 1, Title: "Item 1"},
-			{ID: 2, Title: "Item 2"},
-		}
+	t.Run("Invalid clauses name their path", func(t *testing.T) {
+		_, err := service.SearchDocument(context.Background(), &domain.SearchDocument{
+			Query: &domain.QueryClause{Bool: &domain.BoolClause{
+				Filter: []domain.QueryClause{{Term: map[string]string{"title": "go"}}},
+			}},
+		})
+
+		require.Error(t, err)
+		domainErr, ok := err.(*domain.DomainError)
+		require.True(t, ok)
+		assert.Equal(t, "query.bool.filter[0].term", domainErr.Details["field"])
+	})
+}
 
-		result := service.paginateCachedResults(cached, 2, 2)
+func TestContentService_SearchSpellingSuggestion(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	ctx := context.Background()
+	db := setupTestDB(t)
+	repo := repository.NewContentRepository(db)
+	cacheClient := cache.NewInMemory()
+	defer cacheClient.Close()
+
+	registry := adapter.NewAdapterRegistry()
+	registry.Register("spelling-provider", &MockAdapter{name: "spelling-provider", contents: []*domain.Content{
+		{ProviderID: "sp_1", Provider: "spelling-provider", Title: "Kubernetes Operators", Type: domain.ContentTypeVideo},
+		{ProviderID: "sp_2", Provider: "spelling-provider", Title: "Kubernetes Networking", Type: domain.ContentTypeText},
+	}})
+	service := NewContentService(repo, NewProviderService(registry, logger), NewScoringService(), cacheClient, logger)
 
-		assert.Len(t, result, 0)
+	t.Run("Zero hits offer a suggestion", func(t *testing.T) {
+		response, err := service.Search(ctx, &domain.SearchRequest{Query: "kubernets"})
+
+		require.NoError(t, err)
+		assert.Equal(t, 0, response.Total)
+		assert.Equal(t, "kubernetes", response.Suggestion)
+		assert.False(t, response.AutoCorrected)
 	})
 
-	t.Run("Paginate partial page", func(t *testing.T) {
-		cached := []*domain.Content{
-			{ID: 1, Title: "Item 1"},
-			{ID: 2, Title: "Item 2"},
-			{ID: 3, Title: "Item 3"},
-		}
+	t.Run("Auto correct runs the corrected query", func(t *testing.T) {
+		response, err := service.Search(ctx, &domain.SearchRequest{Query: "kubernets operatrs", AutoCorrect: true})
+
+		require.NoError(t, err)
+		assert.Equal(t, "kubernetes operators", response.Suggestion)
+		assert.True(t, response.AutoCorrected)
+		require.Equal(t, 1, response.Total)
+		assert.Equal(t, "Kubernetes Operators", response.Items[0].Title)
+	})
 
-		result := service.paginateCachedResults(cached, 2, 2)
+	t.Run("Hits are not corrected", func(t *testing.T) {
+		response, err := service.Search(ctx, &domain.SearchRequest{Query: "kubernetes", AutoCorrect: true})
 
-		assert.Len(t, result, 1)
-		assert.Equal(t, "Item 3", result[0].Title)
+		require.NoError(t, err)
+		assert.Equal(t, 2, response.Total)
+		assert.Empty(t, response.Suggestion)
+		assert.False(t, response.AutoCorrected)
 	})
 }
+
+func TestContentService_SearchCursor(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	ctx := context.Background()
+	db := setupTestDB(t)
+	repo := repository.NewContentRepository(db)
+	cacheClient := cache.NewInMemory()
+	defer cacheClient.Close()
+
+	registry := adapter.NewAdapterRegistry()
+	registry.Register("cursor-provider", &MockAdapter{name: "cursor-provider", contents: []*domain.Content{
+		{ProviderID: "cp_1", Provider: "cursor-provider", Title: "Terraform Basics", Type: domain.ContentTypeVideo, Views: 100},
+		{ProviderID: "cp_2", Provider: "cursor-provider", Title: "Terraform Modules", Type: domain.ContentTypeVideo, Views: 3000},
+		{ProviderID: "cp_3", Provider: "cursor-provider", Title: "Terraform State", Type: domain.ContentTypeVideo, Views: 2000},
+	}})
+	service := NewContentService(repo, NewProviderService(registry, logger), NewScoringService(), cacheClient, logger)
+
+	t.Run("Next cursor walks every result", func(t *testing.T) {
+		first, err := service.Search(ctx, &domain.SearchRequest{Query: "terraform", PageSize: 2, SortBy: "popularity"})
+		require.NoError(t, err)
+		require.Len(t, first.Items, 2)
+		require.NotEmpty(t, first.NextCursor)
+
+		second, err := service.Search(ctx, &domain.SearchRequest{Query: "terraform", PageSize: 2, SortBy: "popularity", Cursor: first.NextCursor})
+		require.NoError(t, err)
+
+		require.Len(t, second.Items, 1)
+		assert.Equal(t, "Terraform Basics", second.Items[0].Title)
+		assert.Equal(t, 3, second.Total)
+		assert.Empty(t, second.NextCursor)
+	})
+
+	t.Run("Cursor must match the sort", func(t *testing.T) {
+		first, err := service.Search(ctx, &domain.SearchRequest{Query: "terraform", PageSize: 2})
+		require.NoError(t, err)
+
+		_, err = service.Search(ctx, &domain.SearchRequest{Query: "terraform", PageSize: 2, SortBy: "created_at", Cursor: first.NextCursor})
+
+		require.Error(t, err)
+		domainErr, ok := err.(*domain.DomainError)
+		require.True(t, ok)
+		assert.Equal(t, domain.ErrorCodeInvalidInput, domainErr.Code)
+		assert.Equal(t, "cursor", domainErr.Details["field"])
+	})
+}
+
+func TestContentService_SearchRanges(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	ctx := context.Background()
+	db := setupTestDB(t)
+	repo := repository.NewContentRepository(db)
+	cacheClient := cache.NewInMemory()
+	defer cacheClient.Close()
+
+	registry := adapter.NewAdapterRegistry()
+	registry.Register("range-provider", &MockAdapter{name: "range-provider", contents: []*domain.Content{
+		{ProviderID: "rp_1", Provider: "range-provider", Title: "Ansible Basics", Type: domain.ContentTypeVideo, Views: 100},
+		{ProviderID: "rp_2", Provider: "range-provider", Title: "Ansible Roles", Type: domain.ContentTypeVideo, Views: 3000},
+	}})
+	service := NewContentService(repo, NewProviderService(registry, logger), NewScoringService(), cacheClient, logger)
+	intPtr := func(v int) *int { return &v }
+
+	t.Run("Range filters are part of the cache key", func(t *testing.T) {
+		all, err := service.Search(ctx, &domain.SearchRequest{Query: "ansible"})
+		require.NoError(t, err)
+		popular, err := service.Search(ctx, &domain.SearchRequest{Query: "ansible", MinViews: intPtr(1000)})
+		require.NoError(t, err)
+
+		assert.Equal(t, 2, all.Total)
+		require.Equal(t, 1, popular.Total)
+		assert.Equal(t, "Ansible Roles", popular.Items[0].Title)
+	})
+
+	t.Run("Invalid bounds are rejected", func(t *testing.T) {
+		_, err := service.Search(ctx, &domain.SearchRequest{Query: "ansible", PublishedAfter: "last week"})
+
+		require.Error(t, err)
+		domainErr, ok := err.(*domain.DomainError)
+		require.True(t, ok)
+		assert.Equal(t, "published_after", domainErr.Details["field"])
+	})
+}
+
+// countingAdapter counts fetches and holds each one open for a while, so that concurrent
+// searches overlap
+type countingAdapter struct {
+	MockAdapter
+	fetches atomic.Int32
+}
+
+func (a *countingAdapter) FetchContent(ctx context.Context, query string, contentType *domain.ContentType) ([]*domain.Content, error) {
+	a.fetches.Add(1)
+	select {
+	case <-time.After(100 * time.Millisecond):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	return a.MockAdapter.FetchContent(ctx, query, contentType)
+}
+
+func TestContentService_MultiSearch(t *testing.T) {
+	ctx := context.Background()
+	logger, _ := zap.NewDevelopment()
+	db := setupTestDB(t)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	// every connection to an in-memory database opens a new, empty one
+	sqlDB.SetMaxOpenConns(1)
+	repo := repository.NewContentRepository(db)
+	cacheClient := cache.NewInMemory()
+	defer cacheClient.Close()
+
+	provider := &countingAdapter{MockAdapter: MockAdapter{
+		name: "msearch-provider",
+		contents: []*domain.Content{
+			{ProviderID: "msearch_1", Provider: "msearch-provider", Title: "Go Concurrency", Type: domain.ContentTypeVideo, Views: 100},
+			{ProviderID: "msearch_2", Provider: "msearch-provider", Title: "Go Generics", Type: domain.ContentTypeText, ReadingTime: 5},
+		},
+	}}
+	registry := adapter.NewAdapterRegistry()
+	registry.Register("msearch-provider", provider)
+	service := NewContentService(repo, NewProviderService(registry, logger), NewScoringService(), cacheClient, logger)
+
+	video := domain.ContentTypeVideo
+	invalid := domain.ContentType("podcast")
+	results, err := service.MultiSearch(ctx, &domain.MultiSearchRequest{Searches: []*domain.SearchRequest{
+		{Query: "go", ContentType: &video, SortBy: "score"},
+		{Query: "go", ContentType: &video, SortBy: "created_at"},
+		{Query: "go", ContentType: &invalid},
+	}})
+
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+	for _, result := range results[:2] {
+		require.NoError(t, result.Err)
+		require.Len(t, result.Response.Items, 1)
+		assert.Equal(t, "Go Concurrency", result.Response.Items[0].Title)
+	}
+	assert.Error(t, results[2].Err)
+	assert.Equal(t, int32(1), provider.fetches.Load())
+
+	_, err = service.MultiSearch(ctx, &domain.MultiSearchRequest{})
+	assert.Error(t, err)
+}
+
+func TestContentService_ExportSearch(t *testing.T) {
+	ctx := context.Background()
+	logger, _ := zap.NewDevelopment()
+	db := setupTestDB(t)
+	repo := repository.NewContentRepository(db)
+	cacheClient := cache.NewInMemory()
+	defer cacheClient.Close()
+
+	provider := &countingAdapter{MockAdapter: MockAdapter{name: "export-provider"}}
+	registry := adapter.NewAdapterRegistry()
+	registry.Register("export-provider", provider)
+	service := NewContentService(repo, NewProviderService(registry, logger), NewScoringService(), cacheClient, logger)
+
+	require.NoError(t, repo.BatchCreateOrUpdate(ctx, []*domain.Content{
+		{ProviderID: "export_1", Provider: "export-provider", Title: "Go Concurrency", Type: domain.ContentTypeVideo, Score: 2},
+		{ProviderID: "export_2", Provider: "export-provider", Title: "Go Generics", Type: domain.ContentTypeText, Score: 5},
+		{ProviderID: "export_3", Provider: "export-provider", Title: "Rust Ownership", Type: domain.ContentTypeText, Score: 9},
+	}))
+
+	t.Run("Exports stored matches without fetching", func(t *testing.T) {
+		var titles []string
+		req := &domain.ExportRequest{SearchRequest: domain.SearchRequest{Query: "go", SortBy: "score", Page: 4, Cursor: "stale"}}
+		err := service.ExportSearch(ctx, req, func(batch []*domain.Content) error {
+			for _, content := range batch {
+				titles = append(titles, content.Title)
+			}
+			return nil
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Go Generics", "Go Concurrency"}, titles)
+		assert.Equal(t, domain.ExportFormatCSV, req.Format)
+		assert.Equal(t, int32(0), provider.fetches.Load())
+	})
+
+	t.Run("Invalid search is rejected before export", func(t *testing.T) {
+		called := false
+		err := service.ExportSearch(ctx, &domain.ExportRequest{SearchRequest: domain.SearchRequest{Language: "klingon"}}, func([]*domain.Content) error {
+			called = true
+			return nil
+		})
+
+		require.Error(t, err)
+		assert.False(t, called)
+	})
+}
+
+func TestContentService_SharedFetchOutlivesCanceledSearch(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	db := setupTestDB(t)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+	repo := repository.NewContentRepository(db)
+	cacheClient := cache.NewInMemory()
+	defer cacheClient.Close()
+
+	provider := &countingAdapter{MockAdapter: MockAdapter{
+		name:     "shared-provider",
+		contents: []*domain.Content{{ProviderID: "shared_1", Provider: "shared-provider", Title: "Go Concurrency", Type: domain.ContentTypeVideo}},
+	}}
+	registry := adapter.NewAdapterRegistry()
+	registry.Register("shared-provider", provider)
+	service := NewContentService(repo, NewProviderService(registry, logger), NewScoringService(), cacheClient, logger)
+
+	canceledCtx, cancel := context.WithCancel(context.Background())
+	canceled := make(chan error, 1)
+	go func() {
+		_, err := service.Search(canceledCtx, &domain.SearchRequest{Query: "go"})
+		canceled <- err
+	}()
+	require.Eventually(t, func() bool { return provider.fetches.Load() == 1 }, time.Second, time.Millisecond)
+
+	waiting := make(chan *domain.SearchResponse, 1)
+	go func() {
+		resp, err := service.Search(context.Background(), &domain.SearchRequest{Query: "go", SortBy: "created_at"})
+		assert.NoError(t, err)
+		waiting <- resp
+	}()
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+
+	assert.ErrorIs(t, <-canceled, context.Canceled)
+	resp := <-waiting
+	require.NotNil(t, resp)
+	assert.Equal(t, 1, resp.Total)
+	assert.Equal(t, int32(1), provider.fetches.Load())
+}
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+
+	"search-engine-go/internal/domain"
+	"search-engine-go/internal/repository"
+
+	"go.uber.org/zap"
+)
+
+// DedupService links cross-provider copies of the same content to a canonical record
+// and rescores canonical records on the merged metrics of their cluster
+type DedupService struct {
+	scoringSvc    *ScoringService
+	specification *domain.DuplicateContentSpecification
+	log           *zap.Logger
+}
+
+func NewDedupService(scoringSvc *ScoringService, log *zap.Logger) *DedupService {
+	return &DedupService{
+		scoringSvc:    scoringSvc,
+		specification: domain.NewDuplicateContentSpecification(),
+		log:           log,
+	}
+}
+
+// Reconcile runs in the ingest transaction, with repo bound to it. Every persisted duplicate
+// that no longer matches its canonical record is unlinked, every content item that is then
+// canonical is matched against older canonical records from other providers, and each touched
+// cluster is rescored.
+func (s *DedupService) Reconcile(ctx context.Context, repo *repository.ContentRepository, contents []*domain.Content) error {
+	// clusters maps each touched cluster onto whether it must be rescored even without
+	// duplicates, because it lost one
+	clusters := make(map[int64]bool)
+
+	for _, content := range contents {
+		if !content.IsCanonical() {
+			canonicalID := *content.CanonicalID
+			diverged, err := s.hasDiverged(ctx, repo, content)
+			if err != nil {
+				return err
+			}
+			if diverged {
+				if err := repo.UnlinkDuplicate(ctx, content); err != nil {
+					return err
+				}
+				clusters[canonicalID] = true
+				s.log.Debug("Unlinked diverged duplicate content",
+					zap.Int64("content_id", content.ID),
+					zap.Int64("canonical_id", canonicalID))
+			}
+		}
+		if content.IsCanonical() {
+			canonical, err := s.findCanonical(ctx, repo, content)
+			if err != nil {
+				return err
+			}
+			if canonical != nil {
+				if err := repo.LinkDuplicate(ctx, content.ID, canonical.ID); err != nil {
+					return err
+				}
+				content.CanonicalID = &canonical.ID
+				s.log.Debug("Linked duplicate content",
+					zap.Int64("content_id", content.ID),
+					zap.Int64("canonical_id", canonical.ID))
+			}
+		}
+		if _, ok := clusters[content.ClusterID()]; !ok {
+			clusters[content.ClusterID()] = false
+		}
+	}
+
+	for canonicalID, lostDuplicate := range clusters {
+		if err := s.rescoreCluster(ctx, repo, canonicalID, lostDuplicate); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// hasDiverged reports whether a duplicate no longer matches its canonical record
+func (s *DedupService) hasDiverged(ctx context.Context, repo *repository.ContentRepository, content *domain.Content) (bool, error) {
+	cluster, err := repo.GetCluster(ctx, *content.CanonicalID)
+	if err != nil {
+		return false, err
+	}
+	if len(cluster) == 0 || !cluster[0].IsCanonical() {
+		return true, nil
+	}
+	return !s.specification.IsDuplicate(cluster[0], content), nil
+}
+
+func (s *DedupService) findCanonical(ctx context.Context, repo *repository.ContentRepository, content *domain.Content) (*domain.Content, error) {
+	candidates, err := repo.FindDuplicateCandidates(ctx, content)
+	if err != nil {
+		return nil, err
+	}
+	for _, candidate := range candidates {
+		if s.specification.IsDuplicate(candidate, content) {
+			return candidate, nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *DedupService) rescoreCluster(ctx context.Context, repo *repository.ContentRepository, canonicalID int64, force bool) error {
+	cluster, err := repo.GetCluster(ctx, canonicalID)
+	if err != nil {
+		return err
+	}
+	if len(cluster) == 0 || !force && !s.hasDuplicates(cluster) {
+		return nil
+	}
+	merged := domain.MergeContentMetrics(cluster)
+	return repo.UpdateScore(ctx, canonicalID, s.scoringSvc.CalculateScore(merged))
+}
+
+func (s *DedupService) hasDuplicates(cluster []*domain.Content) bool {
+	return len(cluster) > 1
+}
@@ -0,0 +1,140 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"search-engine-go/internal/domain"
+	"search-engine-go/internal/infrastructure/cache"
+	"search-engine-go/internal/repository"
+	"search-engine-go/pkg/adapter"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestDedupService_Reconcile(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	scoringService := NewScoringServiceWithTime(time.Now())
+	ctx := context.Background()
+
+	t.Run("Links duplicates and scores canonical on merged metrics", func(t *testing.T) {
+		db := setupTestDB(t)
+		repo := repository.NewContentRepository(db)
+		service := NewDedupService(scoringService, logger)
+
+		now := time.Now()
+		first := &domain.Content{ProviderID: "p1_1", Provider: "provider1", Title: "Scaling Postgres", Type: domain.ContentTypeVideo, Views: 10000, Likes: 100, CreatedAt: now}
+		second := &domain.Content{ProviderID: "p2_1", Provider: "provider2", Title: "Scaling PostgreSQL", Type: domain.ContentTypeVideo, Views: 5000, Likes: 50, CreatedAt: now}
+		third := &domain.Content{ProviderID: "p3_1", Provider: "provider3", Title: "scaling postgres!", Type: domain.ContentTypeVideo, Views: 5000, Likes: 50, CreatedAt: now}
+		contents := []*domain.Content{first, second, third}
+		for _, content := range contents {
+			content.Score = scoringService.CalculateScore(content)
+			domain.ApplyFingerprint(content)
+		}
+		require.NoError(t, repo.BatchCreateOrUpdate(ctx, contents))
+
+		require.NoError(t, service.Reconcile(ctx, repo, contents))
+
+		assert.True(t, first.IsCanonical())
+		assert.True(t, second.IsCanonical())
+		require.NotNil(t, third.CanonicalID)
+		assert.Equal(t, first.ID, *third.CanonicalID)
+
+		stored, err := repo.GetByID(ctx, first.ID)
+		require.NoError(t, err)
+		merged := domain.MergeContentMetrics([]*domain.Content{first, third})
+		assert.InDelta(t, scoringService.CalculateScore(merged), stored.Score, 0.001)
+		assert.Greater(t, stored.Score, first.Score)
+		assert.Len(t, stored.Sources, 2)
+	})
+
+	t.Run("Unlinks duplicates that diverge from their canonical", func(t *testing.T) {
+		db := setupTestDB(t)
+		repo := repository.NewContentRepository(db)
+		service := NewDedupService(scoringService, logger)
+
+		now := time.Now()
+		ingest := func(contents ...*domain.Content) {
+			for _, content := range contents {
+				content.Score = scoringService.CalculateScore(content)
+				domain.ApplyFingerprint(content)
+			}
+			require.NoError(t, repo.Transaction(ctx, func(repo *repository.ContentRepository) error {
+				if err := repo.BatchCreateOrUpdate(ctx, contents); err != nil {
+					return err
+				}
+				return service.Reconcile(ctx, repo, contents)
+			}))
+		}
+		first := &domain.Content{ProviderID: "p1_1", Provider: "provider1", Title: "Scaling Postgres", Type: domain.ContentTypeVideo, Views: 10000, Likes: 100, CreatedAt: now}
+		second := &domain.Content{ProviderID: "p2_1", Provider: "provider2", Title: "scaling postgres!", Type: domain.ContentTypeVideo, Views: 5000, Likes: 50, CreatedAt: now}
+		ingest(first, second)
+		require.NotNil(t, second.CanonicalID)
+
+		renamed := &domain.Content{ProviderID: "p2_1", Provider: "provider2", Title: "Kubernetes Operators Explained", Type: domain.ContentTypeVideo, Views: 5000, Likes: 50, CreatedAt: now}
+		ingest(renamed)
+
+		assert.True(t, renamed.IsCanonical())
+		cluster, err := repo.GetCluster(ctx, first.ID)
+		require.NoError(t, err)
+		require.Len(t, cluster, 1)
+		assert.InDelta(t, scoringService.CalculateScore(first), cluster[0].Score, 0.001)
+		stored, err := repo.GetByID(ctx, second.ID)
+		require.NoError(t, err)
+		assert.True(t, stored.IsCanonical())
+	})
+
+	t.Run("A failed reconcile leaves nothing stored", func(t *testing.T) {
+		db := setupTestDB(t)
+		repo := repository.NewContentRepository(db)
+
+		content := &domain.Content{ProviderID: "p1_1", Provider: "provider1", Title: "Scaling Postgres", Type: domain.ContentTypeVideo}
+		domain.ApplyFingerprint(content)
+		err := repo.Transaction(ctx, func(repo *repository.ContentRepository) error {
+			if err := repo.BatchCreateOrUpdate(ctx, []*domain.Content{content}); err != nil {
+				return err
+			}
+			return errors.New("reconcile failed")
+		})
+
+		require.Error(t, err)
+		var count int64
+		require.NoError(t, db.Model(&domain.Content{}).Count(&count).Error)
+		assert.Zero(t, count)
+	})
+
+	t.Run("Search returns one hit per duplicate cluster", func(t *testing.T) {
+		db := setupTestDB(t)
+		repo := repository.NewContentRepository(db)
+		cacheClient := cache.NewInMemory()
+		defer cacheClient.Close()
+
+		registry := adapter.NewAdapterRegistry()
+		registry.Register("provider1", &MockAdapter{
+			name: "provider1",
+			contents: []*domain.Content{
+				{ProviderID: "p1_1", Provider: "provider1", Title: "The Go Memory Model", Type: domain.ContentTypeVideo, Views: 2000, Likes: 20, CreatedAt: time.Now()},
+			},
+		})
+		registry.Register("provider2", &MockAdapter{
+			name: "provider2",
+			contents: []*domain.Content{
+				{ProviderID: "p2_1", Provider: "provider2", Title: "Go Memory Model", Type: domain.ContentTypeVideo, Views: 1000, Likes: 10, CreatedAt: time.Now()},
+			},
+		})
+
+		providerSvc := NewProviderService(registry, logger)
+		service := NewContentService(repo, providerSvc, scoringService, cacheClient, logger)
+
+		response, err := service.Search(ctx, &domain.SearchRequest{Query: "Memory", Page: 1, PageSize: 20})
+
+		require.NoError(t, err)
+		assert.Equal(t, 1, response.Total)
+		require.Len(t, response.Items, 1)
+		assert.Len(t, response.Items[0].Sources, 2)
+	})
+}
@@ -16,8 +16,12 @@ type JWTService struct {
 	log        *zap.Logger
 }
 
+// RoleAdmin is the role that may change search settings such as synonyms
+const RoleAdmin = "admin"
+
 type Claims struct {
 	Username string `json:"username"`
+	Role     string `json:"role,omitempty"`
 	jwt.RegisteredClaims
 }
 
@@ -29,10 +33,11 @@ func NewJWTService(cfg config.AuthConfig, log *zap.Logger) *JWTService {
 	}
 }
 
-func (s *JWTService) GenerateToken(username string) (string, error) {
+func (s *JWTService) GenerateToken(username, role string) (string, error) {
 	expirationTime := time.Now().Add(s.expiration)
 	claims := &Claims{
 		Username: username,
+		Role:     role,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(expirationTime),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
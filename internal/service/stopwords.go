@@ -0,0 +1,36 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+
+	"search-engine-go/internal/domain"
+)
+
+// LoadTextAnalyzer creates the analyzer for search queries with the custom stopwords in file, a
+// JSON object of word arrays keyed by language code. Only the built-in stopwords are used when
+// file is empty or does not exist. The custom stopwords only apply to queries: the search
+// index and PostgreSQL's search vectors keep analyzing content with their own lists.
+func LoadTextAnalyzer(file string) (*domain.TextAnalyzer, error) {
+	if file == "" {
+		return domain.NewTextAnalyzer(nil)
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return domain.NewTextAnalyzer(nil)
+		}
+		return nil, domain.NewInternalError("failed to read stopwords file", err)
+	}
+
+	var custom map[string][]string
+	if err := json.Unmarshal(data, &custom); err != nil {
+		return nil, domain.NewInternalError("failed to parse stopwords file", err)
+	}
+	analyzer, err := domain.NewTextAnalyzer(custom)
+	if err != nil {
+		return nil, domain.NewInternalError("invalid stopwords file", err)
+	}
+	return analyzer, nil
+}
@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"search-engine-go/internal/domain"
+	"search-engine-go/internal/infrastructure/cache"
+	"search-engine-go/internal/repository"
+	"search-engine-go/pkg/adapter"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestLoadTextAnalyzer(t *testing.T) {
+	dir := t.TempDir()
+
+	t.Run("Missing file uses built-in stopwords", func(t *testing.T) {
+		analyzer, err := LoadTextAnalyzer(filepath.Join(dir, "missing.json"))
+
+		require.NoError(t, err)
+		assert.True(t, analyzer.IsStopword("the", domain.LanguageEnglish))
+		assert.False(t, analyzer.IsStopword("bitte", domain.LanguageGerman))
+	})
+
+	t.Run("Custom stopwords are added", func(t *testing.T) {
+		file := filepath.Join(dir, "stopwords.json")
+		require.NoError(t, os.WriteFile(file, []byte(`{"de": ["Bitte"], "tr-TR": ["şey"]}`), 0o644))
+
+		analyzer, err := LoadTextAnalyzer(file)
+
+		require.NoError(t, err)
+		assert.True(t, analyzer.IsStopword("bitte", domain.LanguageGerman))
+		assert.True(t, analyzer.IsStopword("şey", domain.LanguageTurkish))
+		assert.True(t, analyzer.IsStopword("der", domain.LanguageGerman))
+	})
+
+	t.Run("Unsupported language fails", func(t *testing.T) {
+		file := filepath.Join(dir, "unsupported.json")
+		require.NoError(t, os.WriteFile(file, []byte(`{"fr": ["le"]}`), 0o644))
+
+		_, err := LoadTextAnalyzer(file)
+
+		assert.Error(t, err)
+	})
+}
+
+func TestContentService_SearchLanguage(t *testing.T) {
+	ctx := context.Background()
+	logger, _ := zap.NewDevelopment()
+	db := setupTestDB(t)
+	repo := repository.NewContentRepository(db)
+	cacheClient := cache.NewInMemory()
+	defer cacheClient.Close()
+
+	registry := adapter.NewAdapterRegistry()
+	registry.Register("language-provider", &MockAdapter{
+		name: "language-provider",
+		contents: []*domain.Content{
+			{ProviderID: "language_1", Provider: "language-provider", Title: "Einführung in die Kubernetes Welt", Type: domain.ContentTypeVideo},
+			{ProviderID: "language_2", Provider: "language-provider", Title: "Kubernetes in Production", Type: domain.ContentTypeVideo, Language: "en-US"},
+		},
+	})
+	service := NewContentService(repo, NewProviderService(registry, logger), NewScoringService(), cacheClient, logger)
+
+	resp, err := service.Search(ctx, &domain.SearchRequest{Query: "kubernetes", Language: "DE"})
+	require.NoError(t, err)
+	require.Len(t, resp.Items, 1)
+	assert.Equal(t, "Einführung in die Kubernetes Welt", resp.Items[0].Title)
+	assert.Equal(t, domain.LanguageGerman, resp.Items[0].Language)
+
+	resp, err = service.Search(ctx, &domain.SearchRequest{Query: "the kubernetes", Language: "en"})
+	require.NoError(t, err)
+	require.Len(t, resp.Items, 1)
+	assert.Equal(t, domain.LanguageEnglish, resp.Items[0].Language)
+
+	_, err = service.Search(ctx, &domain.SearchRequest{Query: "kubernetes", Language: "fr"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,462 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"search-engine-go/internal/domain"
+	"search-engine-go/internal/repository"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// suggestLatencyTarget is the lookup time above which a suggest request is logged as slow
+	suggestLatencyTarget = 20 * time.Millisecond
+
+	// maxIndexedQueries caps how many logged queries the index holds, and the query log keeps.
+	// The most searched ones are loaded at startup.
+	maxIndexedQueries = 10000
+)
+
+// SuggestService answers autocomplete requests from two in-memory prefix indexes, one over
+// content titles fed on ingest and one over the query log fed by searches, and corrects the
+// spelling of queries against the words of titles and tags. All of them are warmed from the
+// database at startup, so lookups never hit the database. Searched queries are counted in
+// memory and written to the query log by Flush.
+type SuggestService struct {
+	repo     *repository.ContentRepository
+	titles   *prefixIndex
+	queries  *prefixIndex
+	spelling *spellingIndex
+	log      *zap.Logger
+
+	// pending counts the searches of each query since the last flush
+	pendingMu sync.Mutex
+	pending   map[string]int
+
+	stopCh   chan struct{}
+	stopOnce sync.Once
+}
+
+func NewSuggestService(repo *repository.ContentRepository, log *zap.Logger) *SuggestService {
+	return &SuggestService{
+		repo:     repo,
+		titles:   newPrefixIndex(true, 0),
+		queries:  newPrefixIndex(false, maxIndexedQueries),
+		spelling: newSpellingIndex(),
+		log:      log,
+		pending:  make(map[string]int),
+		stopCh:   make(chan struct{}),
+	}
+}
+
+// Warm loads every canonical title and the most popular logged queries into the indexes
+func (s *SuggestService) Warm(ctx context.Context) error {
+	contents, err := s.repo.ListSuggestTitles(ctx)
+	if err != nil {
+		return err
+	}
+	s.IndexContents(contents)
+
+	queries, err := s.repo.ListPopularQueries(ctx, maxIndexedQueries)
+	if err != nil {
+		return err
+	}
+	for _, query := range queries {
+		s.queries.set(query.Query, "", float64(query.Count))
+	}
+
+	documents, err := s.repo.ListSpellingDocuments(ctx)
+	if err != nil {
+		return err
+	}
+	for _, document := range documents {
+		s.spelling.set(document)
+	}
+
+	s.log.Info("Suggest index warmed", zap.Int("titles", len(contents)), zap.Int("queries", len(queries)))
+	return nil
+}
+
+// IndexContents adds or refreshes the titles of canonical content, dropping the previous title
+// of renamed content and the title of content linked as a duplicate, and the words spelling is
+// corrected against, which content must come with its tags for
+func (s *SuggestService) IndexContents(contents []*domain.Content) {
+	for _, content := range contents {
+		if content.IsCanonical() {
+			s.titles.setOwned(content.ID, content.Title, content.Type, content.Score)
+		} else {
+			s.titles.release(content.ID)
+		}
+		s.spelling.set(content)
+	}
+}
+
+// CorrectQuery returns the query with every unknown word replaced by its closest title or tag
+// word, or "" when there is nothing to correct
+func (s *SuggestService) CorrectQuery(query string) string {
+	return s.spelling.correct(query)
+}
+
+// RecordQuery counts a search query so that it is offered as a completion. The count reaches
+// the query log on the next Flush.
+func (s *SuggestService) RecordQuery(query string) {
+	key := domain.SuggestKey(query)
+	if key == "" {
+		return
+	}
+	s.queries.increment(key)
+
+	s.pendingMu.Lock()
+	s.pending[key]++
+	s.pendingMu.Unlock()
+}
+
+// Flush writes the searches counted since the last flush to the query log, then prunes the
+// log down to the queries the index can hold. Counts that fail to be written are kept for the
+// next flush.
+func (s *SuggestService) Flush(ctx context.Context) error {
+	s.pendingMu.Lock()
+	counts := s.pending
+	s.pending = make(map[string]int)
+	s.pendingMu.Unlock()
+	if len(counts) == 0 {
+		return nil
+	}
+
+	if err := s.repo.RecordQueries(ctx, counts, time.Now().UTC()); err != nil {
+		s.pendingMu.Lock()
+		for query, count := range counts {
+			s.pending[query] += count
+		}
+		s.pendingMu.Unlock()
+		return err
+	}
+	return s.repo.PruneQueries(ctx, maxIndexedQueries)
+}
+
+// Watch flushes the counted searches every interval until Shutdown is called
+func (s *SuggestService) Watch(interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				if err := s.Flush(context.Background()); err != nil {
+					s.log.Warn("Failed to flush search queries", zap.Error(err))
+				}
+			case <-s.stopCh:
+				s.log.Info("Search query flush goroutine stopped")
+				return
+			}
+		}
+	}()
+}
+
+// Shutdown stops Watch and flushes the searches counted since its last flush
+func (s *SuggestService) Shutdown(ctx context.Context) error {
+	s.stopOnce.Do(func() {
+		close(s.stopCh)
+	})
+	return s.Flush(ctx)
+}
+
+func (s *SuggestService) Suggest(ctx context.Context, req *domain.SuggestRequest) (*domain.SuggestResponse, error) {
+	suggestSpec := domain.NewSuggestSpecification()
+	if err := suggestSpec.NormalizeSuggestRequest(req); err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	resp := &domain.SuggestResponse{
+		Prefix:  req.Prefix,
+		Titles:  s.titles.lookup(req.Prefix, req.ContentType, req.Limit),
+		Queries: s.queries.lookup(req.Prefix, nil, req.Limit),
+	}
+	if elapsed := time.Since(start); elapsed > suggestLatencyTarget {
+		s.log.Warn("Slow suggest lookup", zap.String("prefix", req.Prefix), zap.Duration("elapsed", elapsed))
+	}
+
+	return resp, nil
+}
+
+// prefixIndex maps normalized keys onto weighted suggestions. With anyWord set, an entry is
+// keyed at the start of each of its words, so "kube" also completes "Intro to Kubernetes".
+// Keys are appended unsorted and sorted on the next lookup, which keeps bulk ingests cheap;
+// the keys of removed entries are pruned then too.
+type prefixIndex struct {
+	mu      sync.RWMutex
+	anyWord bool
+	// capacity bounds the number of entries when positive. Once it is exceeded by a tenth, the
+	// lightest entries are evicted down to capacity, the least recently used first.
+	capacity int
+	entries  map[string]*domain.Suggestion
+	// used records when each entry was last set, in ticks of clock
+	used  map[string]uint64
+	clock uint64
+	// owners maps content IDs onto the entry of their title and refs counts the content
+	// sharing each entry, so that an entry is removed once no content has its title
+	owners map[int64]string
+	refs   map[string]int
+	keys   []prefixKey
+	sorted bool
+}
+
+type prefixKey struct {
+	key   string
+	id    string
+	entry *domain.Suggestion
+}
+
+func newPrefixIndex(anyWord bool, capacity int) *prefixIndex {
+	return &prefixIndex{
+		anyWord:  anyWord,
+		capacity: capacity,
+		entries:  make(map[string]*domain.Suggestion),
+		used:     make(map[string]uint64),
+		owners:   make(map[int64]string),
+		refs:     make(map[string]int),
+		sorted:   true,
+	}
+}
+
+// set adds an entry or replaces the weight of an existing one
+func (idx *prefixIndex) set(text string, contentType domain.ContentType, weight float64) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	if _, entry := idx.entry(text, contentType); entry != nil {
+		entry.Weight = weight
+		idx.evict()
+	}
+}
+
+// setOwned sets the entry of a content item's title, replacing the one it owned before
+func (idx *prefixIndex) setOwned(owner int64, text string, contentType domain.ContentType, weight float64) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	id, entry := idx.entry(text, contentType)
+	if previous, ok := idx.owners[owner]; !ok || previous != id {
+		idx.releaseLocked(owner)
+		if entry == nil {
+			return
+		}
+		idx.owners[owner] = id
+		idx.refs[id]++
+	}
+	entry.Weight = weight
+}
+
+// release drops the entry a content item owned, unless other content shares it
+func (idx *prefixIndex) release(owner int64) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	idx.releaseLocked(owner)
+}
+
+func (idx *prefixIndex) releaseLocked(owner int64) {
+	id, ok := idx.owners[owner]
+	if !ok {
+		return
+	}
+	delete(idx.owners, owner)
+	if idx.refs[id]--; idx.refs[id] <= 0 {
+		delete(idx.refs, id)
+		idx.remove(id)
+	}
+}
+
+// increment adds one to the weight of an entry, adding the entry if needed
+func (idx *prefixIndex) increment(text string) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	if _, entry := idx.entry(text, ""); entry != nil {
+		entry.Weight++
+		idx.evict()
+	}
+}
+
+// entry returns the ID and entry for text and type, creating and keying the entry if it does
+// not exist yet. Callers must hold the write lock.
+func (idx *prefixIndex) entry(text string, contentType domain.ContentType) (string, *domain.Suggestion) {
+	key := domain.SuggestKey(text)
+	if key == "" {
+		return "", nil
+	}
+	id := key + "\x00" + string(contentType)
+	idx.clock++
+	idx.used[id] = idx.clock
+	if entry, ok := idx.entries[id]; ok {
+		return id, entry
+	}
+
+	entry := &domain.Suggestion{Text: strings.TrimSpace(text), Type: contentType}
+	idx.entries[id] = entry
+	idx.keys = append(idx.keys, prefixKey{key: key, id: id, entry: entry})
+	if idx.anyWord {
+		for i := 1; i < len(key); i++ {
+			if key[i-1] == ' ' {
+				idx.keys = append(idx.keys, prefixKey{key: key[i:], id: id, entry: entry})
+			}
+		}
+	}
+	idx.sorted = false
+	return id, entry
+}
+
+// remove drops an entry. Its keys are pruned on the next lookup. Callers must hold the write
+// lock.
+func (idx *prefixIndex) remove(id string) {
+	delete(idx.entries, id)
+	delete(idx.used, id)
+	idx.sorted = false
+}
+
+// evict removes the lightest entries once the index holds a tenth more than its capacity.
+// Callers must hold the write lock.
+func (idx *prefixIndex) evict() {
+	if idx.capacity <= 0 || len(idx.entries) <= idx.capacity+idx.capacity/10 {
+		return
+	}
+
+	ids := make([]string, 0, len(idx.entries))
+	for id := range idx.entries {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		if wi, wj := idx.entries[ids[i]].Weight, idx.entries[ids[j]].Weight; wi != wj {
+			return wi < wj
+		}
+		return idx.used[ids[i]] < idx.used[ids[j]]
+	})
+	for _, id := range ids[:len(ids)-idx.capacity] {
+		idx.remove(id)
+	}
+}
+
+// lookup returns copies of the heaviest entries with a key starting with prefix
+func (idx *prefixIndex) lookup(prefix string, contentType *domain.ContentType, limit int) []*domain.Suggestion {
+	idx.ensureSorted()
+
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	key := domain.SuggestKey(prefix)
+	start := sort.Search(len(idx.keys), func(i int) bool {
+		return idx.keys[i].key >= key
+	})
+
+	seen := make(map[*domain.Suggestion]bool)
+	matches := []*domain.Suggestion{}
+	for i := start; i < len(idx.keys) && strings.HasPrefix(idx.keys[i].key, key); i++ {
+		entry := idx.keys[i].entry
+		if seen[entry] || idx.entries[idx.keys[i].id] != entry || contentType != nil && entry.Type != *contentType {
+			continue
+		}
+		seen[entry] = true
+		suggestion := *entry
+		matches = append(matches, &suggestion)
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Weight != matches[j].Weight {
+			return matches[i].Weight > matches[j].Weight
+		}
+		return matches[i].Text < matches[j].Text
+	})
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches
+}
+
+func (idx *prefixIndex) ensureSorted() {
+	idx.mu.RLock()
+	sorted := idx.sorted
+	idx.mu.RUnlock()
+	if sorted {
+		return
+	}
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	if !idx.sorted {
+		keys := idx.keys[:0]
+		for _, key := range idx.keys {
+			if idx.entries[key.id] == key.entry {
+				keys = append(keys, key)
+			}
+		}
+		idx.keys = keys
+		sort.Slice(idx.keys, func(i, j int) bool {
+			return idx.keys[i].key < idx.keys[j].key
+		})
+		idx.sorted = true
+	}
+}
+
+// spellingIndex counts the words of canonical titles and of tag assignments. It keeps what
+// each content item added, so that an update or a duplicate link replaces the item's words
+// instead of adding to them.
+type spellingIndex struct {
+	mu         sync.RWMutex
+	dictionary domain.SpellingDictionary
+	documents  map[int64]spellingDocument
+}
+
+// spellingDocument is the text of one content item in the dictionary: its title while it is
+// canonical, and its tag names
+type spellingDocument struct {
+	title string
+	tags  []string
+}
+
+func newSpellingIndex() *spellingIndex {
+	return &spellingIndex{
+		dictionary: make(domain.SpellingDictionary),
+		documents:  make(map[int64]spellingDocument),
+	}
+}
+
+// set adds the words of content, replacing those it added before
+func (idx *spellingIndex) set(content *domain.Content) {
+	document := spellingDocument{tags: domain.TagNames(content.Tags)}
+	if content.IsCanonical() {
+		document.title = content.Title
+	}
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	if previous, ok := idx.documents[content.ID]; ok {
+		idx.dictionary.Remove(previous.title, 1)
+		for _, tag := range previous.tags {
+			idx.dictionary.Remove(tag, 1)
+		}
+	}
+	idx.dictionary.Add(document.title, 1)
+	for _, tag := range document.tags {
+		idx.dictionary.Add(tag, 1)
+	}
+	idx.documents[content.ID] = document
+}
+
+func (idx *spellingIndex) correct(query string) string {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	spellingSpec := domain.NewSpellingSpecification()
+	return spellingSpec.CorrectQuery(query, idx.dictionary)
+}
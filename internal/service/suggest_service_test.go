@@ -0,0 +1,262 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"search-engine-go/internal/domain"
+	"search-engine-go/internal/infrastructure/cache"
+	"search-engine-go/internal/repository"
+	"search-engine-go/pkg/adapter"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func suggestionTexts(suggestions []*domain.Suggestion) []string {
+	texts := make([]string, 0, len(suggestions))
+	for _, suggestion := range suggestions {
+		texts = append(texts, suggestion.Text)
+	}
+	return texts
+}
+
+func TestSuggestService_Suggest(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	ctx := context.Background()
+	db := setupTestDB(t)
+	repo := repository.NewContentRepository(db)
+	service := NewSuggestService(repo, logger)
+
+	service.IndexContents([]*domain.Content{
+		{ID: 1, Title: "Kubernetes Operators", Type: domain.ContentTypeVideo, Score: 5},
+		{ID: 2, Title: "Intro to Kubernetes", Type: domain.ContentTypeText, Score: 9},
+		{ID: 3, Title: "Kubernetes   in Production", Type: domain.ContentTypeVideo, Score: 7},
+		{ID: 4, Title: "Kotlin Coroutines", Type: domain.ContentTypeVideo, Score: 8},
+	})
+
+	t.Run("Completes titles at any word, heaviest first", func(t *testing.T) {
+		resp, err := service.Suggest(ctx, &domain.SuggestRequest{Prefix: "KUBE"})
+
+		require.NoError(t, err)
+		assert.Equal(t, "KUBE", resp.Prefix)
+		assert.Equal(t, []string{"Intro to Kubernetes", "Kubernetes   in Production", "Kubernetes Operators"}, suggestionTexts(resp.Titles))
+		assert.Empty(t, resp.Queries)
+	})
+
+	t.Run("Multi-word prefixes", func(t *testing.T) {
+		resp, err := service.Suggest(ctx, &domain.SuggestRequest{Prefix: "kubernetes in"})
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Kubernetes   in Production"}, suggestionTexts(resp.Titles))
+	})
+
+	t.Run("Filters by type and limits results", func(t *testing.T) {
+		videoType := domain.ContentTypeVideo
+		resp, err := service.Suggest(ctx, &domain.SuggestRequest{Prefix: "k", ContentType: &videoType, Limit: 2})
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Kotlin Coroutines", "Kubernetes   in Production"}, suggestionTexts(resp.Titles))
+	})
+
+	t.Run("Re-indexing updates the weight", func(t *testing.T) {
+		service.IndexContents([]*domain.Content{{ID: 1, Title: "Kubernetes Operators", Type: domain.ContentTypeVideo, Score: 10}})
+
+		resp, err := service.Suggest(ctx, &domain.SuggestRequest{Prefix: "kubernetes"})
+
+		require.NoError(t, err)
+		require.NotEmpty(t, resp.Titles)
+		assert.Equal(t, "Kubernetes Operators", resp.Titles[0].Text)
+		assert.Equal(t, 10.0, resp.Titles[0].Weight)
+		assert.Len(t, resp.Titles, 3)
+	})
+
+	t.Run("Duplicates are not indexed", func(t *testing.T) {
+		canonicalID := int64(1)
+		service.IndexContents([]*domain.Content{{ID: 5, Title: "Kubernetes Deep Dive", Type: domain.ContentTypeVideo, CanonicalID: &canonicalID}})
+
+		resp, err := service.Suggest(ctx, &domain.SuggestRequest{Prefix: "kubernetes deep"})
+
+		require.NoError(t, err)
+		assert.Empty(t, resp.Titles)
+	})
+
+	t.Run("Renamed and linked content drops its old title", func(t *testing.T) {
+		service.IndexContents([]*domain.Content{
+			{ID: 6, Title: "Kubernetes Networking", Type: domain.ContentTypeText, Score: 4},
+			{ID: 7, Title: "Kubernetes Networking", Type: domain.ContentTypeText, Score: 4},
+			{ID: 8, Title: "Kubernetes Storage", Type: domain.ContentTypeText, Score: 3},
+		})
+		canonicalID := int64(1)
+		service.IndexContents([]*domain.Content{
+			{ID: 6, Title: "Kubernetes Networking Basics", Type: domain.ContentTypeText, Score: 4},
+			{ID: 8, Title: "Kubernetes Storage", Type: domain.ContentTypeText, CanonicalID: &canonicalID},
+		})
+
+		resp, err := service.Suggest(ctx, &domain.SuggestRequest{Prefix: "kubernetes n"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Kubernetes Networking", "Kubernetes Networking Basics"}, suggestionTexts(resp.Titles))
+
+		service.IndexContents([]*domain.Content{{ID: 7, Title: "Kubernetes Networking Basics", Type: domain.ContentTypeText, Score: 4}})
+		resp, err = service.Suggest(ctx, &domain.SuggestRequest{Prefix: "kubernetes n"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Kubernetes Networking Basics"}, suggestionTexts(resp.Titles))
+
+		resp, err = service.Suggest(ctx, &domain.SuggestRequest{Prefix: "kubernetes s"})
+		require.NoError(t, err)
+		assert.Empty(t, resp.Titles)
+	})
+
+	t.Run("Completes logged queries by popularity", func(t *testing.T) {
+		service.RecordQuery("kubernetes helm")
+		service.RecordQuery("Kubernetes  Operators")
+		service.RecordQuery("kubernetes operators")
+
+		resp, err := service.Suggest(ctx, &domain.SuggestRequest{Prefix: "kubernetes"})
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"kubernetes operators", "kubernetes helm"}, suggestionTexts(resp.Queries))
+		assert.Equal(t, 2.0, resp.Queries[0].Weight)
+	})
+
+	t.Run("Invalid requests", func(t *testing.T) {
+		_, err := service.Suggest(ctx, &domain.SuggestRequest{Prefix: " "})
+
+		require.Error(t, err)
+		domainErr, ok := err.(*domain.DomainError)
+		require.True(t, ok)
+		assert.Equal(t, domain.ErrorCodeInvalidInput, domainErr.Code)
+	})
+}
+
+func TestPrefixIndex_EvictsLightestQueries(t *testing.T) {
+	idx := newPrefixIndex(false, 10)
+	for i := 0; i < 10; i++ {
+		idx.increment(fmt.Sprintf("query %d", i))
+	}
+	idx.increment("query 9")
+	idx.increment("new a")
+	idx.increment("new b")
+
+	assert.Len(t, idx.entries, 10)
+	assert.Equal(t, []string{"query 9", "query 2", "query 3"}, suggestionTexts(idx.lookup("query", nil, 3)))
+	assert.Len(t, idx.lookup("query", nil, 20), 8)
+	assert.Equal(t, []string{"new a", "new b"}, suggestionTexts(idx.lookup("new", nil, 20)))
+}
+
+func TestSuggestService_Warm(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	ctx := context.Background()
+	db := setupTestDB(t)
+	repo := repository.NewContentRepository(db)
+
+	require.NoError(t, repo.BatchCreateOrUpdate(ctx, []*domain.Content{
+		{ProviderID: "p1_1", Provider: "provider1", Title: "Golang Generics", Type: domain.ContentTypeText, Score: 6},
+	}))
+	previous := NewSuggestService(repo, logger)
+	previous.RecordQuery("golang generics")
+	require.NoError(t, previous.Flush(ctx))
+
+	service := NewSuggestService(repo, logger)
+	require.NoError(t, service.Warm(ctx))
+
+	resp, err := service.Suggest(ctx, &domain.SuggestRequest{Prefix: "gola"})
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Golang Generics"}, suggestionTexts(resp.Titles))
+	assert.Equal(t, []string{"golang generics"}, suggestionTexts(resp.Queries))
+	assert.Equal(t, "golang generics", service.CorrectQuery("golnag generics"))
+}
+
+func TestSuggestService_Flush(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	ctx := context.Background()
+	db := setupTestDB(t)
+	repo := repository.NewContentRepository(db)
+	service := NewSuggestService(repo, logger)
+
+	service.RecordQuery("golang generics")
+	service.RecordQuery("Golang  Generics")
+	service.RecordQuery("rust")
+
+	queries, err := repo.ListPopularQueries(ctx, 10)
+	require.NoError(t, err)
+	assert.Empty(t, queries, "searches are not written before a flush")
+
+	require.NoError(t, service.Flush(ctx))
+	service.RecordQuery("rust")
+	require.NoError(t, service.Shutdown(ctx))
+
+	queries, err = repo.ListPopularQueries(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, queries, 2)
+	assert.Equal(t, "golang generics", queries[0].Query)
+	assert.Equal(t, 2, queries[0].Count)
+	assert.Equal(t, "rust", queries[1].Query)
+	assert.Equal(t, 2, queries[1].Count)
+}
+
+func TestSuggestService_CorrectQuery(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	db := setupTestDB(t)
+	service := NewSuggestService(repository.NewContentRepository(db), logger)
+
+	service.IndexContents([]*domain.Content{
+		{ID: 1, Title: "Kubernetes Operators", Type: domain.ContentTypeVideo, Tags: domain.NewTags("devops")},
+		{ID: 2, Title: "Docker Handbook", Type: domain.ContentTypeText},
+	})
+
+	t.Run("Corrects against titles and tags", func(t *testing.T) {
+		assert.Equal(t, "kubernetes operators", service.CorrectQuery("kubernets operatrs"))
+		assert.Equal(t, "devops", service.CorrectQuery("devosp"))
+		assert.Empty(t, service.CorrectQuery("kubernetes"))
+	})
+
+	t.Run("Updates replace the words of a title", func(t *testing.T) {
+		service.IndexContents([]*domain.Content{{ID: 1, Title: "Kubernetes Controllers", Type: domain.ContentTypeVideo}})
+
+		assert.Equal(t, "controllers", service.CorrectQuery("controlers"))
+		assert.Empty(t, service.CorrectQuery("operatrs"))
+		assert.Empty(t, service.CorrectQuery("devosp"))
+	})
+
+	t.Run("Duplicates only keep their tags", func(t *testing.T) {
+		canonicalID := int64(1)
+		service.IndexContents([]*domain.Content{{ID: 2, Title: "Docker Handbook", Type: domain.ContentTypeText, CanonicalID: &canonicalID, Tags: domain.NewTags("containers")}})
+
+		assert.Empty(t, service.CorrectQuery("handbok"))
+		assert.Equal(t, "containers", service.CorrectQuery("contaners"))
+	})
+}
+
+func TestContentService_SearchFeedsSuggestions(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	ctx := context.Background()
+	db := setupTestDB(t)
+	repo := repository.NewContentRepository(db)
+	cacheClient := cache.NewInMemory()
+	defer cacheClient.Close()
+
+	registry := adapter.NewAdapterRegistry()
+	registry.Register("suggest-provider", &MockAdapter{name: "suggest-provider", contents: []*domain.Content{
+		{ProviderID: "s_1", Provider: "suggest-provider", Title: "Terraform Modules", Type: domain.ContentTypeVideo},
+	}})
+	service := NewContentService(repo, NewProviderService(registry, logger), NewScoringService(), cacheClient, logger)
+
+	_, err := service.Search(ctx, &domain.SearchRequest{Query: "terraform"})
+	require.NoError(t, err)
+	_, err = service.Search(ctx, &domain.SearchRequest{Query: "terraform"})
+	require.NoError(t, err)
+	_, err = service.Search(ctx, &domain.SearchRequest{Query: "terraform", Page: 2})
+	require.NoError(t, err)
+
+	resp, err := service.Suggest(ctx, &domain.SuggestRequest{Prefix: "terra"})
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Terraform Modules"}, suggestionTexts(resp.Titles))
+	require.Len(t, resp.Queries, 1)
+	assert.Equal(t, "terraform", resp.Queries[0].Text)
+	assert.Equal(t, 2.0, resp.Queries[0].Weight)
+}
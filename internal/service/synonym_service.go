@@ -0,0 +1,188 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"sync"
+	"time"
+
+	"search-engine-go/internal/domain"
+	"search-engine-go/internal/repository"
+
+	"go.uber.org/zap"
+)
+
+type SynonymServiceInterface interface {
+	List(ctx context.Context) ([]*domain.SynonymSet, error)
+	Get(ctx context.Context, id int64) (*domain.SynonymSet, error)
+	Create(ctx context.Context, set *domain.SynonymSet) (*domain.SynonymSet, error)
+	Update(ctx context.Context, id int64, set *domain.SynonymSet) (*domain.SynonymSet, error)
+	Delete(ctx context.Context, id int64) error
+	Reload(ctx context.Context) error
+}
+
+// SynonymService keeps an in-memory dictionary of the synonym sets stored in the database and,
+// optionally, a JSON file of sets. The dictionary is rebuilt after every change made through
+// the service and, with Watch, periodically so that edits from other instances or to the file
+// are picked up without a restart.
+type SynonymService struct {
+	repo *repository.SynonymRepository
+	spec *domain.SynonymSpecification
+	file string
+	log  *zap.Logger
+
+	mu         sync.RWMutex
+	dictionary *domain.SynonymDictionary
+
+	stopCh   chan struct{}
+	stopOnce sync.Once
+}
+
+// NewSynonymService creates a service with an empty dictionary; file may be empty
+func NewSynonymService(repo *repository.SynonymRepository, file string, log *zap.Logger) *SynonymService {
+	return &SynonymService{
+		repo:       repo,
+		spec:       domain.NewSynonymSpecification(),
+		file:       file,
+		log:        log,
+		dictionary: domain.NewSynonymDictionary(nil),
+		stopCh:     make(chan struct{}),
+	}
+}
+
+// Expand applies the current dictionary to a parsed query
+func (s *SynonymService) Expand(node domain.QueryNode) domain.QueryNode {
+	if node == nil {
+		return nil
+	}
+	s.mu.RLock()
+	dictionary := s.dictionary
+	s.mu.RUnlock()
+	return dictionary.Expand(node)
+}
+
+// Reload rebuilds the dictionary from the database and the synonyms file. The previous
+// dictionary stays in use when either cannot be read.
+func (s *SynonymService) Reload(ctx context.Context) error {
+	sets, err := s.repo.List(ctx)
+	if err != nil {
+		return err
+	}
+	fileSets, err := s.loadFile()
+	if err != nil {
+		return err
+	}
+	sets = append(sets, fileSets...)
+
+	dictionary := domain.NewSynonymDictionary(sets)
+	s.mu.Lock()
+	s.dictionary = dictionary
+	s.mu.Unlock()
+
+	s.log.Debug("Synonym dictionary reloaded", zap.Int("sets", len(sets)), zap.Int("terms", dictionary.Len()))
+	return nil
+}
+
+// loadFile reads the synonym sets of the configured file, a JSON array of sets
+func (s *SynonymService) loadFile() ([]*domain.SynonymSet, error) {
+	if s.file == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(s.file)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, domain.NewInternalError("failed to read synonyms file", err)
+	}
+
+	var sets []*domain.SynonymSet
+	if err := json.Unmarshal(data, &sets); err != nil {
+		return nil, domain.NewInternalError("failed to parse synonyms file", err)
+	}
+	for _, set := range sets {
+		if err := s.spec.NormalizeSynonymSet(set); err != nil {
+			return nil, domain.NewInternalError("invalid synonym set in synonyms file", err)
+		}
+	}
+	return sets, nil
+}
+
+// Watch reloads the dictionary every interval until Shutdown is called
+func (s *SynonymService) Watch(interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				if err := s.Reload(context.Background()); err != nil {
+					s.log.Warn("Failed to reload synonyms", zap.Error(err))
+				}
+			case <-s.stopCh:
+				s.log.Info("Synonym reload goroutine stopped")
+				return
+			}
+		}
+	}()
+}
+
+func (s *SynonymService) Shutdown() {
+	s.stopOnce.Do(func() {
+		close(s.stopCh)
+	})
+}
+
+func (s *SynonymService) List(ctx context.Context) ([]*domain.SynonymSet, error) {
+	return s.repo.List(ctx)
+}
+
+func (s *SynonymService) Get(ctx context.Context, id int64) (*domain.SynonymSet, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+func (s *SynonymService) Create(ctx context.Context, set *domain.SynonymSet) (*domain.SynonymSet, error) {
+	if err := s.spec.NormalizeSynonymSet(set); err != nil {
+		return nil, err
+	}
+	set.ID = 0
+	if err := s.repo.Create(ctx, set); err != nil {
+		return nil, err
+	}
+	s.reloadAfterChange(ctx)
+	return set, nil
+}
+
+func (s *SynonymService) Update(ctx context.Context, id int64, set *domain.SynonymSet) (*domain.SynonymSet, error) {
+	if err := s.spec.NormalizeSynonymSet(set); err != nil {
+		return nil, err
+	}
+	set.ID = id
+	if err := s.repo.Update(ctx, set); err != nil {
+		return nil, err
+	}
+	s.reloadAfterChange(ctx)
+	return set, nil
+}
+
+func (s *SynonymService) Delete(ctx context.Context, id int64) error {
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+	s.reloadAfterChange(ctx)
+	return nil
+}
+
+// reloadAfterChange applies a stored change right away. A failed reload only delays it until
+// the next periodic reload, so it does not fail the change.
+func (s *SynonymService) reloadAfterChange(ctx context.Context) {
+	if err := s.Reload(ctx); err != nil {
+		s.log.Warn("Failed to reload synonyms after change", zap.Error(err))
+	}
+}
@@ -0,0 +1,136 @@
+package service
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"search-engine-go/internal/domain"
+	"search-engine-go/internal/infrastructure/cache"
+	"search-engine-go/internal/repository"
+	"search-engine-go/pkg/adapter"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func expandQuery(t *testing.T, service *SynonymService, query string) string {
+	node, err := domain.ParseQuery(query)
+	require.NoError(t, err)
+	return service.Expand(node).String()
+}
+
+func TestSynonymService(t *testing.T) {
+	ctx := context.Background()
+	logger, _ := zap.NewDevelopment()
+
+	t.Run("Changes apply to expansion", func(t *testing.T) {
+		service := NewSynonymService(repository.NewSynonymRepository(setupTestDB(t)), "", logger)
+
+		created, err := service.Create(ctx, &domain.SynonymSet{
+			Name:  "Infra",
+			Rules: []domain.SynonymRule{{Terms: []string{"K8s", "Kubernetes"}}},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"k8s", "kubernetes"}, created.Rules[0].Terms)
+		assert.Equal(t, "(k8s OR kubernetes)", expandQuery(t, service, "k8s"))
+
+		_, err = service.Update(ctx, created.ID, &domain.SynonymSet{
+			Name:  "Infra",
+			Rules: []domain.SynonymRule{{Terms: []string{"k8s"}, Synonyms: []string{"kube"}}},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "(k8s OR kube)", expandQuery(t, service, "k8s"))
+
+		stored, err := service.Get(ctx, created.ID)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"kube"}, stored.Rules[0].Synonyms)
+
+		require.NoError(t, service.Delete(ctx, created.ID))
+		assert.Equal(t, "k8s", expandQuery(t, service, "k8s"))
+	})
+
+	t.Run("Duplicate name", func(t *testing.T) {
+		service := NewSynonymService(repository.NewSynonymRepository(setupTestDB(t)), "", logger)
+		set := func() *domain.SynonymSet {
+			return &domain.SynonymSet{Name: "infra", Rules: []domain.SynonymRule{{Terms: []string{"a", "b"}}}}
+		}
+
+		_, err := service.Create(ctx, set())
+		require.NoError(t, err)
+		_, err = service.Create(ctx, set())
+
+		var domainErr *domain.DomainError
+		require.ErrorAs(t, err, &domainErr)
+		assert.Equal(t, domain.ErrorCodeInvalidInput, domainErr.Code)
+	})
+
+	t.Run("Unknown set", func(t *testing.T) {
+		service := NewSynonymService(repository.NewSynonymRepository(setupTestDB(t)), "", logger)
+		set := &domain.SynonymSet{Name: "infra", Rules: []domain.SynonymRule{{Terms: []string{"a", "b"}}}}
+
+		var domainErr *domain.DomainError
+		_, err := service.Update(ctx, 42, set)
+		require.ErrorAs(t, err, &domainErr)
+		assert.Equal(t, domain.ErrorCodeNotFound, domainErr.Code)
+
+		err = service.Delete(ctx, 42)
+		require.ErrorAs(t, err, &domainErr)
+		assert.Equal(t, domain.ErrorCodeNotFound, domainErr.Code)
+	})
+
+	t.Run("Reload reads the synonyms file", func(t *testing.T) {
+		file := filepath.Join(t.TempDir(), "synonyms.json")
+		service := NewSynonymService(repository.NewSynonymRepository(setupTestDB(t)), file, logger)
+
+		require.NoError(t, service.Reload(ctx))
+		assert.Equal(t, "js", expandQuery(t, service, "js"))
+
+		require.NoError(t, os.WriteFile(file, []byte(`[{"name": "web", "rules": [{"terms": ["JS"], "synonyms": ["javascript"]}]}]`), 0o644))
+		require.NoError(t, service.Reload(ctx))
+		assert.Equal(t, "(js OR javascript)", expandQuery(t, service, "js"))
+
+		require.NoError(t, os.WriteFile(file, []byte(`not json`), 0o644))
+		assert.Error(t, service.Reload(ctx))
+		assert.Equal(t, "(js OR javascript)", expandQuery(t, service, "js"))
+	})
+}
+
+func TestContentService_SearchSynonyms(t *testing.T) {
+	ctx := context.Background()
+	logger, _ := zap.NewDevelopment()
+	db := setupTestDB(t)
+	repo := repository.NewContentRepository(db)
+	cacheClient := cache.NewInMemory()
+	defer cacheClient.Close()
+
+	registry := adapter.NewAdapterRegistry()
+	registry.Register("synonym-provider", &MockAdapter{
+		name: "synonym-provider",
+		contents: []*domain.Content{
+			{ProviderID: "synonym_1", Provider: "synonym-provider", Title: "Kubernetes in Production", Type: domain.ContentTypeVideo},
+			{ProviderID: "synonym_2", Provider: "synonym-provider", Title: "Docker Basics", Type: domain.ContentTypeVideo},
+		},
+	})
+	service := NewContentService(repo, NewProviderService(registry, logger), NewScoringService(), cacheClient, logger)
+
+	synonymSvc := NewSynonymService(repository.NewSynonymRepository(db), "", logger)
+	_, err := synonymSvc.Create(ctx, &domain.SynonymSet{
+		Name:  "infra",
+		Rules: []domain.SynonymRule{{Terms: []string{"k8s", "kubernetes"}}},
+	})
+	require.NoError(t, err)
+	service.SetSynonymService(synonymSvc)
+
+	resp, err := service.Search(ctx, &domain.SearchRequest{Query: "k8s"})
+	require.NoError(t, err)
+	require.Len(t, resp.Items, 1)
+	assert.Equal(t, "Kubernetes in Production", resp.Items[0].Title)
+
+	expand := false
+	resp, err = service.Search(ctx, &domain.SearchRequest{Query: "k8s", Expand: &expand})
+	require.NoError(t, err)
+	assert.Empty(t, resp.Items)
+}
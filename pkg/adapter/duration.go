@@ -0,0 +1,62 @@
+package adapter
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var isoDurationPattern = regexp.MustCompile(`^P(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+(?:\.\d+)?)S)?)?$`)
+
+// parseDurationSeconds converts provider duration strings ("15:30", "1:02:45",
+// "PT15M30S" or a bare number of seconds) into seconds. Unparseable values yield 0.
+func parseDurationSeconds(raw string) int {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return 0
+	}
+
+	if strings.HasPrefix(strings.ToUpper(raw), "P") {
+		return parseISODuration(strings.ToUpper(raw))
+	}
+
+	if strings.Contains(raw, ":") {
+		return parseClockDuration(raw)
+	}
+
+	if seconds, err := strconv.ParseFloat(raw, 64); err == nil && seconds >= 0 {
+		return int(seconds)
+	}
+	return 0
+}
+
+func parseClockDuration(raw string) int {
+	parts := strings.Split(raw, ":")
+	if len(parts) > 3 {
+		return 0
+	}
+
+	total := 0
+	for _, part := range parts {
+		value, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil || value < 0 {
+			return 0
+		}
+		total = total*60 + value
+	}
+	return total
+}
+
+func parseISODuration(raw string) int {
+	matches := isoDurationPattern.FindStringSubmatch(raw)
+	if matches == nil || raw == "P" || raw == "PT" {
+		return 0
+	}
+
+	days, _ := strconv.Atoi(matches[1])
+	hours, _ := strconv.Atoi(matches[2])
+	minutes, _ := strconv.Atoi(matches[3])
+	seconds, _ := strconv.ParseFloat(matches[4], 64)
+
+	return days*86400 + hours*3600 + minutes*60 + int(seconds)
+}
@@ -0,0 +1,34 @@
+package adapter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDurationSeconds(t *testing.T) {
+	tests := []struct {
+		name     string
+		raw      string
+		expected int
+	}{
+		{name: "Minutes and seconds", raw: "15:30", expected: 930},
+		{name: "Hours minutes and seconds", raw: "1:02:45", expected: 3765},
+		{name: "ISO-8601 minutes and seconds", raw: "PT15M30S", expected: 930},
+		{name: "ISO-8601 hours", raw: "PT1H", expected: 3600},
+		{name: "ISO-8601 with days", raw: "P1DT2H", expected: 93600},
+		{name: "ISO-8601 lowercase", raw: "pt2m", expected: 120},
+		{name: "Bare seconds", raw: "95", expected: 95},
+		{name: "Surrounding whitespace", raw: " 03:05 ", expected: 185},
+		{name: "Empty", raw: "", expected: 0},
+		{name: "Garbage", raw: "about ten minutes", expected: 0},
+		{name: "Too many segments", raw: "1:2:3:4", expected: 0},
+		{name: "Empty ISO-8601", raw: "PT", expected: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, parseDurationSeconds(tt.raw))
+		})
+	}
+}
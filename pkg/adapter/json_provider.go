@@ -12,6 +12,7 @@ import (
 
 	"search-engine-go/internal/domain"
 
+	"go.uber.org/zap"
 	"golang.org/x/time/rate"
 )
 
@@ -22,6 +23,7 @@ type JSONProviderAdapter struct {
 	rateLimiter *rate.Limiter
 	retryCount  int
 	retryDelay  time.Duration
+	log         *zap.Logger
 }
 
 func NewJSONProviderAdapter(name, url string, rateLimit int, timeout time.Duration) *JSONProviderAdapter {
@@ -41,6 +43,7 @@ func NewJSONProviderAdapterWithRetry(name, url string, rateLimit int, timeout ti
 		rateLimiter: rate.NewLimiter(rate.Limit(rps), rateLimit),
 		retryCount:  retryCount,
 		retryDelay:  retryDelay,
+		log:         zap.NewNop(),
 	}
 }
 
@@ -127,13 +130,26 @@ func (a *JSONProviderAdapter) FetchContent(ctx context.Context, query string, co
 
 	contents := make([]*domain.Content, 0, len(jsonResponse.Contents))
 	for _, item := range jsonResponse.Contents {
-		content := a.convertToDomain(item)
+		content, err := a.convertToDomain(item)
+		if err != nil {
+			a.log.Warn("Skipping provider item",
+				zap.String("provider", a.name),
+				zap.String("id", item.ID),
+				zap.Error(err),
+			)
+			continue
+		}
 		contents = append(contents, content)
 	}
 
 	return contents, nil
 }
 
+// SetLogger makes the adapter log the items it skips
+func (a *JSONProviderAdapter) SetLogger(log *zap.Logger) {
+	a.log = log
+}
+
 func (a *JSONProviderAdapter) isFilePath(url string) bool {
 	return !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://")
 }
@@ -154,20 +170,32 @@ type JSONContentItem struct {
 	Metrics     Metrics  `json:"metrics"`
 	PublishedAt string   `json:"published_at"`
 	Tags        []string `json:"tags"`
+	Language    string   `json:"language,omitempty"`
 }
 
 type Metrics struct {
-	Views       int    `json:"views,omitempty"`
-	Likes       int    `json:"likes,omitempty"`
-	Duration    string `json:"duration,omitempty"`
-	ReadingTime int    `json:"reading_time,omitempty"`
-	Reactions   int    `json:"reactions,omitempty"`
+	Views         int    `json:"views,omitempty"`
+	Likes         int    `json:"likes,omitempty"`
+	Duration      string `json:"duration,omitempty"`
+	ReadingTime   int    `json:"reading_time,omitempty"`
+	Reactions     int    `json:"reactions,omitempty"`
+	Comments      int    `json:"comments,omitempty"`
+	Listens       int    `json:"listens,omitempty"`
+	EpisodeLength string `json:"episode_length,omitempty"`
+	ImageCount    int    `json:"image_count,omitempty"`
 }
 
-func (a *JSONProviderAdapter) convertToDomain(item JSONContentItem) *domain.Content {
-	contentType := domain.ContentTypeText
-	if item.Type == "video" {
-		contentType = domain.ContentTypeVideo
+// convertToDomain maps an item onto content, failing for types we have no content type for
+func (a *JSONProviderAdapter) convertToDomain(item JSONContentItem) (*domain.Content, error) {
+	contentType, ok := domain.ParseProviderContentType(item.Type)
+	if !ok {
+		return nil, fmt.Errorf("unknown content type %q", item.Type)
+	}
+
+	// Podcasts report their length as episode_length rather than duration
+	duration := item.Metrics.Duration
+	if duration == "" {
+		duration = item.Metrics.EpisodeLength
 	}
 
 	createdAt := time.Now()
@@ -186,6 +214,12 @@ func (a *JSONProviderAdapter) convertToDomain(item JSONContentItem) *domain.Cont
 		Likes:       item.Metrics.Likes,
 		ReadingTime: item.Metrics.ReadingTime,
 		Reactions:   item.Metrics.Reactions,
+		Duration:    parseDurationSeconds(duration),
+		Comments:    item.Metrics.Comments,
+		Listens:     item.Metrics.Listens,
+		ImageCount:  item.Metrics.ImageCount,
+		Tags:        domain.NewTags(item.Tags...),
+		Language:    item.Language,
 		CreatedAt:   createdAt,
-	}
+	}, nil
 }
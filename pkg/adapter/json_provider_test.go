@@ -11,6 +11,8 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
 )
 
 func TestJSONProviderAdapter_GetName(t *testing.T) {
@@ -123,6 +125,28 @@ func TestJSONProviderAdapter_FetchContent_WithContentTypeFilter(t *testing.T) {
 	assert.Len(t, contents, 2)
 }
 
+func TestJSONProviderAdapter_FetchContent_SkipsUnknownTypes(t *testing.T) {
+	jsonFile := filepath.Join(t.TempDir(), "test.json")
+	jsonContent := `{
+		"contents": [
+			{"id": "v1", "title": "Test Video", "type": "video"},
+			{"id": "l1", "title": "Test Livestream", "type": "livestream"}
+		]
+	}`
+	require.NoError(t, os.WriteFile(jsonFile, []byte(jsonContent), 0644))
+	core, logs := observer.New(zap.WarnLevel)
+	adapter := NewJSONProviderAdapter("test-provider", jsonFile, 60, 5*time.Second)
+	adapter.SetLogger(zap.New(core))
+
+	contents, err := adapter.FetchContent(context.Background(), "", nil)
+
+	require.NoError(t, err)
+	require.Len(t, contents, 1)
+	assert.Equal(t, "test-provider_v1", contents[0].ProviderID)
+	require.Equal(t, 1, logs.Len())
+	assert.Equal(t, "l1", logs.All()[0].ContextMap()["id"])
+}
+
 func TestJSONProviderAdapter_FetchContent_InvalidJSON(t *testing.T) {
 	tmpDir := t.TempDir()
 	jsonFile := filepath.Join(tmpDir, "invalid.json")
@@ -157,13 +181,15 @@ func TestJSONProviderAdapter_convertToDomain(t *testing.T) {
 			Title: "Test Video",
 			Type:  "video",
 			Metrics: Metrics{
-				Views: 1000,
-				Likes: 50,
+				Views:    1000,
+				Likes:    50,
+				Duration: "15:30",
 			},
 			PublishedAt: "2024-03-15T10:00:00Z",
 		}
 
-		content := adapter.convertToDomain(item)
+		content, err := adapter.convertToDomain(item)
+		require.NoError(t, err)
 
 		assert.Equal(t, "test-provider_v1", content.ProviderID)
 		assert.Equal(t, "test-provider", content.Provider)
@@ -171,6 +197,7 @@ func TestJSONProviderAdapter_convertToDomain(t *testing.T) {
 		assert.Equal(t, domain.ContentTypeVideo, content.Type)
 		assert.Equal(t, 1000, content.Views)
 		assert.Equal(t, 50, content.Likes)
+		assert.Equal(t, 930, content.Duration)
 	})
 
 	t.Run("Convert text content", func(t *testing.T) {
@@ -185,13 +212,42 @@ func TestJSONProviderAdapter_convertToDomain(t *testing.T) {
 			PublishedAt: "2024-03-14T15:30:00Z",
 		}
 
-		content := adapter.convertToDomain(item)
+		content, err := adapter.convertToDomain(item)
+		require.NoError(t, err)
 
 		assert.Equal(t, domain.ContentTypeText, content.Type)
 		assert.Equal(t, 5, content.ReadingTime)
 		assert.Equal(t, 25, content.Reactions)
 	})
 
+	t.Run("Convert tags", func(t *testing.T) {
+		item := JSONContentItem{
+			ID:    "v2",
+			Title: "Advanced Go Concurrency Patterns",
+			Type:  "video",
+			Tags:  []string{"Programming", "advanced", " concurrency "},
+		}
+
+		content, err := adapter.convertToDomain(item)
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"programming", "advanced", "concurrency"}, domain.TagNames(content.Tags))
+	})
+
+	t.Run("Convert language", func(t *testing.T) {
+		item := JSONContentItem{
+			ID:       "v3",
+			Title:    "Einführung in Go",
+			Type:     "video",
+			Language: "de",
+		}
+
+		content, err := adapter.convertToDomain(item)
+		require.NoError(t, err)
+
+		assert.Equal(t, "de", content.Language)
+	})
+
 	t.Run("Convert with invalid date", func(t *testing.T) {
 		item := JSONContentItem{
 			ID:    "v1",
@@ -200,7 +256,8 @@ func TestJSONProviderAdapter_convertToDomain(t *testing.T) {
 			PublishedAt: "invalid-date",
 		}
 
-		content := adapter.convertToDomain(item)
+		content, err := adapter.convertToDomain(item)
+		require.NoError(t, err)
 
 		assert.NotZero(t, content.CreatedAt)
 	})
@@ -12,6 +12,7 @@ import (
 
 	"search-engine-go/internal/domain"
 
+	"go.uber.org/zap"
 	"golang.org/x/time/rate"
 )
 
@@ -22,6 +23,7 @@ type XMLProviderAdapter struct {
 	rateLimiter *rate.Limiter
 	retryCount  int
 	retryDelay  time.Duration
+	log         *zap.Logger
 }
 
 func NewXMLProviderAdapter(name, url string, rateLimit int, timeout time.Duration) *XMLProviderAdapter {
@@ -41,6 +43,7 @@ func NewXMLProviderAdapterWithRetry(name, url string, rateLimit int, timeout tim
 		rateLimiter: rate.NewLimiter(rate.Limit(rps), rateLimit),
 		retryCount:  retryCount,
 		retryDelay:  retryDelay,
+		log:         zap.NewNop(),
 	}
 }
 
@@ -127,13 +130,26 @@ func (a *XMLProviderAdapter) FetchContent(ctx context.Context, query string, con
 
 	contents := make([]*domain.Content, 0, len(xmlResponse.Items))
 	for _, item := range xmlResponse.Items {
-		content := a.convertToDomain(item)
+		content, err := a.convertToDomain(item)
+		if err != nil {
+			a.log.Warn("Skipping provider item",
+				zap.String("provider", a.name),
+				zap.String("id", item.ID),
+				zap.Error(err),
+			)
+			continue
+		}
 		contents = append(contents, content)
 	}
 
 	return contents, nil
 }
 
+// SetLogger makes the adapter log the items it skips
+func (a *XMLProviderAdapter) SetLogger(log *zap.Logger) {
+	a.log = log
+}
+
 func (a *XMLProviderAdapter) isFilePath(url string) bool {
 	return !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://")
 }
@@ -158,21 +174,32 @@ type XMLContentItem struct {
 	Categories      struct {
 		Category []string `xml:"category"`
 	} `xml:"categories"`
+	Language string `xml:"language,omitempty"`
 }
 
 type XMLStats struct {
-	Views       int    `xml:"views,omitempty"`
-	Likes       int    `xml:"likes,omitempty"`
-	Duration    string `xml:"duration,omitempty"`
-	ReadingTime int    `xml:"reading_time,omitempty"`
-	Reactions   int    `xml:"reactions,omitempty"`
-	Comments    int    `xml:"comments,omitempty"`
+	Views         int    `xml:"views,omitempty"`
+	Likes         int    `xml:"likes,omitempty"`
+	Duration      string `xml:"duration,omitempty"`
+	ReadingTime   int    `xml:"reading_time,omitempty"`
+	Reactions     int    `xml:"reactions,omitempty"`
+	Comments      int    `xml:"comments,omitempty"`
+	Listens       int    `xml:"listens,omitempty"`
+	EpisodeLength string `xml:"episode_length,omitempty"`
+	ImageCount    int    `xml:"image_count,omitempty"`
 }
 
-func (a *XMLProviderAdapter) convertToDomain(item XMLContentItem) *domain.Content {
-	contentType := domain.ContentTypeText
-	if item.Type == "video" {
-		contentType = domain.ContentTypeVideo
+// convertToDomain maps an item onto content, failing for types we have no content type for
+func (a *XMLProviderAdapter) convertToDomain(item XMLContentItem) (*domain.Content, error) {
+	contentType, ok := domain.ParseProviderContentType(item.Type)
+	if !ok {
+		return nil, fmt.Errorf("unknown content type %q", item.Type)
+	}
+
+	// Podcasts report their length as episode_length rather than duration
+	duration := item.Stats.Duration
+	if duration == "" {
+		duration = item.Stats.EpisodeLength
 	}
 
 	createdAt := time.Now()
@@ -191,6 +218,12 @@ func (a *XMLProviderAdapter) convertToDomain(item XMLContentItem) *domain.Conten
 		Likes:       item.Stats.Likes,
 		ReadingTime: item.Stats.ReadingTime,
 		Reactions:   item.Stats.Reactions,
+		Duration:    parseDurationSeconds(duration),
+		Comments:    item.Stats.Comments,
+		Listens:     item.Stats.Listens,
+		ImageCount:  item.Stats.ImageCount,
+		Tags:        domain.NewTags(item.Categories.Category...),
+		Language:    item.Language,
 		CreatedAt:   createdAt,
-	}
+	}, nil
 }
@@ -11,6 +11,8 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
 )
 
 func TestXMLProviderAdapter_GetName(t *testing.T) {
@@ -123,6 +125,37 @@ func TestXMLProviderAdapter_FetchContent_WithContentTypeFilter(t *testing.T) {
 	assert.Len(t, contents, 2)
 }
 
+func TestXMLProviderAdapter_FetchContent_SkipsUnknownTypes(t *testing.T) {
+	xmlFile := filepath.Join(t.TempDir(), "test.xml")
+	xmlContent := `<?xml version="1.0" encoding="UTF-8"?>
+<feed>
+	<items>
+		<item>
+			<id>v1</id>
+			<headline>Test Video</headline>
+			<type>video</type>
+		</item>
+		<item>
+			<id>l1</id>
+			<headline>Test Livestream</headline>
+			<type>livestream</type>
+		</item>
+	</items>
+</feed>`
+	require.NoError(t, os.WriteFile(xmlFile, []byte(xmlContent), 0644))
+	core, logs := observer.New(zap.WarnLevel)
+	adapter := NewXMLProviderAdapter("test-provider", xmlFile, 60, 5*time.Second)
+	adapter.SetLogger(zap.New(core))
+
+	contents, err := adapter.FetchContent(context.Background(), "", nil)
+
+	require.NoError(t, err)
+	require.Len(t, contents, 1)
+	assert.Equal(t, "test-provider_v1", contents[0].ProviderID)
+	require.Equal(t, 1, logs.Len())
+	assert.Equal(t, "l1", logs.All()[0].ContextMap()["id"])
+}
+
 func TestXMLProviderAdapter_FetchContent_InvalidXML(t *testing.T) {
 	tmpDir := t.TempDir()
 	xmlFile := filepath.Join(tmpDir, "invalid.xml")
@@ -163,7 +196,8 @@ func TestXMLProviderAdapter_convertToDomain(t *testing.T) {
 			PublicationDate: "2024-03-15",
 		}
 
-		content := adapter.convertToDomain(item)
+		content, err := adapter.convertToDomain(item)
+		require.NoError(t, err)
 
 		assert.Equal(t, "test-provider_v1", content.ProviderID)
 		assert.Equal(t, "test-provider", content.Provider)
@@ -181,15 +215,70 @@ func TestXMLProviderAdapter_convertToDomain(t *testing.T) {
 			Stats: XMLStats{
 				ReadingTime: 5,
 				Reactions:   25,
+				Comments:    12,
 			},
 			PublicationDate: "2024-03-14",
 		}
 
-		content := adapter.convertToDomain(item)
+		content, err := adapter.convertToDomain(item)
+		require.NoError(t, err)
 
 		assert.Equal(t, domain.ContentTypeText, content.Type)
 		assert.Equal(t, 5, content.ReadingTime)
 		assert.Equal(t, 25, content.Reactions)
+		assert.Equal(t, 12, content.Comments)
+	})
+
+	t.Run("Convert podcast content", func(t *testing.T) {
+		item := XMLContentItem{
+			ID:       "p1",
+			Headline: "Go Time Episode 300",
+			Type:     "podcast",
+			Stats: XMLStats{
+				Listens:       4200,
+				Likes:         310,
+				EpisodeLength: "1:05:30",
+			},
+		}
+
+		content, err := adapter.convertToDomain(item)
+		require.NoError(t, err)
+
+		assert.Equal(t, domain.ContentTypeAudio, content.Type)
+		assert.Equal(t, 4200, content.Listens)
+		assert.Equal(t, 3930, content.Duration)
+	})
+
+	t.Run("Convert gallery content", func(t *testing.T) {
+		item := XMLContentItem{
+			ID:       "g1",
+			Headline: "GopherCon Photos",
+			Type:     "gallery",
+			Stats: XMLStats{
+				Views:      900,
+				ImageCount: 24,
+			},
+		}
+
+		content, err := adapter.convertToDomain(item)
+		require.NoError(t, err)
+
+		assert.Equal(t, domain.ContentTypeGallery, content.Type)
+		assert.Equal(t, 24, content.ImageCount)
+	})
+
+	t.Run("Convert categories to tags", func(t *testing.T) {
+		item := XMLContentItem{
+			ID:       "v1",
+			Headline: "Introduction to Docker",
+			Type:     "video",
+		}
+		item.Categories.Category = []string{"DevOps", "containers", "devops"}
+
+		content, err := adapter.convertToDomain(item)
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"devops", "containers"}, domain.TagNames(content.Tags))
 	})
 
 	t.Run("Convert with invalid date", func(t *testing.T) {
@@ -200,7 +289,8 @@ func TestXMLProviderAdapter_convertToDomain(t *testing.T) {
 			PublicationDate: "invalid-date",
 		}
 
-		content := adapter.convertToDomain(item)
+		content, err := adapter.convertToDomain(item)
+		require.NoError(t, err)
 
 		assert.NotZero(t, content.CreatedAt)
 	})